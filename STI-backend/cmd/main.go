@@ -1,9 +1,12 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -12,10 +15,16 @@ import (
 	"github.com/urfave/cli/v2"
 	_ "github.com/viteant/stockinsight/docs"
 	"github.com/viteant/stockinsight/internal/api"
+	apikeyinterfaces "github.com/viteant/stockinsight/internal/apikey/interfaces"
+	backtestinterfaces "github.com/viteant/stockinsight/internal/backtest/interfaces"
+	"github.com/viteant/stockinsight/internal/cassette"
 	"github.com/viteant/stockinsight/internal/db"
 	"github.com/viteant/stockinsight/internal/db/seeds/finances"
 	"github.com/viteant/stockinsight/internal/db/seeds/stocks"
-	financeinterfaces "github.com/viteant/stockinsight/internal/finance/interfaces"
+	"github.com/viteant/stockinsight/internal/problem"
+	quarantineinterfaces "github.com/viteant/stockinsight/internal/quarantine/interfaces"
+	schedulerdomain "github.com/viteant/stockinsight/internal/scheduler/domain"
+	schedulerinterfaces "github.com/viteant/stockinsight/internal/scheduler/interfaces"
 	stockinterfaces "github.com/viteant/stockinsight/internal/stock/interfaces"
 )
 
@@ -23,6 +32,14 @@ import (
 // @version 1.0
 // @description API de acciones y recomendaciones
 // @BasePath /api
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description API key creada con --create-api-key
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description La misma API key como "Bearer <API key>"
 func main() {
 	err := godotenv.Load()
 	if err != nil {
@@ -45,12 +62,37 @@ func main() {
 			},
 			&cli.BoolFlag{
 				Name:  "serve",
-				Usage: "Iniciar el servidor",
+				Usage: "Iniciar el servidor (con SCHEDULER_ENABLED=true también ejecuta los trabajos programados)",
+			},
+			&cli.BoolFlag{
+				Name:  "daemon",
+				Usage: "Ejecutar los trabajos programados (sync, finance y rescore) sin el servidor",
 			},
 			&cli.BoolFlag{
 				Name:  "sync",
 				Usage: "Sincronizar datos de stocks con la API",
 			},
+			&cli.BoolFlag{
+				Name:  "full",
+				Usage: "Forzar una resincronización completa, ignorando la marca de agua (con --sync) o reprocesar todas las filas (con --backfill-actions o --renormalize)",
+			},
+			&cli.StringFlag{
+				Name:  "backtest",
+				Usage: "Ejecutar un backtest siguiendo los upgrades del brokerage indicado",
+			},
+			&cli.IntFlag{
+				Name:  "hold-days",
+				Usage: "Días hábiles que se mantiene cada posición (solo con --backtest)",
+				Value: 30,
+			},
+			&cli.StringFlag{
+				Name:  "from",
+				Usage: "Fecha inicial YYYY-MM-DD (solo con --backtest, default: un año antes de --to)",
+			},
+			&cli.StringFlag{
+				Name:  "to",
+				Usage: "Fecha final YYYY-MM-DD (solo con --backtest, default: hoy)",
+			},
 			&cli.StringFlag{
 				Name:  "export",
 				Usage: "Exportar los datos de stocks a un archivo JSON",
@@ -67,16 +109,99 @@ func main() {
 				Name:  "update-finance",
 				Usage: "Actualiza datos históricos de Yahoo Finance para todos los tickers",
 			},
+			&cli.BoolFlag{
+				Name:  "backfill-actions",
+				Usage: "Completa action_type y target_change_pct en los ratings existentes",
+			},
+			&cli.BoolFlag{
+				Name:  "unmapped-ratings",
+				Usage: "Lista los ratings del feed que no están en el mapeo de normalización",
+			},
+			&cli.BoolFlag{
+				Name:  "renormalize",
+				Usage: "Aplica el mapeo de ratings actual a los ratings guardados con otra versión y recalcula los puntajes",
+			},
+			&cli.StringFlag{
+				Name:  "replay-quarantine",
+				Usage: "Reintenta guardar los registros pendientes en cuarentena: stock, finance o all",
+			},
+			&cli.BoolFlag{
+				Name:  "rescore",
+				Usage: "Recalcula la precisión de los brokers a 7, 30 y 90 días hábiles y sus puntajes",
+			},
+			&cli.StringFlag{
+				Name:  "create-api-key",
+				Usage: "Crea una API key con el nombre indicado y la muestra una única vez",
+			},
+			&cli.StringFlag{
+				Name:  "scopes",
+				Usage: "Scopes de la API key separados por coma: read, write, admin (solo con --create-api-key)",
+				Value: "read",
+			},
+			&cli.Float64Flag{
+				Name:  "rate",
+				Usage: "Peticiones por segundo de la API key (solo con --create-api-key, default: API_KEY_RATE_PER_SEC)",
+			},
+			&cli.IntFlag{
+				Name:  "burst",
+				Usage: "Ráfaga máxima de la API key (solo con --create-api-key, default: API_KEY_BURST)",
+			},
+			&cli.BoolFlag{
+				Name:  "list-api-keys",
+				Usage: "Lista las API keys",
+			},
+			&cli.StringFlag{
+				Name:  "revoke-api-key",
+				Usage: "Revoca la API key con el ID o prefijo indicado",
+			},
+			&cli.BoolFlag{
+				Name:  "refresh-cassettes",
+				Usage: "Vuelve a grabar los cassettes HTTP en HTTP_CASSETTE_DIR (con --sync o --update-finance)",
+			},
 		},
 		Action: func(c *cli.Context) error {
+			tape, err := cassette.ConfigFromEnv()
+			if err != nil {
+				log.Fatalf("Error configurando los cassettes HTTP: %v", err)
+			}
+			if c.Bool("refresh-cassettes") {
+				tape = refreshCassettes(tape)
+			}
+
 			if c.Bool("migrate") {
 				migrate(c.Bool("reset"), databaseURL)
 			} else if c.Bool("sync") {
-				syncData()
+				syncData(tape, c.Bool("full"))
+			} else if c.Bool("daemon") {
+				daemon(tape)
 			} else if c.Bool("serve") || c.NumFlags() == 0 {
-				startServer()
+				startServer(tape)
 			} else if c.Bool("update-finance") || c.NumFlags() == 0 {
-				updateFinance()
+				updateFinance(tape)
+			} else if c.Bool("backfill-actions") {
+				backfillActions(c.Bool("full"))
+			} else if c.Bool("unmapped-ratings") {
+				unmappedRatings()
+			} else if c.Bool("renormalize") {
+				renormalize(tape, c.Bool("full"))
+			} else if kind := c.String("replay-quarantine"); kind != "" {
+				replayQuarantine(kind)
+			} else if c.Bool("rescore") {
+				rescore(tape)
+			} else if name := c.String("create-api-key"); name != "" {
+				createAPIKey(name, c.String("scopes"), c.Float64("rate"), c.Int("burst"))
+			} else if c.Bool("list-api-keys") {
+				listAPIKeys()
+			} else if idOrPrefix := c.String("revoke-api-key"); idOrPrefix != "" {
+				revokeAPIKey(idOrPrefix)
+			} else if brokerage := c.String("backtest"); brokerage != "" {
+				backtest(backtestinterfaces.BacktestRequest{
+					Brokerage:  brokerage,
+					HoldDays:   c.Int("hold-days"),
+					From:       c.String("from"),
+					To:         c.String("to"),
+					PriceBasis: os.Getenv("PRICE_BASIS"),
+				})
 			} else if path := c.String("export"); path != "" {
 				if table := c.String("table"); table != "" {
 					exportData(path, table)
@@ -101,32 +226,159 @@ func migrate(reset bool, databaseURL string) {
 	db.RunMigrations(reset, databaseURL)
 }
 
-func startServer() {
+func startServer(tape cassette.Config) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	log.Println("🚀 Iniciando servidor en http://localhost:8080")
 
 	dbConn := db.NewCockroachDB()
 	defer dbConn.Close()
 
-	app := fiber.New()
+	app := fiber.New(fiber.Config{ErrorHandler: problem.ErrorHandler})
 	app.Use(cors.New(cors.Config{
-		AllowOrigins:  "*",
-		AllowMethods:  "GET",
-		AllowHeaders:  "Origin, Content-Type, Accept, Authorization",
-		ExposeHeaders: "Content-Length, Access-Control-Allow-Origin, Access-Control-Allow-Headers",
+		AllowOrigins:  corsOrigins(),
+		AllowMethods:  "GET,POST",
+		AllowHeaders:  "Origin, Content-Type, Accept, Authorization, X-API-Key",
+		ExposeHeaders: "Content-Length, Access-Control-Allow-Origin, Access-Control-Allow-Headers, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, Retry-After",
 	}))
 
-	api.RegisterRoutes(app, dbConn)
+	if err := api.RegisterRoutes(app, dbConn, tape); err != nil {
+		log.Fatalf("No se pudieron registrar las rutas: %v", err)
+	}
 	app.Get("/swagger/*", swagger.HandlerDefault)
 
-	log.Fatal(app.Listen(":8080"))
+	schedulerDone := make(chan struct{})
+	if os.Getenv("SCHEDULER_ENABLED") == "true" {
+		go func() {
+			defer close(schedulerDone)
+			if err := schedulerinterfaces.RunScheduler(ctx, tape); err != nil {
+				log.Printf("Error en el scheduler: %v", err)
+			}
+		}()
+	} else {
+		close(schedulerDone)
+	}
+
+	go func() {
+		<-ctx.Done()
+		log.Println("Deteniendo el servidor...")
+		if err := app.Shutdown(); err != nil {
+			log.Printf("Error deteniendo el servidor: %v", err)
+		}
+	}()
+
+	if err := app.Listen(":8080"); err != nil {
+		log.Fatal(err)
+	}
+	schedulerinterfaces.StopJobs()
+	<-schedulerDone
+}
+
+// corsOrigins lee los orígenes permitidos de CORS_ALLOWED_ORIGINS, separados
+// por coma. Por defecto solo el frontend de desarrollo.
+func corsOrigins() string {
+	origins := os.Getenv("CORS_ALLOWED_ORIGINS")
+	if origins == "" {
+		return "http://localhost:5173"
+	}
+	return origins
 }
 
-func syncData() {
+func daemon(tape cassette.Config) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Println("⏰ Iniciando el scheduler...")
+	if err := schedulerinterfaces.RunScheduler(ctx, tape); err != nil {
+		log.Fatalf("Error en el scheduler: %v", err)
+	}
+	log.Println("Scheduler detenido.")
+}
+
+func syncData(tape cassette.Config, full bool) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	log.Println("🔄 Sincronizando datos de stocks con la API...")
-	stockinterfaces.RunStockSync()
+	err := schedulerinterfaces.RunSync(ctx, tape, full)
+	if errors.Is(err, schedulerdomain.ErrJobBusy) {
+		log.Fatalln("Ya hay una sincronización en curso (scheduler, API u otra réplica).")
+	}
+	if err != nil {
+		log.Fatalf("Error sincronizando stocks: %v", err)
+	}
 	log.Println("🔄 Sincronización de stocks completada.")
 }
 
+func backtest(req backtestinterfaces.BacktestRequest) {
+	if err := backtestinterfaces.RunBacktest(req); err != nil {
+		log.Fatalf("Error ejecutando el backtest: %v", err)
+	}
+}
+
+func backfillActions(all bool) {
+	log.Println("Clasificando acciones de los ratings existentes...")
+	if err := stockinterfaces.RunActionBackfill(all); err != nil {
+		log.Fatalf("Error en el backfill de action_type: %v", err)
+	}
+}
+
+func unmappedRatings() {
+	if err := stockinterfaces.PrintUnmappedRatings(); err != nil {
+		log.Fatalf("Error listando ratings sin mapeo: %v", err)
+	}
+}
+
+func renormalize(tape cassette.Config, all bool) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Println("Renormalizando ratings con el mapeo actual...")
+	if err := stockinterfaces.RunRenormalize(all); err != nil {
+		log.Fatalf("Error renormalizando ratings: %v", err)
+	}
+
+	// La normalización define qué ratings acertaron.
+	runJob(ctx, tape, schedulerdomain.JobRescore)
+}
+
+// refreshCassettes devuelve tape en modo refresh: los clientes HTTP salen a la
+// red y sobrescriben lo grabado.
+func refreshCassettes(tape cassette.Config) cassette.Config {
+	if tape.Dir == "" {
+		log.Fatal("--refresh-cassettes requiere HTTP_CASSETTE_DIR")
+	}
+	tape.Mode = cassette.ModeRefresh
+	log.Println("📼 Regrabando cassettes HTTP...")
+	return tape
+}
+
+func replayQuarantine(kind string) {
+	log.Println("Reintentando registros en cuarentena...")
+	if err := quarantineinterfaces.RunReplay(kind); err != nil {
+		log.Fatalf("Error reinyectando la cuarentena: %v", err)
+	}
+}
+
+func createAPIKey(name, scopes string, rate float64, burst int) {
+	if err := apikeyinterfaces.CreateAPIKey(name, scopes, rate, burst); err != nil {
+		log.Fatalf("Error creando la API key: %v", err)
+	}
+}
+
+func listAPIKeys() {
+	if err := apikeyinterfaces.PrintAPIKeys(); err != nil {
+		log.Fatalf("Error listando las API keys: %v", err)
+	}
+}
+
+func revokeAPIKey(idOrPrefix string) {
+	if err := apikeyinterfaces.RevokeAPIKey(idOrPrefix); err != nil {
+		log.Fatalf("Error revocando la API key: %v", err)
+	}
+}
+
 func exportData(path string, table string) {
 	log.Println("Iniciando Exportación de datos...")
 	dataBase := db.NewCockroachDB()
@@ -166,13 +418,38 @@ func importData(path string, table string) {
 	}
 
 	if err != nil {
-		log.Fatalf("Error exportando datos: %v", err)
+		log.Fatalf("Error importando datos: %v", err)
 	}
 
 	log.Printf("Datos importados con éxito!")
 
 }
 
-func updateFinance() {
-	financeinterfaces.SyncFinanceHandler()
+func updateFinance(tape cassette.Config) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	runJob(ctx, tape, schedulerdomain.JobFinance)
+
+	// Con precios nuevos cambian los resultados de los ratings y los puntajes.
+	runJob(ctx, tape, schedulerdomain.JobRescore)
+}
+
+func rescore(tape cassette.Config) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	runJob(ctx, tape, schedulerdomain.JobRescore)
+}
+
+// runJob ejecuta el trabajo con el mismo lease que el scheduler y la API, para
+// no correrlo dos veces a la vez.
+func runJob(ctx context.Context, tape cassette.Config, name string) {
+	err := schedulerinterfaces.RunJob(ctx, tape, name)
+	if errors.Is(err, schedulerdomain.ErrJobBusy) {
+		log.Fatalf("%s ya está en curso (scheduler, API u otra réplica).", name)
+	}
+	if err != nil {
+		log.Fatalf("Error ejecutando %s: %v", name, err)
+	}
 }
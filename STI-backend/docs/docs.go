@@ -15,9 +15,17 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/recommendations": {
+        "/api/admin/jobs": {
             "get": {
-                "description": "Devuelve una lista con 10 acciones recomendadas para comprar, mantener y vender, basadas en la puntuación de los brokers.",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve las últimas ejecuciones de este proceso, de la más reciente a la más antigua.",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,34 +33,51 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Recommendations"
+                    "Admin"
                 ],
-                "summary": "Recomendaciones de acciones",
+                "summary": "Ejecuciones lanzadas desde la API",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/domain.StockRecommendation"
+                                "$ref": "#/definitions/domain.Execution"
                             }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     }
                 }
             }
         },
-        "/api/stocks": {
+        "/api/admin/jobs/{id}": {
             "get": {
-                "description": "Devuelve acciones con paginación y filtros",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve el estado y el avance de la ejecución: páginas procesadas en sync y tickers procesados en finance y rescore. Solo la réplica que lanzó la ejecución la conoce; en las demás se usa progress.run_id con /api/sync/runs/{id}.",
                 "consumes": [
                     "application/json"
                 ],
@@ -60,62 +85,308 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Stocks"
+                    "Admin"
                 ],
-                "summary": "Lista de acciones",
+                "summary": "Estado de una ejecución",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Número de página",
-                        "name": "page",
-                        "in": "query"
+                        "type": "string",
+                        "description": "ID de la ejecución",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Execution"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/jobs/{id}/cancel": {
+            "post": {
+                "security": [
                     {
-                        "type": "integer",
-                        "description": "Cantidad por página",
-                        "name": "limit",
-                        "in": "query"
+                        "ApiKeyAuth": []
                     },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Pide la cancelación de una ejecución en curso. El estado pasa a canceled cuando el trabajo se detiene; la sincronización termina la página actual y guarda el cursor.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Cancelar una ejecución",
+                "parameters": [
                     {
                         "type": "string",
-                        "description": "Filtra por ticker (ILIKE)",
-                        "name": "ticker",
-                        "in": "query"
+                        "description": "ID de la ejecución",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Execution"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
                     },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/jobs/{job}": {
+            "post": {
+                "security": [
                     {
-                        "type": "string",
-                        "description": "Filtra por nombre de empresa (ILIKE)",
-                        "name": "company",
-                        "in": "query"
+                        "ApiKeyAuth": []
                     },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ejecuta en segundo plano la sincronización de ratings (sync), la actualización de precios (finance) o la recalificación de brokers (rescore) y devuelve la ejecución. progress.run_id es la corrida en sync_runs, consultable en /api/sync/runs/{id} desde cualquier réplica.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Lanzar un trabajo",
+                "parameters": [
                     {
                         "type": "string",
-                        "description": "Filtra por brokerage (ILIKE)",
-                        "name": "brokerage",
-                        "in": "query"
+                        "description": "Trabajo (sync, finance o rescore)",
+                        "name": "job",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Execution"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/backtests": {
+            "post": {
+                "security": [
                     {
-                        "type": "number",
-                        "description": "Filtra por target_from mínimo",
-                        "name": "target_from_min",
-                        "in": "query"
+                        "ApiKeyAuth": []
                     },
                     {
-                        "type": "number",
-                        "description": "Filtra por target_from máximo",
-                        "name": "target_from_max",
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Simula comprar en cada upgrade del brokerage y vender tras hold_days días hábiles o ante un downgrade. Devuelve retorno total, CAGR, máximo drawdown, tasa de aciertos, operaciones y curva de capital, comparados con un benchmark equiponderado de todos los tickers. Requiere el scope write o admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Backtests"
+                ],
+                "summary": "Backtest \"seguir al broker\"",
+                "parameters": [
+                    {
+                        "description": "Configuración del backtest (hold_days default: 30, from default: un año antes de to, to default: hoy, price_basis default: adjusted, initial_capital default: 10000)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/interfaces.BacktestRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Result"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/consensus": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve, por ticker, el último rating de cada brokerage, conteos buy/hold/sell, estadísticas de target_to, upside implícito contra el último cierre y un puntaje de consenso ponderado por el puntaje de cada broker. Ordenado por puntaje.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Consensus"
+                ],
+                "summary": "Consenso por ticker",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Cantidad de tickers (default: 50, máximo: 500)",
+                        "name": "limit",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Fecha mínima (YYYY-MM-DD)",
-                        "name": "date_from",
+                        "description": "Base de precios de los puntajes de brokers (raw o adjusted, default: PRICE_BASIS o raw)",
+                        "name": "price_basis",
                         "in": "query"
                     },
                     {
-                        "type": "string",
-                        "description": "Fecha máxima (YYYY-MM-DD)",
-                        "name": "date_to",
+                        "type": "integer",
+                        "description": "Horizonte en días hábiles de los puntajes de brokers (7, 30 o 90; default: cierre más cercano al rating)",
+                        "name": "horizon",
                         "in": "query"
                     }
                 ],
@@ -123,41 +394,1531 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.TickerConsensus"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "domain.StockRecommendation": {
-            "type": "object",
-            "properties": {
-                "action": {
-                    "type": "string"
-                },
-                "brokerage": {
-                    "type": "string"
-                },
-                "company": {
-                    "type": "string"
-                },
-                "normalize_rating_from": {
-                    "type": "string"
-                },
-                "normalize_rating_to": {
-                    "type": "string"
-                },
-                "ticker": {
-                    "type": "string"
-                },
-                "weight_score": {
+        },
+        "/api/quarantine": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve los ratings y velas rechazados en la ingesta, con el payload original, la fuente y el motivo, del más reciente al más antiguo.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Quarantine"
+                ],
+                "summary": "Registros en cuarentena",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filtra por tipo (stock o finance)",
+                        "name": "kind",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtra por estado (pending, resolved o discarded)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Cantidad de registros (default: 50, máximo: 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Record"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/quarantine/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Quarantine"
+                ],
+                "summary": "Detalle de un registro en cuarentena",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID del registro",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Record"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/quarantine/{id}/discard": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Marca un registro pendiente como discarded; no se vuelve a reintentar.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Quarantine"
+                ],
+                "summary": "Descartar un registro en cuarentena",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID del registro",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Record"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/quarantine/{id}/retry": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Vuelve a validar y guardar un registro pendiente. Si se guarda queda resolved; si no, sigue pending con el intento y el error registrados.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Quarantine"
+                ],
+                "summary": "Reintentar un registro en cuarentena",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID del registro",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Record"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/recommendations": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve hasta limit acciones recomendadas (10 por defecto) para comprar, mantener y vender, basadas en la puntuación de los brokers (precisión Beta-binomial de broker_scores, informada en score_method).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Recommendations"
+                ],
+                "summary": "Recomendaciones de acciones",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Base de precios para evaluar a los brokers (raw o adjusted, default: PRICE_BASIS o raw)",
+                        "name": "price_basis",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Horizonte en días hábiles para ordenar a los brokers (7, 30 o 90; default: cierre más cercano al rating)",
+                        "name": "horizon",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Vida media en días del historial del broker (0 desactiva el decaimiento, default: 365)",
+                        "name": "track_half_life",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Vida media en días de la antigüedad del rating (0 desactiva el decaimiento, default: 90)",
+                        "name": "rating_half_life",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Cantidad por tipo de rating (default: 10, máximo: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Mínimo de predicciones evaluadas del broker",
+                        "name": "min_predictions",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Solo ratings desde esta fecha (YYYY-MM-DD)",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtra por brokerage (ILIKE)",
+                        "name": "brokerage",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Un solo rating por ticker en cada tipo, el de mayor puntaje",
+                        "name": "distinct_ticker",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtra por tipo de acción (upgrade, downgrade, initiation, reiteration, target_raised, target_lowered, target_set, other)",
+                        "name": "action_type",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.StockRecommendation"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/stocks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve acciones con paginación y filtros. Se puede paginar por número de página o con los cursores next_cursor y prev_cursor de la respuesta, que no se saltean ni repiten filas aunque se inserten otras. Los parámetros inválidos responden 400 con un problema RFC 7807 que detalla cada campo en errors.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Stocks"
+                ],
+                "summary": "Lista de acciones",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Número de página (default: 1, no se combina con cursor)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor o prev_cursor de una respuesta anterior; los filtros deben repetirse o se responde 400",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Incluir total y total_pages (default: true)",
+                        "name": "include_total",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Cantidad por página (default: 20, máximo: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Columna para ordenar: created_at, target_to, target_from, rating_to, rating_from, action, ticker, company, brokerage o id (default: created_at)",
+                        "name": "orderBy",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Dirección de orden (asc o desc, default: asc)",
+                        "name": "orderDir",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtra por ID (UUID)",
+                        "name": "id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtra por ticker (ILIKE)",
+                        "name": "ticker",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtra por nombre de empresa (ILIKE)",
+                        "name": "company",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtra por brokerage (ILIKE)",
+                        "name": "brokerage",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Filtra por target_from mínimo",
+                        "name": "target_from_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Filtra por target_from máximo",
+                        "name": "target_from_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Filtra por target_to mínimo",
+                        "name": "target_to_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Filtra por target_to máximo",
+                        "name": "target_to_max",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fecha mínima (YYYY-MM-DD o RFC 3339)",
+                        "name": "date_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fecha máxima, inclusiva (YYYY-MM-DD incluye todo el día, o RFC 3339)",
+                        "name": "date_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filtra por tipo de acción (upgrade, downgrade, initiation, reiteration, target_raised, target_lowered, target_set, other)",
+                        "name": "action_type",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/sync/runs": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve las corridas más recientes de sincronización de stocks, actualización de finanzas y recalificación de brokers.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sync"
+                ],
+                "summary": "Historial de sincronizaciones",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filtra por tipo (stocks, finances o rescore)",
+                        "name": "kind",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Cantidad de corridas (default: 20, máximo: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Run"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/sync/runs/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve el detalle de una corrida de sincronización.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sync"
+                ],
+                "summary": "Detalle de una sincronización",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID de la corrida",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Run"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tickers/{ticker}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve la empresa, el historial de ratings ordenado por fecha, el último cierre y el consenso actual de los brokers.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tickers"
+                ],
+                "summary": "Detalle de un ticker",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ticker",
+                        "name": "ticker",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.TickerDetail"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tickers/{ticker}/consensus": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve el último rating de cada brokerage sobre el ticker, conteos buy/hold/sell, estadísticas de target_to, upside implícito contra el último cierre y un puntaje de consenso ponderado por el puntaje de cada broker.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Consensus"
+                ],
+                "summary": "Consenso de un ticker",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ticker",
+                        "name": "ticker",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Base de precios de los puntajes de brokers (raw o adjusted, default: PRICE_BASIS o raw)",
+                        "name": "price_basis",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Horizonte en días hábiles de los puntajes de brokers (7, 30 o 90; default: cierre más cercano al rating)",
+                        "name": "horizon",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.TickerConsensus"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/tickers/{ticker}/prices": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Devuelve velas OHLCV del ticker, agregadas en el servidor por día, semana o mes.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tickers"
+                ],
+                "summary": "Serie de precios de un ticker",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ticker",
+                        "name": "ticker",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fecha inicial (YYYY-MM-DD, default: un año antes de to)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fecha final (YYYY-MM-DD, default: hoy)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Intervalo (1d, 1w o 1mo, default: 1d)",
+                        "name": "interval",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "domain.Config": {
+            "type": "object",
+            "properties": {
+                "brokerage": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "hold_days": {
+                    "type": "integer"
+                },
+                "initial_capital": {
+                    "type": "number"
+                },
+                "price_basis": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Consensus": {
+            "type": "object",
+            "properties": {
+                "brokers": {
+                    "type": "integer"
+                },
+                "buy": {
+                    "type": "integer"
+                },
+                "hold": {
+                    "type": "integer"
+                },
+                "rating": {
+                    "type": "string"
+                },
+                "sell": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.EquityPoint": {
+            "type": "object",
+            "properties": {
+                "benchmark": {
+                    "type": "number"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "equity": {
+                    "type": "number"
+                },
+                "positions": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.Execution": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "job": {
+                    "type": "string"
+                },
+                "progress": {
+                    "$ref": "#/definitions/domain.Progress"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.LatestClose": {
+            "type": "object",
+            "properties": {
+                "close": {
+                    "type": "number"
+                },
+                "date": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Metrics": {
+            "type": "object",
+            "properties": {
+                "cagr": {
+                    "type": "number"
+                },
+                "max_drawdown": {
+                    "type": "number"
+                },
+                "total_return": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.Progress": {
+            "type": "object",
+            "properties": {
+                "pages": {
+                    "type": "integer"
+                },
+                "rows_failed": {
+                    "type": "integer"
+                },
+                "rows_inserted": {
+                    "type": "integer"
+                },
+                "rows_quarantined": {
+                    "type": "integer"
+                },
+                "rows_updated": {
+                    "type": "integer"
+                },
+                "run_id": {
+                    "type": "string"
+                },
+                "tickers_done": {
+                    "type": "integer"
+                },
+                "tickers_total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.Record": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_seen_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "resolved_at": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Result": {
+            "type": "object",
+            "properties": {
+                "benchmark": {
+                    "$ref": "#/definitions/domain.Metrics"
+                },
+                "config": {
+                    "$ref": "#/definitions/domain.Config"
+                },
+                "equity_curve": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.EquityPoint"
+                    }
+                },
+                "hit_rate": {
+                    "type": "number"
+                },
+                "strategy": {
+                    "$ref": "#/definitions/domain.Metrics"
+                },
+                "total_trades": {
+                    "type": "integer"
+                },
+                "trades": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Trade"
+                    }
+                }
+            }
+        },
+        "domain.Run": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "heartbeat_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "pages_fetched": {
+                    "type": "integer"
+                },
+                "rows_failed": {
+                    "type": "integer"
+                },
+                "rows_inserted": {
+                    "type": "integer"
+                },
+                "rows_quarantined": {
+                    "type": "integer"
+                },
+                "rows_updated": {
+                    "type": "integer"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Stock": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "action_type": {
+                    "type": "string"
+                },
+                "brokerage": {
+                    "type": "string"
+                },
+                "company": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "normalize_rating_from": {
+                    "type": "string"
+                },
+                "normalize_rating_from_5": {
+                    "description": "Calificaciones en la escala de 5 niveles (strong_buy … strong_sell).",
+                    "type": "string"
+                },
+                "normalize_rating_to": {
+                    "type": "string"
+                },
+                "normalize_rating_to_5": {
+                    "type": "string"
+                },
+                "rating_from": {
+                    "type": "string"
+                },
+                "rating_mapping_version": {
+                    "type": "integer"
+                },
+                "rating_to": {
+                    "type": "string"
+                },
+                "target_change_pct": {
+                    "type": "number"
+                },
+                "target_from": {
+                    "type": "number"
+                },
+                "target_to": {
+                    "type": "number"
+                },
+                "ticker": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.StockRecommendation": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "action_type": {
+                    "type": "string"
+                },
+                "broker_predictions": {
+                    "type": "integer"
+                },
+                "brokerage": {
+                    "type": "string"
+                },
+                "company": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "normalize_rating_from": {
+                    "type": "string"
+                },
+                "normalize_rating_to": {
+                    "type": "string"
+                },
+                "score_ci_high": {
+                    "type": "number"
+                },
+                "score_ci_low": {
+                    "type": "number"
+                },
+                "score_method": {
+                    "type": "string"
+                },
+                "target_from": {
+                    "type": "number"
+                },
+                "target_to": {
+                    "type": "number"
+                },
+                "ticker": {
+                    "type": "string"
+                },
+                "weight_score": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.TickerConsensus": {
+            "type": "object",
+            "properties": {
+                "brokers": {
+                    "type": "integer"
+                },
+                "buy": {
+                    "type": "integer"
+                },
+                "company": {
+                    "type": "string"
+                },
+                "hold": {
+                    "type": "integer"
+                },
+                "implied_upside": {
+                    "type": "number"
+                },
+                "latest_close": {
+                    "$ref": "#/definitions/domain.LatestClose"
+                },
+                "rating": {
+                    "type": "string"
+                },
+                "ratings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Stock"
+                    }
+                },
+                "score": {
+                    "type": "number"
+                },
+                "sell": {
+                    "type": "integer"
+                },
+                "target_currency": {
+                    "type": "string"
+                },
+                "target_high": {
+                    "type": "number"
+                },
+                "target_low": {
+                    "type": "number"
+                },
+                "target_mean": {
+                    "type": "number"
+                },
+                "target_median": {
+                    "type": "number"
+                },
+                "ticker": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.TickerDetail": {
+            "type": "object",
+            "properties": {
+                "company": {
+                    "type": "string"
+                },
+                "consensus": {
+                    "$ref": "#/definitions/domain.Consensus"
+                },
+                "latest_close": {
+                    "$ref": "#/definitions/domain.LatestClose"
+                },
+                "ratings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Stock"
+                    }
+                },
+                "ticker": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Trade": {
+            "type": "object",
+            "properties": {
+                "entry_date": {
+                    "type": "string"
+                },
+                "entry_price": {
+                    "type": "number"
+                },
+                "exit_date": {
+                    "type": "string"
+                },
+                "exit_price": {
+                    "type": "number"
+                },
+                "exit_reason": {
+                    "type": "string"
+                },
+                "return": {
+                    "type": "number"
+                },
+                "ticker": {
+                    "type": "string"
+                }
+            }
+        },
+        "interfaces.BacktestRequest": {
+            "type": "object",
+            "properties": {
+                "brokerage": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "hold_days": {
+                    "type": "integer"
+                },
+                "initial_capital": {
                     "type": "number"
+                },
+                "price_basis": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "problem.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "problem.Problem": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/problem.FieldError"
+                    }
+                },
+                "instance": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "API key creada con --create-api-key",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "description": "La misma API key como \"Bearer \u003cAPI key\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
@@ -4,14 +4,39 @@ import (
 	"database/sql"
 
 	"github.com/gofiber/fiber/v2"
+	apikeydomain "github.com/viteant/stockinsight/internal/apikey/domain"
+	apikeyinterfaces "github.com/viteant/stockinsight/internal/apikey/interfaces"
+	backtestroutes "github.com/viteant/stockinsight/internal/backtest/interfaces"
+	"github.com/viteant/stockinsight/internal/cassette"
+	financeroutes "github.com/viteant/stockinsight/internal/finance/interfaces"
+	quarantineroutes "github.com/viteant/stockinsight/internal/quarantine/interfaces"
+	schedulerroutes "github.com/viteant/stockinsight/internal/scheduler/interfaces"
 	stockroutes "github.com/viteant/stockinsight/internal/stock/interfaces"
+	syncrunroutes "github.com/viteant/stockinsight/internal/syncrun/interfaces"
 )
 
-func RegisterRoutes(app *fiber.App, db *sql.DB) {
-	apiGroup := app.Group("/api")
+// RegisterRoutes registra todos los endpoints bajo /api. tape es la
+// configuración de cassettes de los trabajos lanzados desde la API. Falla si
+// algún repositorio no se puede configurar (p. ej. el mapeo de ratings).
+func RegisterRoutes(app *fiber.App, db *sql.DB, tape cassette.Config) error {
+	apiGroup := app.Group("/api",
+		apikeyinterfaces.Middleware(db),
+		apikeyinterfaces.RequireScope(apikeydomain.ScopeRead),
+	)
+	requireWrite := apikeyinterfaces.RequireScope(apikeydomain.ScopeWrite)
+	requireAdmin := apikeyinterfaces.RequireScope(apikeydomain.ScopeAdmin)
 
-	stockroutes.RegisterStockRoutes(apiGroup, db)
+	if err := stockroutes.RegisterStockRoutes(apiGroup, db); err != nil {
+		return err
+	}
+	syncrunroutes.RegisterSyncRunRoutes(apiGroup, db)
+	financeroutes.RegisterFinanceRoutes(apiGroup, db)
+	backtestroutes.RegisterBacktestRoutes(apiGroup, db, requireWrite)
+	if err := quarantineroutes.RegisterQuarantineRoutes(apiGroup, db, requireAdmin); err != nil {
+		return err
+	}
 
-	// Luego podrías tener:
-	// financeroutes.RegisterFinanceRoutes(apiGroup, db)
+	adminGroup := apiGroup.Group("/admin", requireAdmin)
+	schedulerroutes.RegisterJobRoutes(adminGroup, db, tape)
+	return nil
 }
@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"time"
+)
+
+const (
+	// ScopeRead permite los endpoints de consulta.
+	ScopeRead = "read"
+	// ScopeWrite permite además los endpoints que consumen cómputo, como los
+	// backtests.
+	ScopeWrite = "write"
+	// ScopeAdmin permite además lanzar trabajos y modificar la cuarentena.
+	ScopeAdmin = "admin"
+
+	keyPrefix  = "sti_"
+	prefixSize = len(keyPrefix) + 8
+)
+
+var Scopes = []string{ScopeRead, ScopeWrite, ScopeAdmin}
+
+var (
+	ErrKeyNotFound  = errors.New("API key no encontrada")
+	ErrAmbiguousKey = errors.New("el prefijo coincide con varias API keys, se debe indicar el ID")
+	ErrInvalidKey   = errors.New("API key inválida o revocada")
+	ErrInvalidScope = errors.New("scope inválido, debe ser read, write o admin")
+)
+
+// APIKey es una clave de acceso a la API. Solo se guarda el hash SHA-256 de
+// la clave; Prefix son sus primeros caracteres, para reconocerla al listar.
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	RatePerSec float64    `json:"rate_per_sec"`
+	Burst      int        `json:"burst"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	Hash       string     `json:"-"`
+}
+
+// HasScope indica si la clave tiene el scope; write incluye read y admin
+// incluye todos.
+func (k APIKey) HasScope(scope string) bool {
+	switch {
+	case slices.Contains(k.Scopes, scope), slices.Contains(k.Scopes, ScopeAdmin):
+		return true
+	case scope == ScopeRead:
+		return slices.Contains(k.Scopes, ScopeWrite)
+	default:
+		return false
+	}
+}
+
+type APIKeyRepository interface {
+	Create(key APIKey) (APIKey, error)
+	FindByHash(hash string) (APIKey, error)
+	List() ([]APIKey, error)
+	// Revoke acepta el ID o el prefijo de la clave. Si el prefijo coincide
+	// con varias claves devuelve ErrAmbiguousKey sin revocar ninguna.
+	Revoke(idOrPrefix string) (APIKey, error)
+	TouchLastUsed(id string, at time.Time) error
+}
+
+// GenerateKey crea una clave aleatoria de 256 bits con el prefijo "sti_".
+func GenerateKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return keyPrefix + hex.EncodeToString(b), nil
+}
+
+// HashKey es el hash con el que se guarda y se busca la clave. Al ser claves
+// aleatorias de 256 bits no hace falta un hash lento.
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func KeyPrefix(key string) string {
+	if len(key) < prefixSize {
+		return key
+	}
+	return key[:prefixSize]
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/viteant/stockinsight/internal/apikey/domain"
+	"github.com/viteant/stockinsight/internal/db"
+)
+
+const maxTxRetries = 5
+
+type PersistenceAPIKeyRepository struct {
+	DB *sql.DB
+}
+
+func NewCockroachAPIKeyRepository(db *sql.DB) *PersistenceAPIKeyRepository {
+	return &PersistenceAPIKeyRepository{DB: db}
+}
+
+const selectKeyColumns = `
+	SELECT id::STRING, name, prefix, key_hash, scopes, rate_per_sec, burst,
+		created_at, last_used_at, revoked_at
+	FROM api_keys
+`
+
+func (r *PersistenceAPIKeyRepository) Create(key domain.APIKey) (domain.APIKey, error) {
+	return scanKey(r.DB.QueryRow(`
+		INSERT INTO api_keys (name, prefix, key_hash, scopes, rate_per_sec, burst)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id::STRING, name, prefix, key_hash, scopes, rate_per_sec, burst,
+			created_at, last_used_at, revoked_at
+	`, key.Name, key.Prefix, key.Hash, pq.Array(key.Scopes), key.RatePerSec, key.Burst))
+}
+
+// FindByHash solo devuelve claves no revocadas.
+func (r *PersistenceAPIKeyRepository) FindByHash(hash string) (domain.APIKey, error) {
+	key, err := scanKey(r.DB.QueryRow(selectKeyColumns+`WHERE key_hash = $1 AND revoked_at IS NULL`, hash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return key, domain.ErrKeyNotFound
+	}
+	return key, err
+}
+
+func (r *PersistenceAPIKeyRepository) List() ([]domain.APIKey, error) {
+	rows, err := r.DB.Query(selectKeyColumns + `ORDER BY created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []domain.APIKey
+	for rows.Next() {
+		key, err := scanKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// Revoke busca la clave por ID o prefijo dentro de la misma transacción que
+// la revoca, y no revoca nada si el prefijo coincide con más de una.
+func (r *PersistenceAPIKeyRepository) Revoke(idOrPrefix string) (domain.APIKey, error) {
+	var key domain.APIKey
+	err := db.RunInTx(r.DB, maxTxRetries, func(tx *sql.Tx) error {
+		rows, err := tx.Query(`
+			SELECT id::STRING FROM api_keys
+			WHERE id::STRING = $1 OR prefix = $1
+			LIMIT 2
+		`, idOrPrefix)
+		if err != nil {
+			return err
+		}
+		var ids []string
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			ids = append(ids, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		switch len(ids) {
+		case 0:
+			return domain.ErrKeyNotFound
+		case 1:
+		default:
+			return domain.ErrAmbiguousKey
+		}
+
+		key, err = scanKey(tx.QueryRow(`
+			UPDATE api_keys SET revoked_at = COALESCE(revoked_at, now())
+			WHERE id::STRING = $1
+			RETURNING id::STRING, name, prefix, key_hash, scopes, rate_per_sec, burst,
+				created_at, last_used_at, revoked_at
+		`, ids[0]))
+		return err
+	})
+	return key, err
+}
+
+func (r *PersistenceAPIKeyRepository) TouchLastUsed(id string, at time.Time) error {
+	_, err := r.DB.Exec(`UPDATE api_keys SET last_used_at = $2 WHERE id::STRING = $1`, id, at)
+	return err
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanKey(row rowScanner) (domain.APIKey, error) {
+	var key domain.APIKey
+	var lastUsedAt, revokedAt sql.NullTime
+
+	err := row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.Prefix,
+		&key.Hash,
+		pq.Array(&key.Scopes),
+		&key.RatePerSec,
+		&key.Burst,
+		&key.CreatedAt,
+		&lastUsedAt,
+		&revokedAt,
+	)
+	if err != nil {
+		return key, err
+	}
+
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	return key, nil
+}
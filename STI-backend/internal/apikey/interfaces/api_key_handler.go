@@ -0,0 +1,86 @@
+package interfaces
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/viteant/stockinsight/internal/db"
+)
+
+// CreateAPIKey crea una clave e imprime su valor. Es la única vez que se
+// muestra: en la base solo queda el hash.
+func CreateAPIKey(name, scopes string, rate float64, burst int) error {
+	dbConn := db.NewCockroachDB()
+	defer dbConn.Close()
+
+	plain, key, err := newAPIKeyService(dbConn).Create(name, splitScopes(scopes), rate, burst)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("API key creada: %s (id %s)\n", key.Name, key.ID)
+	fmt.Printf("Scopes: %s  Límite: %g/s, ráfaga %d\n", strings.Join(key.Scopes, ","), key.RatePerSec, key.Burst)
+	fmt.Printf("\n  %s\n\n", plain)
+	fmt.Println("Guárdala ahora: no se puede volver a mostrar.")
+	return nil
+}
+
+func PrintAPIKeys() error {
+	dbConn := db.NewCockroachDB()
+	defer dbConn.Close()
+
+	keys, err := newAPIKeyService(dbConn).List()
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		fmt.Println("No hay API keys. Crea una con --create-api-key=<nombre>")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tPREFIJO\tNOMBRE\tSCOPES\tLÍMITE\tCREADA\tÚLTIMO USO\tESTADO")
+	for _, k := range keys {
+		status := "activa"
+		if k.RevokedAt != nil {
+			status = "revocada " + k.RevokedAt.Format("2006-01-02")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%g/s (%d)\t%s\t%s\t%s\n",
+			k.ID, k.Prefix, k.Name, strings.Join(k.Scopes, ","), k.RatePerSec, k.Burst,
+			k.CreatedAt.Format("2006-01-02"), formatTime(k.LastUsedAt), status)
+	}
+	return w.Flush()
+}
+
+// RevokeAPIKey revoca la clave por ID o por prefijo.
+func RevokeAPIKey(idOrPrefix string) error {
+	dbConn := db.NewCockroachDB()
+	defer dbConn.Close()
+
+	key, err := newAPIKeyService(dbConn).Revoke(idOrPrefix)
+	if err != nil {
+		return fmt.Errorf("%s: %w", idOrPrefix, err)
+	}
+	fmt.Printf("API key revocada: %s (%s)\n", key.Name, key.Prefix)
+	return nil
+}
+
+func splitScopes(s string) []string {
+	var scopes []string
+	for _, scope := range strings.Split(s, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format("2006-01-02 15:04")
+}
@@ -0,0 +1,166 @@
+package interfaces
+
+import (
+	"errors"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/viteant/stockinsight/internal/apikey/domain"
+	"github.com/viteant/stockinsight/internal/apikey/use_cases"
+	"github.com/viteant/stockinsight/internal/problem"
+	"github.com/viteant/stockinsight/internal/ratelimit"
+)
+
+const (
+	localsKey = "api_key"
+	// touchEvery limita las escrituras de last_used_at a una por minuto y clave.
+	touchEvery = time.Minute
+)
+
+// devKey es la clave que se usa con API_AUTH=off.
+var devKey = domain.APIKey{ID: "dev", Name: "API_AUTH=off", Scopes: domain.Scopes}
+
+type limiter struct {
+	bucket *ratelimit.TokenBucket
+	rate   float64
+	burst  int
+}
+
+// Authenticator valida la API key de cada petición y aplica su límite de
+// tasa. Los baldes viven en memoria, así que el límite es por réplica.
+type Authenticator struct {
+	service *use_cases.APIKeyService
+
+	mu       sync.Mutex
+	limiters map[string]*limiter
+	touched  map[string]time.Time
+}
+
+func NewAuthenticator(service *use_cases.APIKeyService) *Authenticator {
+	return &Authenticator{
+		service:  service,
+		limiters: make(map[string]*limiter),
+		touched:  make(map[string]time.Time),
+	}
+}
+
+// Handler acepta la clave en Authorization: Bearer o en X-API-Key y agrega
+// los headers RateLimit-Limit, RateLimit-Remaining y RateLimit-Reset.
+func (a *Authenticator) Handler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		plain := requestKey(c)
+		if plain == "" {
+			return unauthorized(c, "se requiere una API key en Authorization: Bearer o X-API-Key")
+		}
+
+		key, err := a.service.Authenticate(plain)
+		if errors.Is(err, domain.ErrInvalidKey) {
+			return unauthorized(c, "la API key no existe o fue revocada")
+		}
+		if err != nil {
+			log.Printf("Error validando la API key: %v", err)
+			return problem.Write(c, fiber.StatusInternalServerError, "Error validating API key", "")
+		}
+
+		bucket := a.bucketFor(key)
+		ok, remaining, reset := bucket.Allow()
+		c.Set("RateLimit-Limit", strconv.Itoa(bucket.Burst()))
+		c.Set("RateLimit-Remaining", strconv.Itoa(remaining))
+		c.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(reset)))
+		if !ok {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(ceilSeconds(reset)))
+			return problem.Write(c, fiber.StatusTooManyRequests, "Too many requests", "se superó el límite de peticiones de la API key")
+		}
+
+		a.touch(key)
+		c.Locals(localsKey, key)
+		return c.Next()
+	}
+}
+
+// bucketFor devuelve el balde de la clave; si cambiaron sus límites se crea
+// uno nuevo.
+func (a *Authenticator) bucketFor(key domain.APIKey) *ratelimit.TokenBucket {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	l, ok := a.limiters[key.ID]
+	if !ok || l.rate != key.RatePerSec || l.burst != key.Burst {
+		l = &limiter{
+			bucket: ratelimit.NewTokenBucket(key.RatePerSec, key.Burst),
+			rate:   key.RatePerSec,
+			burst:  key.Burst,
+		}
+		a.limiters[key.ID] = l
+	}
+	return l.bucket
+}
+
+func (a *Authenticator) touch(key domain.APIKey) {
+	now := time.Now()
+
+	a.mu.Lock()
+	if now.Sub(a.touched[key.ID]) < touchEvery {
+		a.mu.Unlock()
+		return
+	}
+	a.touched[key.ID] = now
+	a.mu.Unlock()
+
+	if err := a.service.TouchLastUsed(key.ID, now); err != nil {
+		log.Printf("Error actualizando last_used_at de la API key %s: %v", key.Prefix, err)
+	}
+}
+
+// DevAuth reemplaza a Authenticator con API_AUTH=off: todas las peticiones
+// pasan con todos los scopes y sin límite de tasa.
+func DevAuth() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(localsKey, devKey)
+		return c.Next()
+	}
+}
+
+// RequireScope responde 403 si la clave autenticada no tiene el scope.
+func RequireScope(scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key, ok := KeyFromContext(c)
+		if !ok {
+			return unauthorized(c, "se requiere una API key")
+		}
+		if !key.HasScope(scope) {
+			return problem.Write(c, fiber.StatusForbidden, "Forbidden", "la API key no tiene el scope "+scope)
+		}
+		return c.Next()
+	}
+}
+
+// KeyFromContext devuelve la clave autenticada de la petición.
+func KeyFromContext(c *fiber.Ctx) (domain.APIKey, bool) {
+	key, ok := c.Locals(localsKey).(domain.APIKey)
+	return key, ok
+}
+
+func requestKey(c *fiber.Ctx) string {
+	if key := strings.TrimSpace(c.Get("X-API-Key")); key != "" {
+		return key
+	}
+	if key, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer "); ok {
+		return strings.TrimSpace(key)
+	}
+	return ""
+}
+
+func unauthorized(c *fiber.Ctx, message string) error {
+	c.Set(fiber.HeaderWWWAuthenticate, "Bearer")
+	return problem.Write(c, fiber.StatusUnauthorized, "Unauthorized", message)
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
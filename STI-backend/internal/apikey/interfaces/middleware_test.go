@@ -0,0 +1,137 @@
+package interfaces
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/viteant/stockinsight/internal/apikey/domain"
+	"github.com/viteant/stockinsight/internal/apikey/use_cases"
+)
+
+type fakeKeys struct {
+	keys    []domain.APIKey
+	touched int
+}
+
+func (f *fakeKeys) Create(key domain.APIKey) (domain.APIKey, error) {
+	key.ID = key.Prefix
+	f.keys = append(f.keys, key)
+	return key, nil
+}
+
+func (f *fakeKeys) FindByHash(hash string) (domain.APIKey, error) {
+	for _, k := range f.keys {
+		if k.Hash == hash && k.RevokedAt == nil {
+			return k, nil
+		}
+	}
+	return domain.APIKey{}, domain.ErrKeyNotFound
+}
+
+func (f *fakeKeys) List() ([]domain.APIKey, error) {
+	return f.keys, nil
+}
+
+func (f *fakeKeys) Revoke(idOrPrefix string) (domain.APIKey, error) {
+	return domain.APIKey{}, domain.ErrKeyNotFound
+}
+
+func (f *fakeKeys) TouchLastUsed(id string, at time.Time) error {
+	f.touched++
+	return nil
+}
+
+func newTestApp(t *testing.T, burst int) (*fiber.App, *fakeKeys, map[string]string) {
+	t.Helper()
+	repo := &fakeKeys{}
+	service := &use_cases.APIKeyService{Repo: repo, DefaultRate: 1, DefaultBurst: burst}
+
+	keys := map[string]string{}
+	for name, scope := range map[string]string{"lector": domain.ScopeRead, "escritor": domain.ScopeWrite, "admin": domain.ScopeAdmin} {
+		plain, _, err := service.Create(name, []string{scope}, 0, 0)
+		assert.NoError(t, err)
+		keys[name] = plain
+	}
+
+	app := fiber.New()
+	group := app.Group("/api", NewAuthenticator(service).Handler(), RequireScope(domain.ScopeRead))
+	group.Get("/stocks", func(c *fiber.Ctx) error { return c.SendString("ok") })
+	group.Post("/backtests", RequireScope(domain.ScopeWrite), func(c *fiber.Ctx) error { return c.SendString("ok") })
+	group.Post("/admin/jobs", RequireScope(domain.ScopeAdmin), func(c *fiber.Ctx) error { return c.SendString("ok") })
+	return app, repo, keys
+}
+
+func TestAuthenticator_Credentials(t *testing.T) {
+	app, _, keys := newTestApp(t, 10)
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		header string
+		value  string
+		want   int
+	}{
+		{"sin clave", "GET", "/api/stocks", "", "", http.StatusUnauthorized},
+		{"clave inválida", "GET", "/api/stocks", "X-API-Key", "sti_otra", http.StatusUnauthorized},
+		{"bearer", "GET", "/api/stocks", "Authorization", "Bearer " + keys["lector"], http.StatusOK},
+		{"x-api-key", "GET", "/api/stocks", "X-API-Key", keys["lector"], http.StatusOK},
+		{"read sin admin", "POST", "/api/admin/jobs", "X-API-Key", keys["lector"], http.StatusForbidden},
+		{"admin", "POST", "/api/admin/jobs", "X-API-Key", keys["admin"], http.StatusOK},
+		{"write lee", "GET", "/api/stocks", "X-API-Key", keys["escritor"], http.StatusOK},
+		{"read sin write", "POST", "/api/backtests", "X-API-Key", keys["lector"], http.StatusForbidden},
+		{"write", "POST", "/api/backtests", "X-API-Key", keys["escritor"], http.StatusOK},
+		{"admin incluye write", "POST", "/api/backtests", "X-API-Key", keys["admin"], http.StatusOK},
+		{"write sin admin", "POST", "/api/admin/jobs", "X-API-Key", keys["escritor"], http.StatusForbidden},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(c.method, c.path, nil)
+		if c.header != "" {
+			req.Header.Set(c.header, c.value)
+		}
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, c.want, resp.StatusCode, c.name)
+	}
+}
+
+func TestAuthenticator_RateLimit(t *testing.T) {
+	app, repo, keys := newTestApp(t, 2)
+
+	get := func() *http.Response {
+		req := httptest.NewRequest("GET", "/api/stocks", nil)
+		req.Header.Set("X-API-Key", keys["lector"])
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		return resp
+	}
+
+	resp := get()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "2", resp.Header.Get("RateLimit-Limit"))
+	assert.Equal(t, "1", resp.Header.Get("RateLimit-Remaining"))
+	assert.Equal(t, "1", resp.Header.Get("RateLimit-Reset"))
+
+	resp = get()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "0", resp.Header.Get("RateLimit-Remaining"))
+
+	resp = get()
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "0", resp.Header.Get("RateLimit-Remaining"))
+	assert.Equal(t, "1", resp.Header.Get("Retry-After"))
+
+	// El límite es por clave.
+	req := httptest.NewRequest("GET", "/api/stocks", nil)
+	req.Header.Set("X-API-Key", keys["admin"])
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// last_used_at se escribe una vez por minuto y clave.
+	assert.Equal(t, 2, repo.touched)
+}
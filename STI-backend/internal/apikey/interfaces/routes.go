@@ -0,0 +1,26 @@
+package interfaces
+
+import (
+	"database/sql"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/viteant/stockinsight/internal/apikey/infrastructure/repository"
+	"github.com/viteant/stockinsight/internal/apikey/use_cases"
+)
+
+// Middleware exige una API key válida. Con API_AUTH=off no se valida nada,
+// pensado solo para desarrollo local.
+func Middleware(db *sql.DB) fiber.Handler {
+	if strings.EqualFold(os.Getenv("API_AUTH"), "off") {
+		log.Println("⚠️  API_AUTH=off: la API no exige API key")
+		return DevAuth()
+	}
+	return NewAuthenticator(newAPIKeyService(db)).Handler()
+}
+
+func newAPIKeyService(db *sql.DB) *use_cases.APIKeyService {
+	return use_cases.NewAPIKeyService(repository.NewCockroachAPIKeyRepository(db))
+}
@@ -0,0 +1,109 @@
+package use_cases
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/viteant/stockinsight/internal/apikey/domain"
+)
+
+const (
+	defaultRatePerSec = 10
+	defaultBurst      = 20
+)
+
+type APIKeyService struct {
+	Repo domain.APIKeyRepository
+	// Límites que se asignan a las claves nuevas si no se indican otros.
+	DefaultRate  float64
+	DefaultBurst int
+}
+
+// NewAPIKeyService lee los límites por defecto de API_KEY_RATE_PER_SEC y
+// API_KEY_BURST.
+func NewAPIKeyService(repo domain.APIKeyRepository) *APIKeyService {
+	s := &APIKeyService{
+		Repo:         repo,
+		DefaultRate:  defaultRatePerSec,
+		DefaultBurst: defaultBurst,
+	}
+	if v, err := strconv.ParseFloat(os.Getenv("API_KEY_RATE_PER_SEC"), 64); err == nil && v > 0 {
+		s.DefaultRate = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("API_KEY_BURST")); err == nil && v > 0 {
+		s.DefaultBurst = v
+	}
+	return s
+}
+
+// Create genera una clave nueva. La clave en claro solo se devuelve aquí; en
+// la base queda únicamente su hash. rate y burst en 0 usan los valores por
+// defecto.
+func (s *APIKeyService) Create(name string, scopes []string, rate float64, burst int) (string, domain.APIKey, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", domain.APIKey{}, errors.New("el nombre de la API key es obligatorio")
+	}
+	if len(scopes) == 0 {
+		scopes = []string{domain.ScopeRead}
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(domain.Scopes, scope) {
+			return "", domain.APIKey{}, fmt.Errorf("%w: %q", domain.ErrInvalidScope, scope)
+		}
+	}
+	if rate <= 0 {
+		rate = s.DefaultRate
+	}
+	if burst <= 0 {
+		burst = s.DefaultBurst
+	}
+
+	plain, err := domain.GenerateKey()
+	if err != nil {
+		return "", domain.APIKey{}, err
+	}
+
+	key, err := s.Repo.Create(domain.APIKey{
+		Name:       name,
+		Prefix:     domain.KeyPrefix(plain),
+		Scopes:     slices.Compact(slices.Sorted(slices.Values(scopes))),
+		RatePerSec: rate,
+		Burst:      burst,
+		Hash:       domain.HashKey(plain),
+	})
+	if err != nil {
+		return "", key, err
+	}
+	return plain, key, nil
+}
+
+func (s *APIKeyService) List() ([]domain.APIKey, error) {
+	return s.Repo.List()
+}
+
+func (s *APIKeyService) Revoke(idOrPrefix string) (domain.APIKey, error) {
+	return s.Repo.Revoke(idOrPrefix)
+}
+
+// Authenticate busca la clave en claro. Devuelve ErrInvalidKey si no existe
+// o fue revocada.
+func (s *APIKeyService) Authenticate(plain string) (domain.APIKey, error) {
+	if plain == "" {
+		return domain.APIKey{}, domain.ErrInvalidKey
+	}
+	key, err := s.Repo.FindByHash(domain.HashKey(plain))
+	if errors.Is(err, domain.ErrKeyNotFound) {
+		return key, domain.ErrInvalidKey
+	}
+	return key, err
+}
+
+func (s *APIKeyService) TouchLastUsed(id string, at time.Time) error {
+	return s.Repo.TouchLastUsed(id, at)
+}
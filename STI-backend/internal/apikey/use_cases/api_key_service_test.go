@@ -0,0 +1,119 @@
+package use_cases
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viteant/stockinsight/internal/apikey/domain"
+)
+
+type fakeKeys struct {
+	keys []domain.APIKey
+}
+
+func (f *fakeKeys) Create(key domain.APIKey) (domain.APIKey, error) {
+	key.ID = key.Prefix
+	key.CreatedAt = time.Now()
+	f.keys = append(f.keys, key)
+	return key, nil
+}
+
+func (f *fakeKeys) FindByHash(hash string) (domain.APIKey, error) {
+	for _, k := range f.keys {
+		if k.Hash == hash && k.RevokedAt == nil {
+			return k, nil
+		}
+	}
+	return domain.APIKey{}, domain.ErrKeyNotFound
+}
+
+func (f *fakeKeys) List() ([]domain.APIKey, error) {
+	return f.keys, nil
+}
+
+func (f *fakeKeys) Revoke(idOrPrefix string) (domain.APIKey, error) {
+	var matches []int
+	for i, k := range f.keys {
+		if k.ID == idOrPrefix || k.Prefix == idOrPrefix {
+			matches = append(matches, i)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return domain.APIKey{}, domain.ErrKeyNotFound
+	case 1:
+	default:
+		return domain.APIKey{}, domain.ErrAmbiguousKey
+	}
+	now := time.Now()
+	f.keys[matches[0]].RevokedAt = &now
+	return f.keys[matches[0]], nil
+}
+
+func (f *fakeKeys) TouchLastUsed(id string, at time.Time) error {
+	return nil
+}
+
+func TestAPIKeyService_CreateAuthenticateRevoke(t *testing.T) {
+	repo := &fakeKeys{}
+	s := &APIKeyService{Repo: repo, DefaultRate: 5, DefaultBurst: 10}
+
+	plain, key, err := s.Create("frontend", nil, 0, 0)
+	assert.NoError(t, err)
+	assert.Len(t, plain, 68)
+	assert.Equal(t, domain.KeyPrefix(plain), key.Prefix)
+	assert.Equal(t, []string{domain.ScopeRead}, key.Scopes)
+	assert.Equal(t, 5.0, key.RatePerSec)
+	assert.Equal(t, 10, key.Burst)
+	assert.NotEqual(t, plain, key.Hash)
+
+	found, err := s.Authenticate(plain)
+	assert.NoError(t, err)
+	assert.Equal(t, key.ID, found.ID)
+	assert.True(t, found.HasScope(domain.ScopeRead))
+	assert.False(t, found.HasScope(domain.ScopeAdmin))
+
+	_, err = s.Authenticate(plain + "x")
+	assert.ErrorIs(t, err, domain.ErrInvalidKey)
+
+	_, err = s.Revoke(key.Prefix)
+	assert.NoError(t, err)
+	_, err = s.Authenticate(plain)
+	assert.ErrorIs(t, err, domain.ErrInvalidKey)
+}
+
+func TestAPIKeyService_RevokeRejectsAmbiguousPrefix(t *testing.T) {
+	repo := &fakeKeys{keys: []domain.APIKey{
+		{ID: "id-1", Prefix: "sti_0000aaaa"},
+		{ID: "id-2", Prefix: "sti_0000aaaa"},
+	}}
+	s := &APIKeyService{Repo: repo}
+
+	_, err := s.Revoke("sti_0000aaaa")
+	assert.ErrorIs(t, err, domain.ErrAmbiguousKey)
+	assert.Nil(t, repo.keys[0].RevokedAt)
+	assert.Nil(t, repo.keys[1].RevokedAt)
+
+	key, err := s.Revoke("id-2")
+	assert.NoError(t, err)
+	assert.Equal(t, "id-2", key.ID)
+	assert.Nil(t, repo.keys[0].RevokedAt)
+}
+
+func TestAPIKeyService_Validation(t *testing.T) {
+	s := &APIKeyService{Repo: &fakeKeys{}, DefaultRate: 5, DefaultBurst: 10}
+
+	_, _, err := s.Create(" ", nil, 0, 0)
+	assert.Error(t, err)
+
+	_, _, err = s.Create("ops", []string{"delete"}, 0, 0)
+	assert.ErrorIs(t, err, domain.ErrInvalidScope)
+
+	_, key, err := s.Create("ops", []string{"admin", "read", "admin"}, 1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{domain.ScopeAdmin, domain.ScopeRead}, key.Scopes)
+	assert.True(t, key.HasScope(domain.ScopeRead))
+	assert.Equal(t, 1.0, key.RatePerSec)
+	assert.Equal(t, 2, key.Burst)
+}
@@ -0,0 +1,137 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	financedomain "github.com/viteant/stockinsight/internal/finance/domain"
+	stockdomain "github.com/viteant/stockinsight/internal/stock/domain"
+)
+
+const (
+	DefaultHoldDays       = 30
+	DefaultInitialCapital = 10000
+)
+
+var ErrInvalidConfig = errors.New("invalid backtest config")
+
+// Config describe una estrategia "seguir al broker": comprar en cada upgrade
+// de Brokerage y vender tras HoldDays días hábiles o ante un downgrade del
+// mismo broker sobre el ticker.
+type Config struct {
+	Brokerage      string                   `json:"brokerage"`
+	HoldDays       int                      `json:"hold_days"`
+	From           time.Time                `json:"from"`
+	To             time.Time                `json:"to"`
+	PriceBasis     financedomain.PriceBasis `json:"price_basis" swaggertype:"string"`
+	InitialCapital float64                  `json:"initial_capital"`
+}
+
+// Normalize completa los valores por defecto y valida la configuración.
+func (c Config) Normalize() (Config, error) {
+	c.Brokerage = strings.TrimSpace(c.Brokerage)
+	if c.Brokerage == "" {
+		return c, fmt.Errorf("%w: brokerage es obligatorio", ErrInvalidConfig)
+	}
+	if c.HoldDays == 0 {
+		c.HoldDays = DefaultHoldDays
+	}
+	if c.HoldDays < 0 {
+		return c, fmt.Errorf("%w: hold_days debe ser positivo", ErrInvalidConfig)
+	}
+	if c.InitialCapital == 0 {
+		c.InitialCapital = DefaultInitialCapital
+	}
+	if c.InitialCapital < 0 {
+		return c, fmt.Errorf("%w: initial_capital debe ser positivo", ErrInvalidConfig)
+	}
+	if c.PriceBasis == "" {
+		c.PriceBasis = financedomain.PriceBasisAdjusted
+	}
+	if c.To.IsZero() {
+		c.To = financedomain.Day(time.Now())
+	}
+	if c.From.IsZero() {
+		c.From = c.To.AddDate(-1, 0, 0)
+	}
+	if !c.From.Before(c.To) {
+		return c, fmt.Errorf("%w: from debe ser anterior a to", ErrInvalidConfig)
+	}
+	return c, nil
+}
+
+// RatingEvent es un rating del broker con su calificación normalizada.
+// ActionType puede venir vacío en filas aún no clasificadas; en ese caso se
+// clasifica con stockdomain.ClassifyAction.
+type RatingEvent struct {
+	Ticker     string
+	Date       time.Time
+	Action     string
+	ActionType string
+	RatingFrom string
+	RatingTo   string
+}
+
+func (e RatingEvent) actionType() string {
+	if e.ActionType != "" {
+		return e.ActionType
+	}
+	return stockdomain.ClassifyAction(e.Action, e.RatingFrom, e.RatingTo)
+}
+
+func (e RatingEvent) IsUpgrade() bool {
+	return e.actionType() == stockdomain.ActionUpgrade
+}
+
+func (e RatingEvent) IsDowngrade() bool {
+	return e.actionType() == stockdomain.ActionDowngrade
+}
+
+// PricePoint es un cierre diario en la base de precio pedida.
+type PricePoint struct {
+	Date  time.Time
+	Close float64
+}
+
+const (
+	ExitHoldDays  = "hold_days"
+	ExitDowngrade = "downgrade"
+	ExitEndOfData = "end_of_data"
+)
+
+type Trade struct {
+	Ticker     string    `json:"ticker"`
+	EntryDate  time.Time `json:"entry_date"`
+	EntryPrice float64   `json:"entry_price"`
+	ExitDate   time.Time `json:"exit_date"`
+	ExitPrice  float64   `json:"exit_price"`
+	ExitReason string    `json:"exit_reason"`
+	Return     float64   `json:"return"`
+}
+
+type EquityPoint struct {
+	Date      time.Time `json:"date"`
+	Equity    float64   `json:"equity"`
+	Benchmark float64   `json:"benchmark"`
+	Positions int       `json:"positions"`
+}
+
+// Metrics usa retornos como fracción (0.12 = 12%). MaxDrawdown es la mayor
+// caída desde un máximo previo, como número positivo.
+type Metrics struct {
+	TotalReturn float64 `json:"total_return"`
+	CAGR        float64 `json:"cagr"`
+	MaxDrawdown float64 `json:"max_drawdown"`
+}
+
+type Result struct {
+	Config      Config        `json:"config"`
+	Strategy    Metrics       `json:"strategy"`
+	Benchmark   Metrics       `json:"benchmark"`
+	HitRate     float64       `json:"hit_rate"`
+	TotalTrades int           `json:"total_trades"`
+	Trades      []Trade       `json:"trades"`
+	EquityCurve []EquityPoint `json:"equity_curve"`
+}
@@ -0,0 +1,12 @@
+package domain
+
+import (
+	"time"
+
+	financedomain "github.com/viteant/stockinsight/internal/finance/domain"
+)
+
+type BacktestRepository interface {
+	ListRatingEvents(brokerage string, from, to time.Time) ([]RatingEvent, error)
+	LoadCloses(from, to time.Time, basis financedomain.PriceBasis) (map[string][]PricePoint, error)
+}
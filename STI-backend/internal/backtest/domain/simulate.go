@@ -0,0 +1,234 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	financedomain "github.com/viteant/stockinsight/internal/finance/domain"
+)
+
+// Run reproduce los eventos del broker sobre los cierres. Cada upgrade abre
+// una posición al cierre del primer día con precio desde el rating (si el
+// ticker no está ya en cartera) y se cierra tras HoldDays cierres, en el
+// primer cierre desde un downgrade o al final de los datos. El capital se
+// reparte en partes iguales entre las posiciones abiertas cada día y queda en
+// efectivo, sin rendimiento, cuando no hay ninguna.
+//
+// El benchmark es una cartera equiponderada de todos los tickers de prices,
+// rebalanceada a diario. prices debe venir ordenado por fecha; no se modifica.
+func Run(cfg Config, events []RatingEvent, input map[string][]PricePoint) Result {
+	prices := make(map[string][]PricePoint, len(input))
+	for ticker, series := range input {
+		prices[ticker] = clip(series, cfg.From, cfg.To)
+	}
+
+	byTicker := make(map[string][]RatingEvent)
+	for _, e := range events {
+		byTicker[e.Ticker] = append(byTicker[e.Ticker], e)
+	}
+
+	var trades []Trade
+	strategy := make(map[time.Time]*dailyReturns)
+	for ticker, tickerEvents := range byTicker {
+		sort.Slice(tickerEvents, func(i, j int) bool {
+			return tickerEvents[i].Date.Before(tickerEvents[j].Date)
+		})
+		series := prices[ticker]
+		for _, t := range tradesFor(ticker, tickerEvents, series, cfg.HoldDays) {
+			trades = append(trades, t.trade)
+			for i := t.entry + 1; i <= t.exit; i++ {
+				add(strategy, series[i].Date, series[i].Close/series[i-1].Close-1)
+			}
+		}
+	}
+	sort.Slice(trades, func(i, j int) bool {
+		if !trades[i].EntryDate.Equal(trades[j].EntryDate) {
+			return trades[i].EntryDate.Before(trades[j].EntryDate)
+		}
+		return trades[i].Ticker < trades[j].Ticker
+	})
+
+	benchmark := make(map[time.Time]*dailyReturns)
+	dateSet := make(map[time.Time]bool)
+	for _, series := range prices {
+		for i, p := range series {
+			dateSet[p.Date] = true
+			if i > 0 && series[i-1].Close > 0 {
+				add(benchmark, p.Date, p.Close/series[i-1].Close-1)
+			}
+		}
+	}
+	dates := make([]time.Time, 0, len(dateSet))
+	for d := range dateSet {
+		dates = append(dates, d)
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+	curve := make([]EquityPoint, 0, len(dates))
+	equity, bench := cfg.InitialCapital, cfg.InitialCapital
+	for _, d := range dates {
+		positions := 0
+		if r, ok := strategy[d]; ok {
+			equity *= 1 + r.mean()
+			positions = r.count
+		}
+		if r, ok := benchmark[d]; ok {
+			bench *= 1 + r.mean()
+		}
+		curve = append(curve, EquityPoint{Date: d, Equity: round2(equity), Benchmark: round2(bench), Positions: positions})
+	}
+
+	hits := 0
+	for _, t := range trades {
+		if t.Return > 0 {
+			hits++
+		}
+	}
+	hitRate := 0.0
+	if len(trades) > 0 {
+		hitRate = round4(float64(hits) / float64(len(trades)))
+	}
+
+	return Result{
+		Config:      cfg,
+		Strategy:    metrics(curve, cfg.InitialCapital, func(p EquityPoint) float64 { return p.Equity }),
+		Benchmark:   metrics(curve, cfg.InitialCapital, func(p EquityPoint) float64 { return p.Benchmark }),
+		HitRate:     hitRate,
+		TotalTrades: len(trades),
+		Trades:      trades,
+		EquityCurve: curve,
+	}
+}
+
+type openTrade struct {
+	trade       Trade
+	entry, exit int
+}
+
+func tradesFor(ticker string, events []RatingEvent, series []PricePoint, holdDays int) []openTrade {
+	var trades []openTrade
+	if len(series) == 0 {
+		return trades
+	}
+
+	var heldUntil time.Time
+	for i, e := range events {
+		if !e.IsUpgrade() || !financedomain.Day(e.Date).After(heldUntil) {
+			continue
+		}
+		entry := indexFrom(series, e.Date)
+		if entry >= len(series)-1 {
+			continue
+		}
+
+		exit := entry + holdDays
+		reason := ExitHoldDays
+		if exit >= len(series) {
+			exit = len(series) - 1
+			reason = ExitEndOfData
+		}
+		for _, next := range events[i+1:] {
+			if !next.IsDowngrade() {
+				continue
+			}
+			// Un downgrade del mismo día de entrada no cierra la posición; se
+			// sigue buscando el siguiente.
+			idx := indexFrom(series, next.Date)
+			if idx <= entry {
+				continue
+			}
+			if idx < exit {
+				exit = idx
+				reason = ExitDowngrade
+			}
+			break
+		}
+
+		in, out := series[entry], series[exit]
+		trades = append(trades, openTrade{
+			trade: Trade{
+				Ticker:     ticker,
+				EntryDate:  in.Date,
+				EntryPrice: in.Close,
+				ExitDate:   out.Date,
+				ExitPrice:  out.Close,
+				ExitReason: reason,
+				Return:     round4(out.Close/in.Close - 1),
+			},
+			entry: entry,
+			exit:  exit,
+		})
+		heldUntil = out.Date
+	}
+	return trades
+}
+
+// indexFrom devuelve el primer cierre en o después de t.
+func indexFrom(series []PricePoint, t time.Time) int {
+	day := financedomain.Day(t)
+	return sort.Search(len(series), func(i int) bool {
+		return !series[i].Date.Before(day)
+	})
+}
+
+func clip(series []PricePoint, from, to time.Time) []PricePoint {
+	result := make([]PricePoint, 0, len(series))
+	for _, p := range series {
+		if p.Close > 0 && !p.Date.Before(from) && !p.Date.After(to) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+type dailyReturns struct {
+	sum   float64
+	count int
+}
+
+func (r *dailyReturns) mean() float64 {
+	return r.sum / float64(r.count)
+}
+
+func add(m map[time.Time]*dailyReturns, d time.Time, ret float64) {
+	r, ok := m[d]
+	if !ok {
+		r = &dailyReturns{}
+		m[d] = r
+	}
+	r.sum += ret
+	r.count++
+}
+
+func metrics(curve []EquityPoint, initial float64, value func(EquityPoint) float64) Metrics {
+	if len(curve) == 0 || initial <= 0 {
+		return Metrics{}
+	}
+
+	peak, maxDrawdown := initial, 0.0
+	for _, p := range curve {
+		v := value(p)
+		peak = math.Max(peak, v)
+		maxDrawdown = math.Max(maxDrawdown, (peak-v)/peak)
+	}
+
+	final := value(curve[len(curve)-1])
+	m := Metrics{
+		TotalReturn: round4(final/initial - 1),
+		MaxDrawdown: round4(maxDrawdown),
+	}
+	years := curve[len(curve)-1].Date.Sub(curve[0].Date).Hours() / 24 / 365.25
+	if years > 0 {
+		m.CAGR = round4(math.Pow(final/initial, 1/years) - 1)
+	}
+	return m
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func round4(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
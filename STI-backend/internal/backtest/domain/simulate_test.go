@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func day(d int) time.Time {
+	return time.Date(2024, 7, d, 0, 0, 0, 0, time.UTC)
+}
+
+func series(closes ...float64) []PricePoint {
+	points := make([]PricePoint, len(closes))
+	for i, c := range closes {
+		points[i] = PricePoint{Date: day(i + 1), Close: c}
+	}
+	return points
+}
+
+func TestRun_FollowBroker(t *testing.T) {
+	cfg, err := Config{Brokerage: "X", HoldDays: 3, From: day(1), To: day(10)}.Normalize()
+	assert.NoError(t, err)
+
+	events := []RatingEvent{
+		// AAA: upgrade el día 2, downgrade el día 4 → sale antes de los 3 días.
+		{Ticker: "AAA", Date: day(2), Action: "upgraded by", RatingFrom: "hold", RatingTo: "buy"},
+		{Ticker: "AAA", Date: day(4), Action: "downgraded by", RatingFrom: "buy", RatingTo: "hold"},
+		// BBB: upgrade el día 5, sale tras 3 cierres (día 8).
+		{Ticker: "BBB", Date: day(5), Action: "target raised by", RatingFrom: "sell", RatingTo: "hold"},
+		// Reiteración: no abre posición.
+		{Ticker: "BBB", Date: day(6), Action: "reiterated by", RatingFrom: "hold", RatingTo: "hold"},
+	}
+	prices := map[string][]PricePoint{
+		"AAA": series(100, 100, 110, 121, 121, 121, 121, 121, 121, 121),
+		"BBB": series(50, 50, 50, 50, 50, 55, 55, 44, 44, 44),
+	}
+
+	result := Run(cfg, events, prices)
+
+	assert.Equal(t, 2, result.TotalTrades)
+	assert.Equal(t, Trade{
+		Ticker: "AAA", EntryDate: day(2), EntryPrice: 100,
+		ExitDate: day(4), ExitPrice: 121, ExitReason: ExitDowngrade, Return: 0.21,
+	}, result.Trades[0])
+	assert.Equal(t, ExitHoldDays, result.Trades[1].ExitReason)
+	assert.Equal(t, day(8), result.Trades[1].ExitDate)
+	assert.Equal(t, -0.12, result.Trades[1].Return)
+	assert.Equal(t, 0.5, result.HitRate)
+
+	// Estrategia: +10%, +10%, luego +10% y -20% en BBB → 10000·1.21·1.1·0.8.
+	assert.InDelta(t, 0.0648, result.Strategy.TotalReturn, 1e-4)
+	assert.InDelta(t, 0.2, result.Strategy.MaxDrawdown, 1e-4)
+	assert.Len(t, result.EquityCurve, 10)
+
+	// Benchmark equiponderado: AAA +21%, BBB -12% en promedio día a día.
+	assert.Greater(t, result.Benchmark.TotalReturn, 0.0)
+}
+
+func TestRun_SkipsSameDayDowngrade(t *testing.T) {
+	cfg, err := Config{Brokerage: "X", HoldDays: 5, From: day(1), To: day(10)}.Normalize()
+	assert.NoError(t, err)
+
+	events := []RatingEvent{
+		// Upgrade y downgrade el día 2: la entrada se mantiene hasta el
+		// downgrade del día 4.
+		{Ticker: "AAA", Date: day(2), Action: "upgraded by", RatingFrom: "hold", RatingTo: "buy"},
+		{Ticker: "AAA", Date: day(2), Action: "downgraded by", RatingFrom: "buy", RatingTo: "hold"},
+		{Ticker: "AAA", Date: day(4), Action: "downgraded by", RatingFrom: "buy", RatingTo: "hold"},
+	}
+	prices := map[string][]PricePoint{
+		"AAA": series(100, 100, 105, 110, 90, 90, 90, 90, 90, 90),
+	}
+
+	result := Run(cfg, events, prices)
+
+	assert.Equal(t, 1, result.TotalTrades)
+	assert.Equal(t, Trade{
+		Ticker: "AAA", EntryDate: day(2), EntryPrice: 100,
+		ExitDate: day(4), ExitPrice: 110, ExitReason: ExitDowngrade, Return: 0.1,
+	}, result.Trades[0])
+}
+
+func TestRun_DoesNotMutatePrices(t *testing.T) {
+	cfg, err := Config{Brokerage: "X", From: day(3), To: day(5)}.Normalize()
+	assert.NoError(t, err)
+
+	prices := map[string][]PricePoint{"AAA": series(100, 101, 102, 103, 104, 105, 106)}
+	Run(cfg, nil, prices)
+
+	assert.Len(t, prices["AAA"], 7)
+	assert.Equal(t, day(1), prices["AAA"][0].Date)
+}
+
+func TestConfigNormalize(t *testing.T) {
+	_, err := Config{}.Normalize()
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+
+	cfg, err := Config{Brokerage: "X", To: day(10)}.Normalize()
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultHoldDays, cfg.HoldDays)
+	assert.Equal(t, day(10).AddDate(-1, 0, 0), cfg.From)
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/viteant/stockinsight/internal/backtest/domain"
+	financedomain "github.com/viteant/stockinsight/internal/finance/domain"
+)
+
+type PersistenceBacktestRepository struct {
+	DB *sql.DB
+}
+
+func NewCockroachBacktestRepository(db *sql.DB) *PersistenceBacktestRepository {
+	return &PersistenceBacktestRepository{DB: db}
+}
+
+// ListRatingEvents devuelve los ratings del broker (sin distinguir mayúsculas)
+// en el rango, ordenados por fecha.
+func (r *PersistenceBacktestRepository) ListRatingEvents(brokerage string, from, to time.Time) ([]domain.RatingEvent, error) {
+	rows, err := r.DB.Query(`
+		SELECT ticker, created_at, action, COALESCE(action_type, ''),
+			COALESCE(normalize_rating_from, ''), COALESCE(normalize_rating_to, '')
+		FROM stocks
+		WHERE lower(brokerage) = lower($1)
+			AND created_at >= $2 AND created_at < $3
+		ORDER BY created_at
+	`, brokerage, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []domain.RatingEvent
+	for rows.Next() {
+		var e domain.RatingEvent
+		if err := rows.Scan(&e.Ticker, &e.Date, &e.Action, &e.ActionType, &e.RatingFrom, &e.RatingTo); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// LoadCloses devuelve los cierres de todos los tickers en el rango. En la
+// base ajustada se usa adj_close, que se reajusta al guardar cada dividendo o
+// split nuevo y forma una serie continua.
+func (r *PersistenceBacktestRepository) LoadCloses(from, to time.Time, basis financedomain.PriceBasis) (map[string][]domain.PricePoint, error) {
+	column := "close"
+	if basis == financedomain.PriceBasisAdjusted {
+		column = "COALESCE(adj_close, close)"
+	}
+
+	rows, err := r.DB.Query(`
+		SELECT ticker, date, `+column+`
+		FROM finances
+		WHERE date BETWEEN $1 AND $2 AND close IS NOT NULL
+		ORDER BY ticker, date
+	`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prices := make(map[string][]domain.PricePoint)
+	for rows.Next() {
+		var ticker string
+		var p domain.PricePoint
+		if err := rows.Scan(&ticker, &p.Date, &p.Close); err != nil {
+			return nil, err
+		}
+		prices[ticker] = append(prices[ticker], p)
+	}
+	return prices, rows.Err()
+}
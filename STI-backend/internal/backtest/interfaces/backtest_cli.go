@@ -0,0 +1,36 @@
+package interfaces
+
+import (
+	"log"
+
+	"github.com/viteant/stockinsight/internal/backtest/infrastructure/repository"
+	"github.com/viteant/stockinsight/internal/backtest/use_cases"
+	"github.com/viteant/stockinsight/internal/db"
+)
+
+// RunBacktest ejecuta el backtest desde la línea de comandos e imprime el resumen.
+func RunBacktest(req BacktestRequest) error {
+	cfg, err := req.ToConfig()
+	if err != nil {
+		return err
+	}
+
+	dbConn := db.NewCockroachDB()
+	defer dbConn.Close()
+
+	service := &use_cases.BacktestService{Repo: repository.NewCockroachBacktestRepository(dbConn)}
+	result, err := service.Run(cfg)
+	if err != nil {
+		return err
+	}
+
+	c := result.Config
+	log.Printf("Backtest de %s (%s a %s, %d días hábiles, base %s)",
+		c.Brokerage, c.From.Format("2006-01-02"), c.To.Format("2006-01-02"), c.HoldDays, c.PriceBasis)
+	log.Printf("Operaciones: %d, tasa de aciertos: %.2f%%", result.TotalTrades, result.HitRate*100)
+	log.Printf("Estrategia: retorno %.2f%%, CAGR %.2f%%, máximo drawdown %.2f%%",
+		result.Strategy.TotalReturn*100, result.Strategy.CAGR*100, result.Strategy.MaxDrawdown*100)
+	log.Printf("Benchmark equiponderado: retorno %.2f%%, CAGR %.2f%%, máximo drawdown %.2f%%",
+		result.Benchmark.TotalReturn*100, result.Benchmark.CAGR*100, result.Benchmark.MaxDrawdown*100)
+	return nil
+}
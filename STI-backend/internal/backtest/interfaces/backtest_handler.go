@@ -0,0 +1,108 @@
+package interfaces
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/viteant/stockinsight/internal/backtest/domain"
+	"github.com/viteant/stockinsight/internal/backtest/use_cases"
+	financedomain "github.com/viteant/stockinsight/internal/finance/domain"
+	"github.com/viteant/stockinsight/internal/problem"
+)
+
+type BacktestHandler struct {
+	useCase *use_cases.BacktestService
+}
+
+func NewBacktestHandler(useCase *use_cases.BacktestService) *BacktestHandler {
+	return &BacktestHandler{
+		useCase: useCase,
+	}
+}
+
+// BacktestRequest es el cuerpo de POST /api/backtests. Las fechas van en
+// formato YYYY-MM-DD.
+type BacktestRequest struct {
+	Brokerage      string  `json:"brokerage"`
+	HoldDays       int     `json:"hold_days"`
+	From           string  `json:"from"`
+	To             string  `json:"to"`
+	PriceBasis     string  `json:"price_basis"`
+	InitialCapital float64 `json:"initial_capital"`
+}
+
+// fieldError es un campo inválido del pedido.
+type fieldError struct {
+	problem.FieldError
+}
+
+func (e *fieldError) Error() string {
+	return e.Field + ": " + e.Reason
+}
+
+// ToConfig valida las fechas y la base de precio del pedido. Los errores son
+// *fieldError con el campo rechazado.
+func (r BacktestRequest) ToConfig() (domain.Config, error) {
+	cfg := domain.Config{
+		Brokerage:      r.Brokerage,
+		HoldDays:       r.HoldDays,
+		InitialCapital: r.InitialCapital,
+	}
+
+	var err error
+	if r.From != "" {
+		if cfg.From, err = time.Parse("2006-01-02", r.From); err != nil {
+			return cfg, &fieldError{problem.FieldError{Field: "from", Value: r.From, Reason: "debe tener formato YYYY-MM-DD"}}
+		}
+	}
+	if r.To != "" {
+		if cfg.To, err = time.Parse("2006-01-02", r.To); err != nil {
+			return cfg, &fieldError{problem.FieldError{Field: "to", Value: r.To, Reason: "debe tener formato YYYY-MM-DD"}}
+		}
+	}
+	if r.PriceBasis != "" {
+		if cfg.PriceBasis, err = financedomain.ParsePriceBasis(r.PriceBasis); err != nil {
+			return cfg, &fieldError{problem.FieldError{Field: "price_basis", Value: r.PriceBasis, Reason: err.Error()}}
+		}
+	}
+	return cfg, nil
+}
+
+// CreateBacktest godoc
+// @Summary Backtest "seguir al broker"
+// @Description Simula comprar en cada upgrade del brokerage y vender tras hold_days días hábiles o ante un downgrade. Devuelve retorno total, CAGR, máximo drawdown, tasa de aciertos, operaciones y curva de capital, comparados con un benchmark equiponderado de todos los tickers. Requiere el scope write o admin.
+// @Tags Backtests
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param request body BacktestRequest true "Configuración del backtest (hold_days default: 30, from default: un año antes de to, to default: hoy, price_basis default: adjusted, initial_capital default: 10000)"
+// @Success 200 {object} domain.Result
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 429 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /api/backtests [post]
+func (h *BacktestHandler) CreateBacktest(c *fiber.Ctx) error {
+	var req BacktestRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.Write(c, fiber.StatusBadRequest, "Invalid request body", err.Error())
+	}
+
+	cfg, err := req.ToConfig()
+	var ferr *fieldError
+	if errors.As(err, &ferr) {
+		return problem.Invalid(c, ferr.FieldError)
+	}
+
+	result, err := h.useCase.Run(cfg)
+	if errors.Is(err, domain.ErrInvalidConfig) {
+		return problem.Write(c, fiber.StatusBadRequest, "Invalid backtest config", err.Error())
+	}
+	if err != nil {
+		return problem.Internal(c, "Error running backtest", err)
+	}
+	return c.JSON(result)
+}
@@ -0,0 +1,98 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/viteant/stockinsight/internal/backtest/domain"
+	"github.com/viteant/stockinsight/internal/backtest/use_cases"
+	financedomain "github.com/viteant/stockinsight/internal/finance/domain"
+	"github.com/viteant/stockinsight/internal/problem"
+)
+
+type fakeBacktestRepo struct {
+	brokerage string
+	basis     financedomain.PriceBasis
+}
+
+func (f *fakeBacktestRepo) ListRatingEvents(brokerage string, from, to time.Time) ([]domain.RatingEvent, error) {
+	f.brokerage = brokerage
+	return []domain.RatingEvent{
+		{Ticker: "AAA", Date: from.AddDate(0, 0, 1), Action: "upgraded by", RatingFrom: "hold", RatingTo: "buy"},
+	}, nil
+}
+
+func (f *fakeBacktestRepo) LoadCloses(from, to time.Time, basis financedomain.PriceBasis) (map[string][]domain.PricePoint, error) {
+	f.basis = basis
+	var points []domain.PricePoint
+	for d, price := from, 100.0; !d.After(to); d, price = d.AddDate(0, 0, 1), price+1 {
+		points = append(points, domain.PricePoint{Date: d, Close: price})
+	}
+	return map[string][]domain.PricePoint{"AAA": points}, nil
+}
+
+func newBacktestApp(repo domain.BacktestRepository) *fiber.App {
+	app := fiber.New()
+	app.Post("/api/backtests", NewBacktestHandler(&use_cases.BacktestService{Repo: repo}).CreateBacktest)
+	return app
+}
+
+func postBacktest(t *testing.T, app *fiber.App, body string) *http.Response {
+	req := httptest.NewRequest("POST", "/api/backtests", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestCreateBacktest_ValidatesRequest(t *testing.T) {
+	app := newBacktestApp(&fakeBacktestRepo{})
+
+	cases := []struct {
+		body  string
+		field string
+	}{
+		{`{"brokerage":"X","from":"01/07/2024"}`, "from"},
+		{`{"brokerage":"X","to":"2024-13-01"}`, "to"},
+		{`{"brokerage":"X","price_basis":"weird"}`, "price_basis"},
+	}
+	for _, tc := range cases {
+		resp := postBacktest(t, app, tc.body)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode, tc.body)
+
+		var body problem.Problem
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		if assert.Len(t, body.Errors, 1, tc.body) {
+			assert.Equal(t, tc.field, body.Errors[0].Field, tc.body)
+		}
+	}
+
+	resp := postBacktest(t, app, `{"hold_days":5}`)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+	resp = postBacktest(t, app, `{"brokerage":"X","from":"2024-07-10","to":"2024-07-01"}`)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestCreateBacktest_RunsSimulation(t *testing.T) {
+	repo := &fakeBacktestRepo{}
+	app := newBacktestApp(repo)
+
+	resp := postBacktest(t, app, `{"brokerage":" X ","hold_days":3,"from":"2024-07-01","to":"2024-07-10","price_basis":"raw"}`)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "X", repo.brokerage)
+	assert.Equal(t, financedomain.PriceBasisRaw, repo.basis)
+
+	var result domain.Result
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Equal(t, 1, result.TotalTrades)
+	assert.Len(t, result.EquityCurve, 10)
+}
@@ -0,0 +1,19 @@
+package interfaces
+
+import (
+	"database/sql"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/viteant/stockinsight/internal/backtest/infrastructure/repository"
+	"github.com/viteant/stockinsight/internal/backtest/use_cases"
+)
+
+// RegisterBacktestRoutes registra los endpoints de backtests. Lanzar uno
+// requiere requireWrite, porque cada simulación recorre todo el historial.
+func RegisterBacktestRoutes(app fiber.Router, db *sql.DB, requireWrite fiber.Handler) {
+	backtestRepo := repository.NewCockroachBacktestRepository(db)
+	backtestService := &use_cases.BacktestService{Repo: backtestRepo}
+	backtestHandler := NewBacktestHandler(backtestService)
+
+	app.Post("/backtests", requireWrite, backtestHandler.CreateBacktest)
+}
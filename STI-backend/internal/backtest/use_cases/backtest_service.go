@@ -0,0 +1,28 @@
+package use_cases
+
+import (
+	"github.com/viteant/stockinsight/internal/backtest/domain"
+)
+
+type BacktestService struct {
+	Repo domain.BacktestRepository
+}
+
+func (s *BacktestService) Run(cfg domain.Config) (domain.Result, error) {
+	cfg, err := cfg.Normalize()
+	if err != nil {
+		return domain.Result{}, err
+	}
+
+	events, err := s.Repo.ListRatingEvents(cfg.Brokerage, cfg.From, cfg.To)
+	if err != nil {
+		return domain.Result{}, err
+	}
+
+	prices, err := s.Repo.LoadCloses(cfg.From, cfg.To, cfg.PriceBasis)
+	if err != nil {
+		return domain.Result{}, err
+	}
+
+	return domain.Run(cfg, events, prices), nil
+}
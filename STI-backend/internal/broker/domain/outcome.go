@@ -0,0 +1,176 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	financedomain "github.com/viteant/stockinsight/internal/finance/domain"
+)
+
+// Horizons son los plazos, en días hábiles, a los que se evalúa cada rating.
+var Horizons = []int{7, 30, 90}
+
+const (
+	DirectionUp      = "up"
+	DirectionDown    = "down"
+	DirectionNeutral = "neutral"
+)
+
+// NeutralBand es la variación relativa máxima para considerar que un target
+// no espera movimiento y que el precio efectivamente no se movió.
+const NeutralBand = 0.02
+
+// Prediction es un rating con precio objetivo a evaluar.
+type Prediction struct {
+	StockID    string
+	Brokerage  string
+	Ticker     string
+	ReportedAt time.Time
+	TargetTo   float64
+}
+
+// PricePoint es una vela diaria de finances. AdjClose ya viene resuelto al
+// cierre cuando no hay ajustado. SplitRatio es la proporción del split que
+// se hizo efectivo ese día (4 en un split 4:1); 0 si no hubo.
+type PricePoint struct {
+	Date       time.Time
+	High       float64
+	Low        float64
+	Close      float64
+	AdjClose   float64
+	SplitRatio float64
+}
+
+// Outcome es el resultado de un rating a un horizonte y base de precio.
+type Outcome struct {
+	StockID        string
+	Brokerage      string
+	Ticker         string
+	HorizonDays    int
+	PriceBasis     financedomain.PriceBasis
+	PredictionDate time.Time
+	BaseDate       time.Time
+	BasePrice      float64
+	HorizonDate    time.Time
+	HorizonPrice   float64
+	TargetTo       float64
+	Direction      string
+	IsCorrect      bool
+	TargetHit      bool
+	SignedError    float64
+}
+
+// Evaluate mide un rating contra el cierre horizon días hábiles después.
+// El precio base es el cierre del primer día hábil desde el rating; la
+// dirección esperada es la del target respecto de ese precio, neutral si
+// está a no más de NeutralBand. Un rating neutral acierta si el cierre del
+// horizonte también queda dentro de esa banda. TargetHit
+// indica si el máximo (o mínimo, si se esperaba una baja) alcanzó el target
+// dentro del horizonte. SignedError es (target - cierre) / cierre en
+// porcentaje: positivo si el broker fue optimista.
+//
+// En la base ajustada el acierto se mide con la serie continua de cierres
+// ajustados, que incluye dividendos. El target, que está en precios del día
+// del rating, se divide por los splits acumulados hasta cada día con el que
+// se compara, y se compara con los precios sin ajustar de ese día.
+//
+// series debe venir ordenada por fecha. Devuelve false si el horizonte aún
+// no tiene precios o si falta el precio base.
+func Evaluate(
+	cal *financedomain.TradingCalendar,
+	p Prediction,
+	series []PricePoint,
+	horizon int,
+	basis financedomain.PriceBasis,
+) (Outcome, bool) {
+	day := financedomain.Day(p.ReportedAt)
+	baseIdx := sort.Search(len(series), func(i int) bool {
+		return !series[i].Date.Before(day)
+	})
+	if baseIdx == len(series) {
+		return Outcome{}, false
+	}
+
+	horizonDate := cal.AddTradingDays(day, horizon)
+	last := series[len(series)-1]
+	if last.Date.Before(horizonDate) {
+		return Outcome{}, false
+	}
+	// Último precio disponible hasta la fecha del horizonte, por si hay huecos.
+	endIdx := sort.Search(len(series), func(i int) bool {
+		return series[i].Date.After(horizonDate)
+	}) - 1
+	if endIdx <= baseIdx {
+		return Outcome{}, false
+	}
+
+	base := series[baseIdx]
+	end := series[endIdx]
+	adjusted := basis == financedomain.PriceBasisAdjusted
+	basePrice := base.Close
+	endPrice := end.Close
+	if adjusted {
+		basePrice = base.AdjClose
+		endPrice = end.AdjClose
+	}
+	if basePrice == 0 || endPrice == 0 || base.Close == 0 || end.Close == 0 {
+		return Outcome{}, false
+	}
+
+	o := Outcome{
+		StockID:        p.StockID,
+		Brokerage:      p.Brokerage,
+		Ticker:         p.Ticker,
+		HorizonDays:    horizon,
+		PriceBasis:     basis,
+		PredictionDate: p.ReportedAt,
+		BaseDate:       base.Date,
+		BasePrice:      basePrice,
+		HorizonDate:    end.Date,
+		HorizonPrice:   endPrice,
+		Direction:      direction(p.TargetTo, base.Close),
+	}
+
+	switch o.Direction {
+	case DirectionUp:
+		o.IsCorrect = endPrice > basePrice
+	case DirectionDown:
+		o.IsCorrect = endPrice < basePrice
+	default:
+		o.IsCorrect = withinBand(endPrice, basePrice)
+	}
+
+	// splits acumula los splits desde el día base: TargetTo / splits queda en
+	// precios sin ajustar de cada día.
+	splits := 1.0
+	for _, bar := range series[baseIdx+1 : endIdx+1] {
+		if adjusted && bar.SplitRatio > 0 {
+			splits *= bar.SplitRatio
+		}
+		target := p.TargetTo / splits
+		if (o.Direction == DirectionUp && bar.High >= target) ||
+			(o.Direction == DirectionDown && bar.Low <= target) {
+			o.TargetHit = true
+		}
+	}
+	o.TargetTo = p.TargetTo / splits
+	o.SignedError = (o.TargetTo - end.Close) / end.Close * 100
+
+	return o, true
+}
+
+func direction(target, price float64) string {
+	switch {
+	case withinBand(target, price):
+		return DirectionNeutral
+	case target > price:
+		return DirectionUp
+	}
+	return DirectionDown
+}
+
+// withinBand indica si price está a no más de NeutralBand de ref.
+func withinBand(price, ref float64) bool {
+	return math.Abs(price-ref) <= NeutralBand*math.Abs(ref)
+}
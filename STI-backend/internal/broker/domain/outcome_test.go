@@ -0,0 +1,137 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	financedomain "github.com/viteant/stockinsight/internal/finance/domain"
+)
+
+func TestEvaluate_UsesCloseAfterHorizonTradingDays(t *testing.T) {
+	cal := financedomain.NewNYSECalendar()
+
+	// Del lunes 01/07/2024 al viernes 12/07/2024; el 04/07 es feriado.
+	var series []PricePoint
+	price := 100.0
+	for _, d := range cal.TradingDays(mustDate(t, "2024-07-01"), mustDate(t, "2024-07-12")) {
+		series = append(series, PricePoint{Date: d, High: price + 1, Low: price - 1, Close: price, AdjClose: price})
+		price += 2
+	}
+
+	p := Prediction{
+		StockID:    "1",
+		Brokerage:  "Broker",
+		Ticker:     "AAA",
+		ReportedAt: time.Date(2024, 7, 1, 14, 30, 0, 0, time.UTC),
+		TargetTo:   105,
+	}
+
+	o, ok := Evaluate(cal, p, series, 5, financedomain.PriceBasisRaw)
+	assert.True(t, ok)
+	// 5 días hábiles después del 01/07 saltando el 4 de julio.
+	assert.Equal(t, mustDate(t, "2024-07-09"), o.HorizonDate)
+	assert.Equal(t, 100.0, o.BasePrice)
+	assert.Equal(t, 110.0, o.HorizonPrice)
+	assert.Equal(t, DirectionUp, o.Direction)
+	assert.True(t, o.IsCorrect)
+	assert.True(t, o.TargetHit)
+	assert.InDelta(t, (105.0-110.0)/110.0*100, o.SignedError, 1e-9)
+
+	// El horizonte a 30 días todavía no tiene precios.
+	_, ok = Evaluate(cal, p, series, 30, financedomain.PriceBasisRaw)
+	assert.False(t, ok)
+}
+
+func TestEvaluate_AdjustedBasisDividesTargetBySplits(t *testing.T) {
+	cal := financedomain.NewNYSECalendar()
+	days := cal.TradingDays(mustDate(t, "2024-07-01"), mustDate(t, "2024-07-12"))
+
+	// Split 2:1 efectivo el 03/07: los cierres sin ajustar se parten a la
+	// mitad y la serie ajustada sigue continua.
+	var series []PricePoint
+	for i, d := range days {
+		adj := 100.0 + float64(i)
+		bar := PricePoint{Date: d, Close: adj, AdjClose: adj, High: adj + 1, Low: adj - 1}
+		if i < 2 {
+			bar.Close, bar.High, bar.Low = adj*2, adj*2+1, adj*2-1
+		}
+		if i == 2 {
+			bar.SplitRatio = 2
+		}
+		series = append(series, bar)
+	}
+
+	p := Prediction{StockID: "1", Brokerage: "Broker", Ticker: "AAA", ReportedAt: days[0], TargetTo: 210}
+
+	raw, ok := Evaluate(cal, p, series, 5, financedomain.PriceBasisRaw)
+	assert.True(t, ok)
+	// Sin ajustar, el split parece una caída.
+	assert.False(t, raw.IsCorrect)
+	assert.False(t, raw.TargetHit)
+
+	adj, ok := Evaluate(cal, p, series, 5, financedomain.PriceBasisAdjusted)
+	assert.True(t, ok)
+	assert.Equal(t, DirectionUp, adj.Direction)
+	assert.Equal(t, 100.0, adj.BasePrice)
+	assert.Equal(t, 105.0, adj.HorizonPrice)
+	assert.True(t, adj.IsCorrect)
+	assert.True(t, adj.TargetHit)
+	assert.Equal(t, 105.0, adj.TargetTo)
+	assert.InDelta(t, 0, adj.SignedError, 1e-9)
+}
+
+func TestEvaluate_NeutralUsesToleranceBand(t *testing.T) {
+	cal := financedomain.NewNYSECalendar()
+	days := cal.TradingDays(mustDate(t, "2024-07-01"), mustDate(t, "2024-07-12"))
+
+	series := make([]PricePoint, len(days))
+	for i, d := range days {
+		series[i] = PricePoint{Date: d, High: 101, Low: 99, Close: 100, AdjClose: 100}
+	}
+	series[5].Close, series[5].AdjClose = 101.5, 101.5
+
+	p := Prediction{StockID: "1", Brokerage: "Broker", Ticker: "AAA", ReportedAt: days[0], TargetTo: 101}
+
+	o, ok := Evaluate(cal, p, series, 5, financedomain.PriceBasisRaw)
+	assert.True(t, ok)
+	assert.Equal(t, DirectionNeutral, o.Direction)
+	assert.True(t, o.IsCorrect)
+
+	series[5].Close = 103
+	o, _ = Evaluate(cal, p, series, 5, financedomain.PriceBasisRaw)
+	assert.False(t, o.IsCorrect)
+}
+
+func TestAggregateOutcomes(t *testing.T) {
+	outcomes := []Outcome{
+		{Brokerage: "A", HorizonDays: 7, PriceBasis: "raw", IsCorrect: true, TargetHit: true, SignedError: 10},
+		{Brokerage: "A", HorizonDays: 7, PriceBasis: "raw", IsCorrect: false, SignedError: -4},
+		{Brokerage: "A", HorizonDays: 30, PriceBasis: "raw", IsCorrect: true, SignedError: 2},
+	}
+
+	stats := AggregateOutcomes(outcomes)
+	assert.Len(t, stats, 2)
+	assert.Equal(t, HorizonStats{
+		Brokerage:        "A",
+		HorizonDays:      7,
+		PriceBasis:       "raw",
+		TotalPredictions: 2,
+		TotalHits:        1,
+		Accuracy:         50,
+		TargetHits:       1,
+		TargetHitRate:    50,
+		MeanSignedError:  3,
+		MeanAbsError:     7,
+		WeightScore:      0.5,
+	}, stats[0])
+}
+
+func mustDate(t *testing.T, s string) time.Time {
+	t.Helper()
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
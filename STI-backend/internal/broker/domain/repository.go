@@ -0,0 +1,13 @@
+package domain
+
+type BrokerRepository interface {
+	ListPredictions() ([]Prediction, error)
+	GetPriceSeries(ticker string) ([]PricePoint, error)
+	// ReplaceOutcomes borra los resultados del ticker y de los ratings
+	// evaluados y guarda outcomes en su lugar.
+	ReplaceOutcomes(ticker string, predictions []Prediction, outcomes []Outcome) error
+	// PruneOutcomes borra los resultados de los tickers que ya no tienen
+	// ratings a evaluar.
+	PruneOutcomes(tickers []string) error
+	ReplaceHorizonStats(stats []HorizonStats) error
+}
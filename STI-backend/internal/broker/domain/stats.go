@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"math"
+	"sort"
+
+	financedomain "github.com/viteant/stockinsight/internal/finance/domain"
+)
+
+// HorizonStats agrega los resultados de un brokerage a un horizonte.
+// Accuracy y TargetHitRate están en porcentaje; WeightScore sigue la fórmula
+// de broker_evaluation (aciertos × accuracy / 100).
+type HorizonStats struct {
+	Brokerage        string                   `json:"brokerage"`
+	HorizonDays      int                      `json:"horizon_days"`
+	PriceBasis       financedomain.PriceBasis `json:"price_basis"`
+	TotalPredictions int                      `json:"total_predictions"`
+	TotalHits        int                      `json:"total_hits"`
+	Accuracy         float64                  `json:"accuracy"`
+	TargetHits       int                      `json:"target_hits"`
+	TargetHitRate    float64                  `json:"target_hit_rate"`
+	MeanSignedError  float64                  `json:"mean_signed_error"`
+	MeanAbsError     float64                  `json:"mean_abs_error"`
+	WeightScore      float64                  `json:"weight_score"`
+}
+
+type statsKey struct {
+	brokerage string
+	horizon   int
+	basis     financedomain.PriceBasis
+}
+
+func AggregateOutcomes(outcomes []Outcome) []HorizonStats {
+	type acc struct {
+		n, hits, targetHits int
+		signed, abs         float64
+	}
+	groups := make(map[statsKey]*acc)
+	for _, o := range outcomes {
+		k := statsKey{o.Brokerage, o.HorizonDays, o.PriceBasis}
+		a, ok := groups[k]
+		if !ok {
+			a = &acc{}
+			groups[k] = a
+		}
+		a.n++
+		if o.IsCorrect {
+			a.hits++
+		}
+		if o.TargetHit {
+			a.targetHits++
+		}
+		a.signed += o.SignedError
+		a.abs += math.Abs(o.SignedError)
+	}
+
+	stats := make([]HorizonStats, 0, len(groups))
+	for k, a := range groups {
+		n := float64(a.n)
+		accuracy := round2(100 * float64(a.hits) / n)
+		stats = append(stats, HorizonStats{
+			Brokerage:        k.brokerage,
+			HorizonDays:      k.horizon,
+			PriceBasis:       k.basis,
+			TotalPredictions: a.n,
+			TotalHits:        a.hits,
+			Accuracy:         accuracy,
+			TargetHits:       a.targetHits,
+			TargetHitRate:    round2(100 * float64(a.targetHits) / n),
+			MeanSignedError:  round2(a.signed / n),
+			MeanAbsError:     round2(a.abs / n),
+			WeightScore:      round2(float64(a.hits) * accuracy / 100),
+		})
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Brokerage != stats[j].Brokerage {
+			return stats[i].Brokerage < stats[j].Brokerage
+		}
+		if stats[i].HorizonDays != stats[j].HorizonDays {
+			return stats[i].HorizonDays < stats[j].HorizonDays
+		}
+		return stats[i].PriceBasis < stats[j].PriceBasis
+	})
+	return stats
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
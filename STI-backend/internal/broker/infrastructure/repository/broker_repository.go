@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/viteant/stockinsight/internal/broker/domain"
+	"github.com/viteant/stockinsight/internal/db"
+	financedomain "github.com/viteant/stockinsight/internal/finance/domain"
+)
+
+const (
+	outcomeBatchSize = 500
+	maxTxRetries     = 5
+)
+
+type PersistenceBrokerRepository struct {
+	DB *sql.DB
+}
+
+func NewCockroachBrokerRepository(db *sql.DB) *PersistenceBrokerRepository {
+	return &PersistenceBrokerRepository{DB: db}
+}
+
+// ListPredictions devuelve los ratings con target ordenados por ticker y fecha.
+func (r *PersistenceBrokerRepository) ListPredictions() ([]domain.Prediction, error) {
+	rows, err := r.DB.Query(`
+		SELECT id, brokerage, ticker, created_at, target_to
+		FROM stocks
+		WHERE target_to IS NOT NULL AND target_to > 0
+		ORDER BY ticker, created_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var predictions []domain.Prediction
+	for rows.Next() {
+		var p domain.Prediction
+		if err := rows.Scan(&p.StockID, &p.Brokerage, &p.Ticker, &p.ReportedAt, &p.TargetTo); err != nil {
+			return nil, err
+		}
+		predictions = append(predictions, p)
+	}
+	return predictions, rows.Err()
+}
+
+func (r *PersistenceBrokerRepository) GetPriceSeries(ticker string) ([]domain.PricePoint, error) {
+	rows, err := r.DB.Query(`
+		SELECT f.date, COALESCE(f.high, f.close), COALESCE(f.low, f.close), f.close, COALESCE(f.adj_close, f.close),
+		       COALESCE(ca.numerator / NULLIF(ca.denominator, 0), 0)
+		FROM finances f
+		LEFT JOIN corporate_actions ca
+			ON ca.ticker = f.ticker AND ca.date = f.date AND ca.type = $2
+		WHERE f.ticker = $1 AND f.close IS NOT NULL
+		ORDER BY f.date
+	`, ticker, financedomain.ActionSplit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var series []domain.PricePoint
+	for rows.Next() {
+		var p domain.PricePoint
+		if err := rows.Scan(&p.Date, &p.High, &p.Low, &p.Close, &p.AdjClose, &p.SplitRatio); err != nil {
+			return nil, err
+		}
+		series = append(series, p)
+	}
+	return series, rows.Err()
+}
+
+// ReplaceOutcomes reemplaza en una transacción los resultados guardados del
+// ticker. También borra los de los ratings evaluados que estaban guardados
+// con otro ticker, por ejemplo si se corrigió el ticker del rating.
+func (r *PersistenceBrokerRepository) ReplaceOutcomes(ticker string, predictions []domain.Prediction, outcomes []domain.Outcome) error {
+	ids := make([]string, len(predictions))
+	for i, p := range predictions {
+		ids[i] = p.StockID
+	}
+
+	return db.RunInTx(r.DB, maxTxRetries, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`
+			DELETE FROM prediction_outcomes
+			WHERE ticker = $1 OR stock_id::STRING = ANY($2)
+		`, ticker, pq.Array(ids)); err != nil {
+			return err
+		}
+		for start := 0; start < len(outcomes); start += outcomeBatchSize {
+			end := min(start+outcomeBatchSize, len(outcomes))
+			if err := insertOutcomes(tx, outcomes[start:end]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// PruneOutcomes borra los resultados de tickers fuera de la lista, que ya no
+// tienen ratings con target.
+func (r *PersistenceBrokerRepository) PruneOutcomes(tickers []string) error {
+	res, err := r.DB.Exec(`
+		DELETE FROM prediction_outcomes WHERE ticker != ALL($1)
+	`, pq.Array(tickers))
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		log.Printf("%d resultados de ratings que ya no se evalúan fueron eliminados", n)
+	}
+	return nil
+}
+
+func insertOutcomes(tx *sql.Tx, outcomes []domain.Outcome) error {
+	const columns = 15
+
+	values := make([]string, 0, len(outcomes))
+	args := make([]interface{}, 0, len(outcomes)*columns)
+	for i, o := range outcomes {
+		placeholders := make([]string, columns)
+		for c := range placeholders {
+			placeholders[c] = fmt.Sprintf("$%d", i*columns+c+1)
+		}
+		values = append(values, "("+strings.Join(placeholders, ", ")+")")
+		args = append(args,
+			o.StockID,
+			o.HorizonDays,
+			string(o.PriceBasis),
+			o.Brokerage,
+			o.Ticker,
+			o.PredictionDate,
+			o.BaseDate,
+			o.BasePrice,
+			o.HorizonDate,
+			o.HorizonPrice,
+			o.TargetTo,
+			o.Direction,
+			o.IsCorrect,
+			o.TargetHit,
+			o.SignedError,
+		)
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO prediction_outcomes (
+			stock_id, horizon_days, price_basis, brokerage, ticker,
+			prediction_date, base_date, base_price, horizon_date, horizon_price,
+			target_to, direction, is_correct, target_hit, signed_error
+		) VALUES %s
+	`, strings.Join(values, ",\n"))
+
+	_, err := tx.Exec(query, args...)
+	return err
+}
+
+// ReplaceHorizonStats reemplaza por completo las estadísticas por horizonte.
+func (r *PersistenceBrokerRepository) ReplaceHorizonStats(stats []domain.HorizonStats) error {
+	return db.RunInTx(r.DB, maxTxRetries, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM broker_horizon_stats WHERE true`); err != nil {
+			return err
+		}
+		for _, s := range stats {
+			if _, err := tx.Exec(`
+				INSERT INTO broker_horizon_stats (
+					brokerage, horizon_days, price_basis,
+					total_predictions, total_hits, accuracy,
+					target_hits, target_hit_rate,
+					mean_signed_error, mean_abs_error, weight_score
+				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			`,
+				s.Brokerage,
+				s.HorizonDays,
+				string(s.PriceBasis),
+				s.TotalPredictions,
+				s.TotalHits,
+				s.Accuracy,
+				s.TargetHits,
+				s.TargetHitRate,
+				s.MeanSignedError,
+				s.MeanAbsError,
+				s.WeightScore,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
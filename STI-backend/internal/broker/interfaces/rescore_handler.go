@@ -0,0 +1,50 @@
+package interfaces
+
+import (
+	"context"
+	"log"
+
+	"github.com/viteant/stockinsight/internal/broker/infrastructure/repository"
+	"github.com/viteant/stockinsight/internal/broker/use_cases"
+	"github.com/viteant/stockinsight/internal/db"
+	syncrundomain "github.com/viteant/stockinsight/internal/syncrun/domain"
+	syncrunrepository "github.com/viteant/stockinsight/internal/syncrun/infrastructure/repository"
+	syncrunusecases "github.com/viteant/stockinsight/internal/syncrun/use_cases"
+)
+
+// RescoreSource identifica las corridas de recalificación en sync_runs.
+const RescoreSource = "prediction_outcomes"
+
+// RunRescore recalcula la precisión de los brokers por horizonte y registra
+// la corrida en sync_runs. onRun recibe el ID de la corrida apenas se
+// registra; onRun y onTicker pueden ser nil.
+func RunRescore(ctx context.Context, onRun func(runID string), onTicker func(done, total int)) error {
+	dbConn := db.NewCockroachDB()
+	defer dbConn.Close()
+
+	service := use_cases.NewRescoreService(repository.NewCockroachBrokerRepository(dbConn))
+	service.OnTicker = onTicker
+	runs := syncrunrepository.NewCockroachRunRepository(dbConn)
+
+	run, stopHeartbeat, err := syncrunusecases.NewTracker(runs).Start(syncrundomain.KindRescore, RescoreSource)
+	if err != nil {
+		return err
+	}
+	if onRun != nil {
+		onRun(run.ID)
+	}
+
+	summary, execErr := service.Execute(ctx)
+	stopHeartbeat()
+
+	run.PagesFetched = summary.Tickers
+	run.RowsInserted = summary.Outcomes
+	run.RowsFailed = summary.Failed
+	run.Complete(execErr)
+
+	if err := runs.Finish(run); err != nil {
+		log.Printf("Error registrando la corrida %s: %v", run.ID, err)
+	}
+
+	return execErr
+}
@@ -0,0 +1,116 @@
+package use_cases
+
+import (
+	"context"
+	"log"
+
+	"github.com/viteant/stockinsight/internal/broker/domain"
+	financedomain "github.com/viteant/stockinsight/internal/finance/domain"
+)
+
+// RescoreSummary resume una recalificación de brokers.
+type RescoreSummary struct {
+	Tickers     int
+	Predictions int
+	Outcomes    int
+	Stats       int
+	Failed      int
+}
+
+type RescoreService struct {
+	Repo     domain.BrokerRepository
+	Calendar *financedomain.TradingCalendar
+	Horizons []int
+
+	// OnTicker, si está definido, se llama después de evaluar cada ticker.
+	OnTicker func(done, total int)
+}
+
+func NewRescoreService(repo domain.BrokerRepository) *RescoreService {
+	return &RescoreService{
+		Repo:     repo,
+		Calendar: financedomain.NewNYSECalendar(),
+		Horizons: domain.Horizons,
+	}
+}
+
+// Execute recalcula los resultados de todos los ratings a cada horizonte y
+// base de precio, borra los de tickers que ya no tienen ratings y luego
+// recalcula las estadísticas por brokerage. Un ticker que falla no detiene a
+// los demás.
+func (s *RescoreService) Execute(ctx context.Context) (RescoreSummary, error) {
+	var summary RescoreSummary
+
+	predictions, err := s.Repo.ListPredictions()
+	if err != nil {
+		return summary, err
+	}
+	summary.Predictions = len(predictions)
+
+	byTicker := make(map[string][]domain.Prediction)
+	var tickers []string
+	for _, p := range predictions {
+		if _, ok := byTicker[p.Ticker]; !ok {
+			tickers = append(tickers, p.Ticker)
+		}
+		byTicker[p.Ticker] = append(byTicker[p.Ticker], p)
+	}
+
+	var all []domain.Outcome
+	for i, ticker := range tickers {
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+
+		outcomes, err := s.scoreTicker(ticker, byTicker[ticker])
+		if err != nil {
+			log.Printf("Error evaluando ratings de %s: %v", ticker, err)
+			summary.Failed++
+		} else {
+			summary.Tickers++
+			summary.Outcomes += len(outcomes)
+			all = append(all, outcomes...)
+		}
+
+		if s.OnTicker != nil {
+			s.OnTicker(i+1, len(tickers))
+		}
+	}
+
+	if err := s.Repo.PruneOutcomes(tickers); err != nil {
+		return summary, err
+	}
+
+	stats := domain.AggregateOutcomes(all)
+	if err := s.Repo.ReplaceHorizonStats(stats); err != nil {
+		return summary, err
+	}
+	summary.Stats = len(stats)
+
+	log.Printf("Recalificación completada: %d tickers, %d ratings, %d resultados, %d estadísticas, %d tickers fallidos",
+		summary.Tickers, summary.Predictions, summary.Outcomes, summary.Stats, summary.Failed)
+	return summary, nil
+}
+
+func (s *RescoreService) scoreTicker(ticker string, predictions []domain.Prediction) ([]domain.Outcome, error) {
+	series, err := s.Repo.GetPriceSeries(ticker)
+	if err != nil {
+		return nil, err
+	}
+
+	var outcomes []domain.Outcome
+	for _, p := range predictions {
+		for _, h := range s.Horizons {
+			for _, basis := range []financedomain.PriceBasis{financedomain.PriceBasisRaw, financedomain.PriceBasisAdjusted} {
+				if o, ok := domain.Evaluate(s.Calendar, p, series, h, basis); ok {
+					outcomes = append(outcomes, o)
+				}
+			}
+		}
+	}
+
+	if err := s.Repo.ReplaceOutcomes(ticker, predictions, outcomes); err != nil {
+		return nil, err
+	}
+	return outcomes, nil
+}
@@ -0,0 +1,265 @@
+package cassette
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Mode define cómo se usa el cassette.
+type Mode string
+
+const (
+	// ModeReplay solo responde con lo grabado; nunca sale a la red.
+	ModeReplay Mode = "replay"
+	// ModeRecord responde con lo grabado y graba lo que falte.
+	ModeRecord Mode = "record"
+	// ModeRefresh sale siempre a la red y sobrescribe lo grabado.
+	ModeRefresh Mode = "refresh"
+)
+
+// ErrNotRecorded indica que en modo replay no hay una respuesta grabada para
+// el pedido.
+var ErrNotRecorded = errors.New("pedido sin grabar en el cassette")
+
+var slugPattern = regexp.MustCompile(`[^a-zA-Z0-9.-]+`)
+
+// Interaction es un intercambio HTTP grabado.
+type Interaction struct {
+	Request    RecordedRequest  `json:"request"`
+	Response   RecordedResponse `json:"response"`
+	RecordedAt time.Time        `json:"recorded_at"`
+}
+
+// RecordedRequest no guarda los headers del pedido para no dejar tokens en
+// los cassettes.
+type RecordedRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+}
+
+type RecordedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body"`
+}
+
+// Transport graba y reproduce intercambios HTTP en Dir, un archivo JSON por
+// pedido. Dos pedidos con el mismo método, URL (sin importar el orden de los
+// parámetros) y cuerpo comparten archivo.
+type Transport struct {
+	Dir  string
+	Mode Mode
+	Next http.RoundTripper
+}
+
+func New(dir string, mode Mode, next http.RoundTripper) *Transport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Transport{Dir: dir, Mode: mode, Next: next}
+}
+
+// Config indica dónde y en qué modo se usan los cassettes. Con Dir vacío los
+// clientes salen a la red sin grabar.
+type Config struct {
+	Dir  string
+	Mode Mode
+}
+
+// ConfigFromEnv lee HTTP_CASSETTE_DIR y HTTP_CASSETTE_MODE (replay, record o
+// refresh; por defecto: replay).
+func ConfigFromEnv() (Config, error) {
+	mode, err := ParseMode(os.Getenv("HTTP_CASSETTE_MODE"))
+	if err != nil {
+		return Config{}, err
+	}
+	return Config{Dir: os.Getenv("HTTP_CASSETTE_DIR"), Mode: mode}, nil
+}
+
+// Wrap envuelve next con un cassette en Dir/name; sin Dir devuelve next sin
+// cambios.
+func (c Config) Wrap(name string, next http.RoundTripper) http.RoundTripper {
+	if c.Dir == "" {
+		return next
+	}
+	mode := c.Mode
+	if mode == "" {
+		mode = ModeReplay
+	}
+	log.Printf("📼 Cassette %s en modo %s (%s)", name, mode, filepath.Join(c.Dir, name))
+	return New(filepath.Join(c.Dir, name), mode, next)
+}
+
+func ParseMode(s string) (Mode, error) {
+	switch Mode(strings.ToLower(strings.TrimSpace(s))) {
+	case "", ModeReplay:
+		return ModeReplay, nil
+	case ModeRecord:
+		return ModeRecord, nil
+	case ModeRefresh:
+		return ModeRefresh, nil
+	}
+	return "", fmt.Errorf("modo de cassette desconocido: %q", s)
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(t.Dir, fileName(req, reqBody))
+
+	if t.Mode != ModeRefresh {
+		in, err := load(path)
+		if err == nil {
+			return in.Response.toHTTP(req), nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if t.Mode == ModeReplay {
+			return nil, fmt.Errorf("%w: %s %s", ErrNotRecorded, req.Method, req.URL.Redacted())
+		}
+	}
+
+	resp, err := t.Next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	// Los 429 y 5xx son pasajeros; grabarlos fijaría una falla en el cassette.
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return resp, nil
+	}
+
+	in := Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    req.URL.Redacted(),
+			Body:   string(reqBody),
+		},
+		Response: RecordedResponse{
+			Status: resp.StatusCode,
+			Header: recordedHeader(resp.Header),
+			Body:   string(body),
+		},
+		RecordedAt: time.Now().UTC(),
+	}
+	if err := save(path, in); err != nil {
+		return nil, fmt.Errorf("error grabando el cassette %s: %w", path, err)
+	}
+	return resp, nil
+}
+
+func (r RecordedResponse) toHTTP(req *http.Request) *http.Response {
+	header := r.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status)),
+		StatusCode:    r.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
+
+// fileName combina una parte legible (método, host y ruta) con el hash del
+// pedido canónico.
+func fileName(req *http.Request, body []byte) string {
+	u := *req.URL
+	u.RawQuery = u.Query().Encode()
+	u.Fragment = ""
+
+	sum := sha256.Sum256([]byte(req.Method + " " + u.String() + "\n" + string(body)))
+
+	slug := strings.Trim(slugPattern.ReplaceAllString(u.Host+u.Path, "_"), "_")
+	if len(slug) > 80 {
+		slug = slug[:80]
+	}
+	return fmt.Sprintf("%s_%s_%s.json", strings.ToLower(req.Method), slug, hex.EncodeToString(sum[:6]))
+}
+
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func recordedHeader(h http.Header) http.Header {
+	out := h.Clone()
+	for _, k := range []string{"Set-Cookie", "Date", "Content-Length"} {
+		out.Del(k)
+	}
+	return out
+}
+
+func load(path string) (Interaction, error) {
+	var in Interaction
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return in, err
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return in, fmt.Errorf("cassette %s inválido: %w", path, err)
+	}
+	return in, nil
+}
+
+// save escribe en un temporal y lo renombra para que un worker concurrente
+// nunca lea un archivo a medias.
+func save(path string, in Interaction) error {
+	data, err := json.MarshalIndent(in, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".cassette-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
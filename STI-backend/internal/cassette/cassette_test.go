@@ -0,0 +1,125 @@
+package cassette
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func get(t *testing.T, client *http.Client, url string) (int, string, error) {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	return resp.StatusCode, string(body), nil
+}
+
+func TestTransport_RecordThenReplayWithoutNetwork(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"page":"` + r.URL.Query().Get("next_page") + `"}`))
+	}))
+	dir := t.TempDir()
+
+	recorder := &http.Client{Transport: New(dir, ModeRecord, nil)}
+	_, body, err := get(t, recorder, server.URL+"/list?next_page=p2&a=1")
+	assert.NoError(t, err)
+	assert.Equal(t, `{"page":"p2"}`, body)
+
+	// Ya grabado: no vuelve a salir a la red.
+	_, _, err = get(t, recorder, server.URL+"/list?next_page=p2&a=1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+
+	server.Close()
+
+	replayer := &http.Client{Transport: New(dir, ModeReplay, nil)}
+	status, body, err := get(t, replayer, server.URL+"/list?a=1&next_page=p2")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, `{"page":"p2"}`, body)
+
+	_, _, err = get(t, replayer, server.URL+"/list?next_page=p3")
+	assert.True(t, errors.Is(err, ErrNotRecorded))
+}
+
+func TestTransport_RefreshOverwritesAndSkipsTransientErrors(t *testing.T) {
+	status := http.StatusOK
+	body := "v1"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+
+	_, _, err := get(t, &http.Client{Transport: New(dir, ModeRecord, nil)}, server.URL)
+	assert.NoError(t, err)
+
+	body = "v2"
+	refresher := &http.Client{Transport: New(dir, ModeRefresh, nil)}
+	_, got, err := get(t, refresher, server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "v2", got)
+
+	status, body = http.StatusTooManyRequests, "lento"
+	code, _, err := get(t, refresher, server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, code)
+
+	_, got, err = get(t, &http.Client{Transport: New(dir, ModeReplay, nil)}, server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "v2", got)
+
+	files, _ := os.ReadDir(dir)
+	assert.Len(t, files, 1)
+	assert.True(t, strings.HasPrefix(files[0].Name(), "get_127.0.0.1"))
+	assert.Equal(t, ".json", filepath.Ext(files[0].Name()))
+}
+
+func TestParseMode(t *testing.T) {
+	mode, err := ParseMode("")
+	assert.NoError(t, err)
+	assert.Equal(t, ModeReplay, mode)
+
+	mode, err = ParseMode(" Record ")
+	assert.NoError(t, err)
+	assert.Equal(t, ModeRecord, mode)
+
+	_, err = ParseMode("live")
+	assert.Error(t, err)
+}
+
+func TestConfig(t *testing.T) {
+	t.Setenv("HTTP_CASSETTE_DIR", "")
+	t.Setenv("HTTP_CASSETTE_MODE", "")
+	tape, err := ConfigFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, Config{Mode: ModeReplay}, tape)
+	// Sin directorio no se envuelve el transporte.
+	assert.Equal(t, http.DefaultTransport, tape.Wrap("api", http.DefaultTransport))
+
+	t.Setenv("HTTP_CASSETTE_MODE", "live")
+	_, err = ConfigFromEnv()
+	assert.Error(t, err)
+
+	dir := t.TempDir()
+	wrapped := Config{Dir: dir, Mode: ModeRefresh}.Wrap("api", nil)
+	if assert.IsType(t, &Transport{}, wrapped) {
+		assert.Equal(t, filepath.Join(dir, "api"), wrapped.(*Transport).Dir)
+		assert.Equal(t, ModeRefresh, wrapped.(*Transport).Mode)
+	}
+}
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS sync_state (
+    source STRING PRIMARY KEY,
+    last_reported_at TIMESTAMPTZ,
+    run_reported_at TIMESTAMPTZ,
+    next_page STRING NOT NULL DEFAULT '',
+    updated_at TIMESTAMPTZ DEFAULT now()
+);
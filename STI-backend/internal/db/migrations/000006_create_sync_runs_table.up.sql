@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS sync_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    kind STRING NOT NULL,
+    source STRING NOT NULL,
+    status STRING NOT NULL DEFAULT 'running',
+    started_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    finished_at TIMESTAMPTZ,
+    pages_fetched INT NOT NULL DEFAULT 0,
+    rows_inserted INT NOT NULL DEFAULT 0,
+    rows_updated INT NOT NULL DEFAULT 0,
+    rows_failed INT NOT NULL DEFAULT 0,
+    error STRING,
+    INDEX sync_runs_started_at_idx (started_at DESC)
+);
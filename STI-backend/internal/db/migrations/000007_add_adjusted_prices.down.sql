@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS corporate_actions;
+ALTER TABLE finances DROP COLUMN IF EXISTS adj_close;
@@ -0,0 +1,14 @@
+ALTER TABLE finances ADD COLUMN IF NOT EXISTS adj_close DECIMAL(12,4);
+
+CREATE TABLE IF NOT EXISTS corporate_actions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    ticker STRING NOT NULL,
+    date DATE NOT NULL,
+    type STRING NOT NULL,
+    amount DECIMAL(12,6),
+    numerator DECIMAL(12,4),
+    denominator DECIMAL(12,4),
+    source STRING,
+    scraped_at TIMESTAMPTZ DEFAULT now(),
+    UNIQUE (ticker, date, type)
+);
@@ -0,0 +1,95 @@
+-- Las vistas de evaluación se calculan para ambas bases de precio:
+-- 'raw' usa el cierre tal como cotizó y 'adjusted' lleva los targets, que
+-- están en precios del día del rating, a precios del día de la vela
+-- dividiéndolos por los splits que hubo entre ambas fechas (o
+-- multiplicándolos si la vela es anterior al rating). Un target en 0 es un
+-- rating sin target, igual que en ListPredictions.
+DROP VIEW IF EXISTS broker_evaluation;
+DROP VIEW IF EXISTS broker_predictions;
+
+CREATE VIEW broker_predictions AS
+SELECT
+    p.brokerage,
+    p.ticker,
+    p.prediction_date,
+    p.price_basis,
+    p.target_to,
+    p.target_from,
+    p.actual_price,
+
+    CASE
+        WHEN p.target_from IS NOT NULL AND p.target_to IS NOT NULL THEN
+            CASE
+                WHEN p.target_to > p.target_from THEN 'up'
+                WHEN p.target_to < p.target_from THEN 'down'
+                ELSE 'neutral'
+                END
+        ELSE NULL
+        END AS prediction_direction,
+
+    CASE
+        WHEN p.target_from IS NOT NULL AND p.target_to IS NOT NULL AND p.actual_price IS NOT NULL THEN
+            CASE
+                WHEN SIGN(p.target_to - p.target_from) = SIGN(p.actual_price - p.target_from) THEN 1
+                ELSE 0
+                END
+        ELSE NULL
+        END AS is_correct,
+
+    CASE
+        WHEN p.target_to IS NOT NULL AND p.actual_price IS NOT NULL AND p.actual_price != 0 THEN
+            ROUND(ABS(p.target_to - p.actual_price) / p.actual_price * 100, 2)
+        ELSE NULL
+        END AS error_percentage
+
+FROM (
+    SELECT
+        s.brokerage,
+        s.ticker,
+        s.created_at AS prediction_date,
+        pb.price_basis,
+        CASE WHEN pb.price_basis = 'adjusted' THEN s.target_to * sp.factor ELSE s.target_to END AS target_to,
+        CASE WHEN pb.price_basis = 'adjusted' THEN NULLIF(s.target_from, 0) * sp.factor ELSE NULLIF(s.target_from, 0) END AS target_from,
+        f.close AS actual_price
+    FROM stocks s
+             JOIN LATERAL (
+        SELECT date, close
+        FROM finances f
+        WHERE f.ticker = s.ticker
+        ORDER BY ABS(f.date - s.created_at::date) ASC
+        LIMIT 1
+        ) f ON true
+             JOIN LATERAL (
+        SELECT CASE
+                   WHEN f.date >= s.created_at::date THEN 1 / COALESCE(EXP(SUM(LN(ca.numerator / ca.denominator))), 1)
+                   ELSE COALESCE(EXP(SUM(LN(ca.numerator / ca.denominator))), 1)
+                   END AS factor
+        FROM corporate_actions ca
+        WHERE ca.ticker = s.ticker
+          AND ca.type = 'split'
+          AND ca.numerator > 0
+          AND ca.denominator > 0
+          AND ca.date > LEAST(s.created_at::date, f.date)
+          AND ca.date <= GREATEST(s.created_at::date, f.date)
+        ) sp ON true
+             CROSS JOIN (VALUES ('raw'), ('adjusted')) AS pb (price_basis)
+    WHERE s.target_to IS NOT NULL AND s.target_to > 0
+) p;
+
+
+CREATE VIEW broker_evaluation AS
+SELECT
+    brokerage,
+    price_basis,
+    COUNT(*) FILTER (WHERE is_correct IS NOT NULL) AS total_predictions,
+    SUM(is_correct) AS total_hits,
+    ROUND(100.0 * SUM(is_correct)::float / NULLIF(COUNT(*) FILTER (WHERE is_correct IS NOT NULL)::float, 0.0), 2) AS accuracy,
+    ROUND(SUM(is_correct)::float * (
+        100.0 * SUM(is_correct)::float / NULLIF(COUNT(*) FILTER (WHERE is_correct IS NOT NULL)::float, 0.0)
+    ) / 100.0, 2) AS weight_score
+FROM
+    broker_predictions
+GROUP BY
+    brokerage, price_basis
+ORDER BY
+    weight_score DESC;
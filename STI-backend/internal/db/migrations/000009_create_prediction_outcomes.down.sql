@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS broker_horizon_stats;
+DROP TABLE IF EXISTS prediction_outcomes;
@@ -0,0 +1,38 @@
+-- Resultado de cada rating a 7, 30 y 90 días hábiles, por base de precio.
+CREATE TABLE IF NOT EXISTS prediction_outcomes (
+    stock_id UUID NOT NULL,
+    horizon_days INT NOT NULL,
+    price_basis STRING NOT NULL,
+    brokerage STRING NOT NULL,
+    ticker STRING NOT NULL,
+    prediction_date TIMESTAMPTZ NOT NULL,
+    base_date DATE NOT NULL,
+    base_price DECIMAL(12,4) NOT NULL,
+    horizon_date DATE NOT NULL,
+    horizon_price DECIMAL(12,4) NOT NULL,
+    target_to DECIMAL(12,4) NOT NULL,
+    direction STRING NOT NULL,
+    is_correct BOOL NOT NULL,
+    target_hit BOOL NOT NULL,
+    signed_error DECIMAL(12,4) NOT NULL,
+    computed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (stock_id, horizon_days, price_basis),
+    INDEX (ticker),
+    INDEX (brokerage, horizon_days, price_basis)
+);
+
+CREATE TABLE IF NOT EXISTS broker_horizon_stats (
+    brokerage STRING NOT NULL,
+    horizon_days INT NOT NULL,
+    price_basis STRING NOT NULL,
+    total_predictions INT NOT NULL,
+    total_hits INT NOT NULL,
+    accuracy DECIMAL(6,2) NOT NULL,
+    target_hits INT NOT NULL,
+    target_hit_rate DECIMAL(6,2) NOT NULL,
+    mean_signed_error DECIMAL(12,2) NOT NULL,
+    mean_abs_error DECIMAL(12,2) NOT NULL,
+    weight_score DECIMAL(12,2) NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (brokerage, horizon_days, price_basis)
+);
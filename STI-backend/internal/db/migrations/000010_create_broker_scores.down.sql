@@ -0,0 +1 @@
+DROP TABLE IF EXISTS broker_scores;
@@ -0,0 +1,18 @@
+-- Precisión de cada brokerage encogida con un prior Beta-binomial.
+-- horizon_days = 0 corresponde a la vista broker_evaluation (cierre más cercano).
+CREATE TABLE IF NOT EXISTS broker_scores (
+    brokerage STRING NOT NULL,
+    horizon_days INT NOT NULL,
+    price_basis STRING NOT NULL,
+    total_predictions INT NOT NULL,
+    total_hits INT NOT NULL,
+    raw_accuracy DECIMAL(8,4) NOT NULL,
+    prior_alpha DECIMAL(12,4) NOT NULL,
+    prior_beta DECIMAL(12,4) NOT NULL,
+    score DECIMAL(8,4) NOT NULL,
+    ci_low DECIMAL(8,4) NOT NULL,
+    ci_high DECIMAL(8,4) NOT NULL,
+    method STRING NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (brokerage, horizon_days, price_basis)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE stocks DROP COLUMN IF EXISTS target_change_pct;
+ALTER TABLE stocks DROP COLUMN IF EXISTS action_type;
@@ -0,0 +1,5 @@
+-- action_type: upgrade, downgrade, initiation, reiteration, target_raised,
+-- target_lowered, target_set u other. Se completa al guardar cada rating y
+-- con --backfill-actions para las filas existentes.
+ALTER TABLE stocks ADD COLUMN IF NOT EXISTS action_type STRING;
+ALTER TABLE stocks ADD COLUMN IF NOT EXISTS target_change_pct DECIMAL(10,4);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS stocks@stocks_action_type_idx;
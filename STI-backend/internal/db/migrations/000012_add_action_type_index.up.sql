@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS stocks_action_type_idx ON stocks (action_type, created_at DESC);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS unmapped_ratings;
+ALTER TABLE stocks DROP COLUMN IF EXISTS rating_mapping_version;
+ALTER TABLE stocks DROP COLUMN IF EXISTS normalize_rating_to_5;
+ALTER TABLE stocks DROP COLUMN IF EXISTS normalize_rating_from_5;
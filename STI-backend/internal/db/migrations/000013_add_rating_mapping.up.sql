@@ -0,0 +1,15 @@
+-- Escala de 5 niveles y versión del mapeo con que se normalizó cada rating.
+ALTER TABLE stocks ADD COLUMN IF NOT EXISTS normalize_rating_from_5 STRING;
+ALTER TABLE stocks ADD COLUMN IF NOT EXISTS normalize_rating_to_5 STRING;
+ALTER TABLE stocks ADD COLUMN IF NOT EXISTS rating_mapping_version INT;
+
+-- Calificaciones del feed que no están en el mapeo (se guardan como hold),
+-- registradas en su forma canónica: minúsculas y sin espacios repetidos.
+CREATE TABLE IF NOT EXISTS unmapped_ratings (
+    raw_rating STRING PRIMARY KEY,
+    occurrences INT NOT NULL DEFAULT 0,
+    last_brokerage STRING,
+    mapping_version INT NOT NULL,
+    first_seen_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
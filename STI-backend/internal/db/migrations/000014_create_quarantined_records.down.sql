@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS quarantined_records;
+ALTER TABLE sync_runs DROP COLUMN IF EXISTS rows_quarantined;
+ALTER TABLE stocks DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,20 @@
+-- currency: moneda de target_from y target_to (ISO 4217).
+ALTER TABLE stocks ADD COLUMN IF NOT EXISTS currency STRING;
+
+ALTER TABLE sync_runs ADD COLUMN IF NOT EXISTS rows_quarantined INT NOT NULL DEFAULT 0;
+
+-- Registros de ingesta que no pasaron la validación. payload es el registro
+-- original; errors, la lista de campos inválidos con su motivo.
+CREATE TABLE IF NOT EXISTS quarantined_records (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    kind STRING NOT NULL,
+    source STRING NOT NULL,
+    fingerprint STRING NOT NULL,
+    payload JSONB NOT NULL,
+    reason STRING NOT NULL,
+    errors JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (kind, fingerprint),
+    INDEX quarantined_records_created_at_idx (created_at DESC)
+);
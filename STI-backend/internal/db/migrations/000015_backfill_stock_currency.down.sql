@@ -0,0 +1 @@
+UPDATE stocks SET currency = NULL WHERE currency = 'USD';
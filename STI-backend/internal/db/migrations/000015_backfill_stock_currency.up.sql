@@ -0,0 +1,3 @@
+-- Hasta ahora todos los targets se leían quitando "$".
+UPDATE stocks SET currency = 'USD'
+WHERE currency IS NULL AND (target_from > 0 OR target_to > 0);
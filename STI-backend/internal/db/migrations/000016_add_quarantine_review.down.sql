@@ -0,0 +1,4 @@
+ALTER TABLE quarantined_records DROP COLUMN IF EXISTS resolved_at;
+ALTER TABLE quarantined_records DROP COLUMN IF EXISTS last_error;
+ALTER TABLE quarantined_records DROP COLUMN IF EXISTS attempts;
+ALTER TABLE quarantined_records DROP COLUMN IF EXISTS status;
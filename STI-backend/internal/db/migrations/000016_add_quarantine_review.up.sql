@@ -0,0 +1,5 @@
+-- status: pending, resolved (reinyectado con éxito) o discarded.
+ALTER TABLE quarantined_records ADD COLUMN IF NOT EXISTS status STRING NOT NULL DEFAULT 'pending';
+ALTER TABLE quarantined_records ADD COLUMN IF NOT EXISTS attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE quarantined_records ADD COLUMN IF NOT EXISTS last_error STRING;
+ALTER TABLE quarantined_records ADD COLUMN IF NOT EXISTS resolved_at TIMESTAMPTZ;
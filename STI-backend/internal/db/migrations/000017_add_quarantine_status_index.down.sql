@@ -0,0 +1 @@
+DROP INDEX IF EXISTS quarantined_records@quarantined_records_status_idx;
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS quarantined_records_status_idx ON quarantined_records (status, kind, created_at DESC);
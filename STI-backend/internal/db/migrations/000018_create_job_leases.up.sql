@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS job_leases (
+    name STRING PRIMARY KEY,
+    holder STRING NOT NULL,
+    scheduled_at TIMESTAMPTZ NOT NULL,
+    acquired_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name STRING NOT NULL,
+    prefix STRING NOT NULL,
+    key_hash STRING NOT NULL UNIQUE,
+    scopes STRING[] NOT NULL,
+    rate_per_sec FLOAT8 NOT NULL,
+    burst INT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
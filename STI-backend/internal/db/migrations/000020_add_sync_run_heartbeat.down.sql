@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS sync_runs@sync_runs_status_heartbeat_idx;
+
+ALTER TABLE sync_runs DROP COLUMN IF EXISTS heartbeat_at;
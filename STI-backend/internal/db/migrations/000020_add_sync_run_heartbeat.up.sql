@@ -0,0 +1,3 @@
+ALTER TABLE sync_runs ADD COLUMN IF NOT EXISTS heartbeat_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+CREATE INDEX IF NOT EXISTS sync_runs_status_heartbeat_idx ON sync_runs (status, heartbeat_at);
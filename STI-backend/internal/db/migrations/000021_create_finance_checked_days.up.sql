@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS finance_checked_days (
+    ticker STRING NOT NULL,
+    date DATE NOT NULL,
+    source STRING NOT NULL,
+    checked_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (ticker, date)
+);
@@ -0,0 +1 @@
+UPDATE finances SET source = 'Yahoo' WHERE source = 'yahoo';
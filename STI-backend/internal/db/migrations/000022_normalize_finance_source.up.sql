@@ -0,0 +1,3 @@
+-- Yahoo guardaba su nombre con mayúscula; los proveedores ahora se
+-- identifican por su nombre en FINANCE_PROVIDERS.
+UPDATE finances SET source = 'yahoo' WHERE source = 'Yahoo';
@@ -11,7 +11,7 @@ import (
 
 func ExportFinanceDataToJSON(db *sql.DB, filepath string) error {
 	rows, err := db.Query(`
-        SELECT ticker, date, open, high, low, close, volume, source, scraped_at
+        SELECT ticker, date, open, high, low, close, adj_close, volume, source, scraped_at
         FROM finances
     `)
 	if err != nil {
@@ -22,6 +22,7 @@ func ExportFinanceDataToJSON(db *sql.DB, filepath string) error {
 	var entries []domain.Finance
 	for rows.Next() {
 		var f domain.Finance
+		var adjClose sql.NullFloat64
 		if err := rows.Scan(
 			&f.Ticker,
 			&f.Date,
@@ -29,6 +30,7 @@ func ExportFinanceDataToJSON(db *sql.DB, filepath string) error {
 			&f.High,
 			&f.Low,
 			&f.Close,
+			&adjClose,
 			&f.Volume,
 			&f.Source,
 			&f.ScrapedAt,
@@ -36,6 +38,7 @@ func ExportFinanceDataToJSON(db *sql.DB, filepath string) error {
 			log.Printf("❌ Error escaneando fila finance: %v", err)
 			continue
 		}
+		f.AdjClose = float32(adjClose.Float64)
 		entries = append(entries, f)
 	}
 
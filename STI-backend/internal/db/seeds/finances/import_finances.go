@@ -8,8 +8,12 @@ import (
 	"os"
 
 	"github.com/viteant/stockinsight/internal/finance/domain"
+	"github.com/viteant/stockinsight/internal/finance/infrastructure/repository"
 )
 
+// ImportSource identifica las importaciones en la cuarentena.
+const ImportSource = "import"
+
 func ImportFinanceDataFromJSON(db *sql.DB, filepath string) error {
 	data, err := os.ReadFile(filepath)
 	if err != nil {
@@ -21,40 +25,37 @@ func ImportFinanceDataFromJSON(db *sql.DB, filepath string) error {
 		return fmt.Errorf("no se pudo parsear el JSON: %w", err)
 	}
 
-	stmt := `
-        INSERT INTO finances (
-            id, ticker, date, open, high, low, close, volume, source, scraped_at
-        ) VALUES (
-            gen_random_uuid(), $1, $2, $3, $4, $5, $6, $7, $8, $9
-        )
-        ON CONFLICT (ticker, date) DO UPDATE SET
-            open = excluded.open,
-            high = excluded.high,
-            low = excluded.low,
-            close = excluded.close,
-            volume = excluded.volume,
-            source = excluded.source,
-            scraped_at = excluded.scraped_at
-    `
-
-	for _, f := range entries {
-		_, err := db.Exec(stmt,
-			f.Ticker,
-			f.Date,
-			f.Open,
-			f.High,
-			f.Low,
-			f.Close,
-			f.Volume,
-			f.Source,
-			f.ScrapedAt,
-		)
-		if err != nil {
-			log.Printf("Error insertando FinanceData %s [%s]: %v",
-				f.Ticker, f.Date.Format("2006-01-02"), err)
+	repo := repository.NewCockroachFinanceRepository(db)
+	valid, rejected := domain.SplitValid(entries)
+
+	result, saveErr := repo.BulkSave(valid)
+	if saveErr != nil {
+		// La transacción se deshizo: las velas válidas van a cuarentena.
+		log.Printf("Error insertando FinanceData: %v", saveErr)
+		result = domain.SaveResult{Failed: len(valid)}
+		for _, f := range valid {
+			result.Rejected = append(result.Rejected, domain.RejectedFinance{Finance: f, Err: saveErr})
+		}
+	}
+	rejected = append(rejected, result.Rejected...)
+
+	var quarantineErr error
+	if len(rejected) > 0 {
+		if quarantineErr = repo.QuarantineFinances(ImportSource, rejected); quarantineErr != nil {
+			log.Printf("Error enviando %d registros a cuarentena: %v", len(rejected), quarantineErr)
+		} else {
+			log.Printf("%d registros enviados a cuarentena", len(rejected))
 		}
 	}
 
-	log.Printf("Importación completa FinanceData: %d registros procesados\n", len(entries))
+	log.Printf("Importación completa FinanceData: %d registros procesados (%d insertados, %d actualizados, %d fallidos)\n",
+		len(entries), result.Inserted, result.Updated, result.Failed)
+
+	if quarantineErr != nil {
+		return fmt.Errorf("%d registros no se guardaron ni quedaron en cuarentena: %w", len(rejected), quarantineErr)
+	}
+	if result.Failed > 0 {
+		return fmt.Errorf("%d de %d registros no se guardaron: %w", result.Failed, len(entries), result.Rejected[0].Err)
+	}
 	return nil
 }
@@ -3,13 +3,17 @@ package stocks
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
-	"time"
 
 	"github.com/viteant/stockinsight/internal/stock/domain"
+	"github.com/viteant/stockinsight/internal/stock/infrastructure/repository"
 )
 
+// ImportSource identifica las importaciones en la cuarentena.
+const ImportSource = "import"
+
 func ImportStocksFromJSON(db *sql.DB, filepath string) error {
 	data, err := os.ReadFile(filepath)
 	if err != nil {
@@ -21,49 +25,28 @@ func ImportStocksFromJSON(db *sql.DB, filepath string) error {
 		return fmt.Errorf("no se pudo parsear el JSON: %w", err)
 	}
 
-	stmt := `
-		INSERT INTO stocks (
-			id, ticker, company, brokerage, action,
-			rating_from, rating_to,
-			normalize_rating_from, normalize_rating_to,
-			target_from, target_to, created_at
-		) VALUES (
-			gen_random_uuid(), $1, $2, $3, $4,
-			$5, $6,
-			$7, $8,
-			$9, $10, $11
-		)
-		ON CONFLICT (ticker, created_at) DO UPDATE SET
-			company = excluded.company,
-			brokerage = excluded.brokerage,
-			action = excluded.action,
-			rating_from = excluded.rating_from,
-			rating_to = excluded.rating_to,
-			normalize_rating_from = excluded.normalize_rating_from,
-			normalize_rating_to = excluded.normalize_rating_to,
-			target_from = excluded.target_from,
-			target_to = excluded.target_to
-	`
-
-	for _, s := range stocks {
-		_, err := db.Exec(stmt,
-			s.Ticker,
-			s.Company,
-			s.Brokerage,
-			s.Action,
-			s.RatingFrom,
-			s.RatingTo,
-			s.NormalizeRatingFrom,
-			s.NormalizeRatingTo,
-			s.TargetFrom,
-			s.TargetTo,
-			s.ReportedAt,
-		)
-		if err != nil {
-			fmt.Printf("Error insertando stock %s (%s): %v\n", s.Ticker, s.ReportedAt.Format(time.RFC3339), err)
+	repo, err := repository.NewCockroachStockRepository(db)
+	if err != nil {
+		return err
+	}
+	result, saveErr := repo.SaveBatch(stocks)
+	if saveErr != nil {
+		fmt.Printf("Error insertando stocks: %v\n", saveErr)
+	}
+	var quarantineErr error
+	if len(result.Rejected) > 0 {
+		if quarantineErr = repo.QuarantineStocks(ImportSource, result.Rejected); quarantineErr != nil {
+			fmt.Printf("Error enviando %d stocks a cuarentena: %v\n", len(result.Rejected), quarantineErr)
+		} else {
+			fmt.Printf("%d stocks enviados a cuarentena\n", len(result.Rejected))
 		}
 	}
 
-	fmt.Printf("Importación completa: %d registros procesados\n", len(stocks))
+	fmt.Printf("Importación completa: %d registros procesados (%d insertados, %d actualizados, %d fallidos)\n",
+		len(stocks), result.Inserted, result.Updated, result.Failed)
+
+	if saveErr != nil || quarantineErr != nil {
+		return fmt.Errorf("%d de %d stocks no se guardaron: %w", result.Failed, len(stocks), errors.Join(saveErr, quarantineErr))
+	}
 	return nil
 }
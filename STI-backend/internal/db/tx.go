@@ -0,0 +1,58 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// sleep espera entre reintentos; los tests la reemplazan.
+var sleep = time.Sleep
+
+// RunInTx ejecuta fn dentro de una transacción y la reintenta hasta maxRetries
+// veces cuando CockroachDB responde con un error de serialización (40001).
+func RunInTx(conn *sql.DB, maxRetries int, fn func(tx *sql.Tx) error) error {
+	return retry(maxRetries, func() error {
+		return runOnce(conn, fn)
+	})
+}
+
+// retry llama a attempt hasta que no devuelva un error reintentable, como
+// máximo maxRetries veces más, con un backoff lineal con jitter.
+func retry(maxRetries int, attempt func() error) error {
+	var err error
+	for i := 0; i <= maxRetries; i++ {
+		if i > 0 {
+			sleep(time.Duration(i*50+rand.Intn(50)) * time.Millisecond)
+		}
+
+		err = attempt()
+		if err == nil || !IsRetryable(err) {
+			return err
+		}
+	}
+	return err
+}
+
+func runOnce(conn *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// IsRetryable indica si el error es un conflicto de serialización que
+// CockroachDB espera que el cliente reintente.
+func IsRetryable(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "40001"
+}
@@ -0,0 +1,63 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRetryable(t *testing.T) {
+	serialization := &pq.Error{Code: "40001"}
+
+	assert.True(t, IsRetryable(serialization))
+	assert.True(t, IsRetryable(fmt.Errorf("guardando lote: %w", serialization)))
+	assert.False(t, IsRetryable(&pq.Error{Code: "23505"}))
+	assert.False(t, IsRetryable(errors.New("40001")))
+	assert.False(t, IsRetryable(nil))
+}
+
+func TestRetry(t *testing.T) {
+	var waits []time.Duration
+	sleep = func(d time.Duration) { waits = append(waits, d) }
+	t.Cleanup(func() { sleep = time.Sleep })
+
+	serialization := &pq.Error{Code: "40001"}
+	other := errors.New("violación de constraint")
+
+	cases := []struct {
+		name      string
+		errs      []error
+		retries   int
+		wantCalls int
+		wantErr   error
+	}{
+		{"sin error", nil, 3, 1, nil},
+		{"reintenta hasta lograrlo", []error{serialization, serialization}, 3, 3, nil},
+		{"agota los reintentos", []error{serialization, serialization, serialization, serialization}, 3, 4, serialization},
+		{"sin reintentos", []error{serialization}, 0, 1, serialization},
+		{"error no reintentable", []error{other}, 3, 1, other},
+	}
+	for _, c := range cases {
+		waits = nil
+		calls := 0
+		err := retry(c.retries, func() error {
+			calls++
+			if calls <= len(c.errs) {
+				return c.errs[calls-1]
+			}
+			return nil
+		})
+
+		assert.Equal(t, c.wantCalls, calls, c.name)
+		assert.Equal(t, c.wantErr, err, c.name)
+		assert.Len(t, waits, c.wantCalls-1, c.name)
+		for i, d := range waits {
+			assert.GreaterOrEqual(t, d, time.Duration((i+1)*50)*time.Millisecond, c.name)
+			assert.Less(t, d, time.Duration((i+2)*50)*time.Millisecond, c.name)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package domain
+
+import "time"
+
+// DateRange es un rango de fechas inclusivo.
+type DateRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// TradingCalendar determina los días hábiles de la bolsa de Nueva York:
+// fines de semana, feriados de NYSE y cierres extraordinarios. Los cierres
+// extraordinarios solo están cargados desde Since; antes de esa fecha el
+// calendario no es confiable.
+type TradingCalendar struct {
+	closures map[string]bool
+	since    time.Time
+}
+
+// nyseCalendarSince es la primera fecha que cubre el calendario de NYSE.
+var nyseCalendarSince = date(2000, time.January, 1)
+
+// Cierres no recurrentes de NYSE.
+var nyseSpecialClosures = []string{
+	"2001-09-11", "2001-09-12", "2001-09-13", "2001-09-14", // atentados del 11 de septiembre
+	"2004-06-11",               // duelo por Ronald Reagan
+	"2007-01-02",               // duelo por Gerald Ford
+	"2012-10-29", "2012-10-30", // huracán Sandy
+	"2018-12-05", // duelo por George H. W. Bush
+	"2025-01-09", // duelo por Jimmy Carter
+}
+
+func NewNYSECalendar() *TradingCalendar {
+	closures := make(map[string]bool, len(nyseSpecialClosures))
+	for _, d := range nyseSpecialClosures {
+		closures[d] = true
+	}
+	return &TradingCalendar{closures: closures, since: nyseCalendarSince}
+}
+
+// Since devuelve la primera fecha que cubre el calendario.
+func (c *TradingCalendar) Since() time.Time {
+	return c.since
+}
+
+// Day normaliza t a la medianoche UTC de su fecha.
+func Day(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func (c *TradingCalendar) IsTradingDay(t time.Time) bool {
+	d := Day(t)
+	switch d.Weekday() {
+	case time.Saturday, time.Sunday:
+		return false
+	}
+	if c.closures[d.Format("2006-01-02")] {
+		return false
+	}
+	return !isNYSEHoliday(d)
+}
+
+// TradingDays devuelve los días hábiles entre from y to, ambos inclusive.
+func (c *TradingCalendar) TradingDays(from, to time.Time) []time.Time {
+	var days []time.Time
+	for d := Day(from); !d.After(Day(to)); d = d.AddDate(0, 0, 1) {
+		if c.IsTradingDay(d) {
+			days = append(days, d)
+		}
+	}
+	return days
+}
+
+// AddTradingDays avanza n días hábiles desde t.
+func (c *TradingCalendar) AddTradingDays(t time.Time, n int) time.Time {
+	d := Day(t)
+	for n > 0 {
+		d = d.AddDate(0, 0, 1)
+		if c.IsTradingDay(d) {
+			n--
+		}
+	}
+	return d
+}
+
+// MissingRanges agrupa en rangos consecutivos los días de expected que no
+// están en existing (claves con formato YYYY-MM-DD).
+func MissingRanges(expected []time.Time, existing map[string]bool) []DateRange {
+	var ranges []DateRange
+	open := false
+
+	for _, d := range expected {
+		if existing[d.Format("2006-01-02")] {
+			open = false
+			continue
+		}
+		if open {
+			ranges[len(ranges)-1].To = d
+			continue
+		}
+		ranges = append(ranges, DateRange{From: d, To: d})
+		open = true
+	}
+
+	return ranges
+}
+
+func isNYSEHoliday(d time.Time) bool {
+	year := d.Year()
+	holidays := []time.Time{
+		newYearsDay(year),
+		nthWeekday(year, time.February, time.Monday, 3), // Presidents' Day
+		easterSunday(year).AddDate(0, 0, -2),            // Good Friday
+		lastWeekday(year, time.May, time.Monday),        // Memorial Day
+		observed(date(year, time.July, 4)),
+		nthWeekday(year, time.September, time.Monday, 1),  // Labor Day
+		nthWeekday(year, time.November, time.Thursday, 4), // Thanksgiving
+		observed(date(year, time.December, 25)),
+	}
+	// NYSE cierra por Martin Luther King Jr. desde 1998 y por Juneteenth
+	// desde 2022.
+	if year >= 1998 {
+		holidays = append(holidays, nthWeekday(year, time.January, time.Monday, 3))
+	}
+	if year >= 2022 {
+		holidays = append(holidays, observed(date(year, time.June, 19)))
+	}
+
+	for _, h := range holidays {
+		if h.Equal(d) {
+			return true
+		}
+	}
+	return false
+}
+
+// newYearsDay aplica la regla de NYSE: si el 1 de enero cae en sábado no se
+// compensa el viernes anterior.
+func newYearsDay(year int) time.Time {
+	d := date(year, time.January, 1)
+	if d.Weekday() == time.Sunday {
+		return d.AddDate(0, 0, 1)
+	}
+	return d
+}
+
+func observed(d time.Time) time.Time {
+	switch d.Weekday() {
+	case time.Saturday:
+		return d.AddDate(0, 0, -1)
+	case time.Sunday:
+		return d.AddDate(0, 0, 1)
+	}
+	return d
+}
+
+func nthWeekday(year int, month time.Month, weekday time.Weekday, n int) time.Time {
+	d := date(year, month, 1)
+	offset := (int(weekday) - int(d.Weekday()) + 7) % 7
+	return d.AddDate(0, 0, offset+7*(n-1))
+}
+
+func lastWeekday(year int, month time.Month, weekday time.Weekday) time.Time {
+	d := date(year, month+1, 1).AddDate(0, 0, -1)
+	offset := (int(d.Weekday()) - int(weekday) + 7) % 7
+	return d.AddDate(0, 0, -offset)
+}
+
+// easterSunday usa el algoritmo anónimo gregoriano (Meeus/Jones/Butcher).
+func easterSunday(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return date(year, time.Month(month), day)
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNYSECalendar_Holidays(t *testing.T) {
+	cal := NewNYSECalendar()
+
+	closed := []string{
+		"2024-01-01", // Año nuevo
+		"2024-01-15", // Martin Luther King Jr.
+		"2024-03-29", // Viernes santo
+		"2024-05-27", // Memorial Day
+		"2024-06-19", // Juneteenth
+		"2024-07-04",
+		"2024-11-28", // Thanksgiving
+		"2024-12-25",
+		"2023-01-02", // Año nuevo cayó en domingo
+		"2021-12-24", // Navidad cayó en sábado
+		"2025-01-09", // Cierre extraordinario
+		"2001-09-12", // 11 de septiembre
+		"2007-01-02", // Duelo por Gerald Ford
+		"2000-01-17", // Martin Luther King Jr.
+		"2024-06-22", // Sábado
+	}
+	for _, d := range closed {
+		assert.False(t, cal.IsTradingDay(mustDate(t, d)), d)
+	}
+
+	open := []string{
+		"2021-12-31", // Año nuevo 2022 cayó en sábado: no se compensa
+		"2021-06-18", // Juneteenth aún no era feriado
+		"1997-01-20", // Martin Luther King Jr. aún no era feriado
+		"2024-11-29",
+		"2024-07-05",
+	}
+	for _, d := range open {
+		assert.True(t, cal.IsTradingDay(mustDate(t, d)), d)
+	}
+}
+
+func TestNYSECalendar_AddTradingDays(t *testing.T) {
+	cal := NewNYSECalendar()
+
+	// Jueves 27/06/2024 + 5 días hábiles salta el fin de semana y el 4 de julio.
+	got := cal.AddTradingDays(mustDate(t, "2024-06-27"), 5)
+	assert.Equal(t, mustDate(t, "2024-07-05"), got)
+}
+
+func TestMissingRanges(t *testing.T) {
+	cal := NewNYSECalendar()
+	expected := cal.TradingDays(mustDate(t, "2024-07-01"), mustDate(t, "2024-07-12"))
+
+	existing := map[string]bool{
+		"2024-07-01": true,
+		"2024-07-05": true,
+		"2024-07-12": true,
+	}
+
+	ranges := MissingRanges(expected, existing)
+	assert.Equal(t, []DateRange{
+		{From: mustDate(t, "2024-07-02"), To: mustDate(t, "2024-07-03")},
+		{From: mustDate(t, "2024-07-08"), To: mustDate(t, "2024-07-11")},
+	}, ranges)
+}
+
+func mustDate(t *testing.T, s string) time.Time {
+	t.Helper()
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
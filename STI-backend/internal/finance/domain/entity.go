@@ -3,13 +3,117 @@ package domain
 import "time"
 
 type Finance struct {
-	Ticker    string
-	Date      time.Time
-	Open      float32
-	High      float32
-	Low       float32
-	Close     float32
+	Ticker string
+	Date   time.Time
+	Open   float32
+	High   float32
+	Low    float32
+	Close  float32
+	// AdjClose es el cierre ajustado por splits y dividendos; 0 si el
+	// proveedor no lo entrega.
+	AdjClose  float32
 	Volume    int64
 	Source    string
 	ScrapedAt time.Time
 }
+
+const (
+	ActionDividend = "dividend"
+	ActionSplit    = "split"
+)
+
+// CorporateAction es un dividendo (Amount por acción) o un split
+// (Numerator:Denominator, p. ej. 4:1).
+type CorporateAction struct {
+	Ticker      string
+	Date        time.Time
+	Type        string
+	Amount      float64
+	Numerator   float64
+	Denominator float64
+	Source      string
+	ScrapedAt   time.Time
+}
+
+// SplitRatio devuelve cuántas acciones nuevas hay por cada acción anterior
+// (4 en un split 4:1); 1 si no es un split válido.
+func (a CorporateAction) SplitRatio() float64 {
+	if a.Type != ActionSplit || a.Numerator <= 0 || a.Denominator <= 0 {
+		return 1
+	}
+	return a.Numerator / a.Denominator
+}
+
+// AdjustmentFactor es el factor por el que se multiplican los cierres
+// ajustados anteriores al evento: 1/SplitRatio en un split y
+// 1 - dividendo/prevClose en un dividendo, con prevClose el cierre del día
+// hábil anterior.
+func (a CorporateAction) AdjustmentFactor(prevClose float64) float64 {
+	switch a.Type {
+	case ActionSplit:
+		return 1 / a.SplitRatio()
+	case ActionDividend:
+		if prevClose > 0 && a.Amount > 0 && a.Amount < prevClose {
+			return 1 - a.Amount/prevClose
+		}
+	}
+	return 1
+}
+
+// PriceHistory es la respuesta de un proveedor de precios.
+type PriceHistory struct {
+	Prices  []Finance
+	Actions []CorporateAction
+}
+
+// SaveResult cuenta las filas afectadas por un guardado masivo. Rejected
+// tiene las filas que fallaron, para enviarlas a cuarentena.
+type SaveResult struct {
+	Inserted int
+	Updated  int
+	Failed   int
+	Rejected []RejectedFinance
+}
+
+// TickerResult es el resultado de actualizar un ticker.
+type TickerResult struct {
+	Ticker   string
+	Rows     int
+	Inserted int
+	Updated  int
+	// Failed cuenta las velas rechazadas que tampoco se pudieron enviar a
+	// cuarentena.
+	Failed int
+	// Quarantined cuenta las velas rechazadas (por validación o por error al
+	// guardar) que quedaron en cuarentena.
+	Quarantined int
+	Attempts    int
+	Err         error
+}
+
+// UpdateSummary resume una corrida de actualización de datos financieros.
+type UpdateSummary struct {
+	TickersProcessed int
+	TickersSucceeded int
+	TickersFailed    int
+	RowsInserted     int
+	RowsUpdated      int
+	RowsFailed       int
+	RowsQuarantined  int
+	Results          []TickerResult
+}
+
+// Add acumula el resultado de un ticker en el resumen.
+func (s *UpdateSummary) Add(r TickerResult) {
+	s.TickersProcessed++
+	if r.Err != nil {
+		s.TickersFailed++
+	} else {
+		s.TickersSucceeded++
+	}
+	s.RowsInserted += r.Inserted
+	s.RowsUpdated += r.Updated
+	s.RowsFailed += r.Failed
+	s.RowsQuarantined += r.Quarantined
+	s.Results = append(s.Results, r)
+}
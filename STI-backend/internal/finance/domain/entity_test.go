@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCorporateAction_AdjustmentFactor(t *testing.T) {
+	split := CorporateAction{Type: ActionSplit, Numerator: 4, Denominator: 1}
+	assert.Equal(t, 4.0, split.SplitRatio())
+	assert.Equal(t, 0.25, split.AdjustmentFactor(0))
+
+	dividend := CorporateAction{Type: ActionDividend, Amount: 2}
+	assert.Equal(t, 1.0, dividend.SplitRatio())
+	assert.InDelta(t, 0.98, dividend.AdjustmentFactor(100), 1e-9)
+	// Sin cierre previo no se puede calcular el factor.
+	assert.Equal(t, 1.0, dividend.AdjustmentFactor(0))
+
+	invalid := CorporateAction{Type: ActionSplit, Numerator: 0, Denominator: 1}
+	assert.Equal(t, 1.0, invalid.AdjustmentFactor(0))
+}
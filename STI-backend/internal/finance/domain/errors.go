@@ -0,0 +1,6 @@
+package domain
+
+import "errors"
+
+// ErrRateLimited indica que el proveedor de precios respondió 429.
+var ErrRateLimited = errors.New("rate limited by price provider")
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	IntervalDaily   = "1d"
+	IntervalWeekly  = "1w"
+	IntervalMonthly = "1mo"
+)
+
+// PriceBar es una vela OHLCV; Date es el primer día hábil del período.
+type PriceBar struct {
+	Date     time.Time `json:"date"`
+	Open     float32   `json:"open"`
+	High     float32   `json:"high"`
+	Low      float32   `json:"low"`
+	Close    float32   `json:"close"`
+	AdjClose float32   `json:"adj_close"`
+	Volume   int64     `json:"volume"`
+}
+
+// Resample agrupa las velas diarias (ordenadas por fecha) en semanas ISO o
+// meses calendario.
+func Resample(data []Finance, interval string) ([]PriceBar, error) {
+	var bucket func(t time.Time) string
+	switch interval {
+	case IntervalDaily:
+		bucket = func(t time.Time) string { return t.Format("2006-01-02") }
+	case IntervalWeekly:
+		bucket = func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		}
+	case IntervalMonthly:
+		bucket = func(t time.Time) string { return t.Format("2006-01") }
+	default:
+		return nil, fmt.Errorf("interval inválido: %q (usa 1d, 1w o 1mo)", interval)
+	}
+
+	bars := []PriceBar{}
+	current := ""
+	for _, f := range data {
+		key := bucket(f.Date)
+		if key != current {
+			current = key
+			bars = append(bars, PriceBar{
+				Date:     f.Date,
+				Open:     f.Open,
+				High:     f.High,
+				Low:      f.Low,
+				Close:    f.Close,
+				AdjClose: f.AdjClose,
+				Volume:   f.Volume,
+			})
+			continue
+		}
+
+		bar := &bars[len(bars)-1]
+		bar.High = max(bar.High, f.High)
+		bar.Low = min(bar.Low, f.Low)
+		bar.Close = f.Close
+		bar.AdjClose = f.AdjClose
+		bar.Volume += f.Volume
+	}
+
+	return bars, nil
+}
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResample_Weekly(t *testing.T) {
+	data := []Finance{
+		{Date: mustDate(t, "2024-07-01"), Open: 10, High: 12, Low: 9, Close: 11, Volume: 100},
+		{Date: mustDate(t, "2024-07-02"), Open: 11, High: 15, Low: 10, Close: 14, Volume: 200},
+		{Date: mustDate(t, "2024-07-05"), Open: 14, High: 14, Low: 8, Close: 9, Volume: 50},
+		{Date: mustDate(t, "2024-07-08"), Open: 9, High: 10, Low: 7, Close: 8, Volume: 10},
+	}
+
+	bars, err := Resample(data, IntervalWeekly)
+	assert.NoError(t, err)
+	assert.Equal(t, []PriceBar{
+		{Date: mustDate(t, "2024-07-01"), Open: 10, High: 15, Low: 8, Close: 9, Volume: 350},
+		{Date: mustDate(t, "2024-07-08"), Open: 9, High: 10, Low: 7, Close: 8, Volume: 10},
+	}, bars)
+}
+
+func TestResample_InvalidInterval(t *testing.T) {
+	_, err := Resample(nil, "5m")
+	assert.Error(t, err)
+}
@@ -0,0 +1,22 @@
+package domain
+
+import "fmt"
+
+// PriceBasis indica si los cálculos de retorno usan el cierre tal como cotizó
+// ("raw") o el cierre ajustado por splits y dividendos ("adjusted").
+type PriceBasis string
+
+const (
+	PriceBasisRaw      PriceBasis = "raw"
+	PriceBasisAdjusted PriceBasis = "adjusted"
+)
+
+func ParsePriceBasis(s string) (PriceBasis, error) {
+	switch PriceBasis(s) {
+	case "", PriceBasisRaw:
+		return PriceBasisRaw, nil
+	case PriceBasisAdjusted:
+		return PriceBasisAdjusted, nil
+	}
+	return "", fmt.Errorf("price_basis inválido: %q (usa raw o adjusted)", s)
+}
@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 type TickerRange struct {
 	Ticker    string
@@ -9,13 +12,24 @@ type TickerRange struct {
 }
 
 type FinanceRepository interface {
-	BulkSave(data []Finance) error
+	BulkSave(data []Finance) (SaveResult, error)
+	GetDates(ticker string, from, to time.Time) ([]time.Time, error)
+	// GetCheckedDates devuelve los días del rango que se pidieron al
+	// proveedor desde since sin obtener una vela válida.
+	GetCheckedDates(ticker string, from, to, since time.Time) ([]time.Time, error)
+	SaveCheckedDates(ticker, source string, dates []time.Time) error
+	SaveCorporateActions(actions []CorporateAction) error
+	GetPrices(ticker string, from, to time.Time) ([]Finance, error)
+	QuarantineFinances(source string, rejected []RejectedFinance) error
 }
 
 type StockRepository interface {
 	GetTickersDateRange() ([]TickerRange, error)
 }
 
+// FinanceScraper es un proveedor de precios diarios. Name identifica al
+// proveedor y se guarda en Finance.Source.
 type FinanceScraper interface {
-	GetHistoricalData(ticker string, from, to time.Time) ([]Finance, error)
+	Name() string
+	GetHistoricalData(ctx context.Context, ticker string, from, to time.Time) (PriceHistory, error)
 }
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidFinance = errors.New("vela inválida")
+
+// RejectedFinance es una vela que no pasó la validación o que no se pudo guardar.
+type RejectedFinance struct {
+	Finance Finance
+	Err     error
+}
+
+// Validate descarta las velas sin ticker ni fecha, sin cierre, con precios o
+// volumen negativos o con un máximo menor que el mínimo. Yahoo entrega null
+// en los días sin operaciones, que llegan aquí como cero.
+func (f Finance) Validate() error {
+	switch {
+	case f.Ticker == "":
+		return fmt.Errorf("%w: sin ticker", ErrInvalidFinance)
+	case f.Date.IsZero():
+		return fmt.Errorf("%w: sin fecha", ErrInvalidFinance)
+	case f.Close <= 0:
+		return fmt.Errorf("%w: cierre %v", ErrInvalidFinance, f.Close)
+	case f.Open < 0 || f.High < 0 || f.Low < 0 || f.AdjClose < 0:
+		return fmt.Errorf("%w: precio negativo", ErrInvalidFinance)
+	case f.Volume < 0:
+		return fmt.Errorf("%w: volumen %d", ErrInvalidFinance, f.Volume)
+	case f.High > 0 && f.Low > 0 && f.High < f.Low:
+		return fmt.Errorf("%w: máximo %v menor que mínimo %v", ErrInvalidFinance, f.High, f.Low)
+	}
+	return nil
+}
+
+// SplitValid separa las velas válidas de las rechazadas.
+func SplitValid(data []Finance) ([]Finance, []RejectedFinance) {
+	valid := make([]Finance, 0, len(data))
+	var rejected []RejectedFinance
+	for _, f := range data {
+		if err := f.Validate(); err != nil {
+			rejected = append(rejected, RejectedFinance{Finance: f, Err: err})
+			continue
+		}
+		valid = append(valid, f)
+	}
+	return valid, rejected
+}
@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitValid(t *testing.T) {
+	day := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	data := []Finance{
+		{Ticker: "AAPL", Date: day, Open: 10, High: 11, Low: 9, Close: 10.5, Volume: 100},
+		{Ticker: "AAPL", Date: day.AddDate(0, 0, 1)},
+		{Ticker: "AAPL", Date: day.AddDate(0, 0, 2), High: 9, Low: 11, Close: 10},
+		{Ticker: "", Date: day, Close: 10},
+	}
+
+	valid, rejected := SplitValid(data)
+
+	assert.Len(t, valid, 1)
+	assert.Len(t, rejected, 3)
+	for _, r := range rejected {
+		assert.ErrorIs(t, r.Err, ErrInvalidFinance)
+	}
+	assert.Contains(t, rejected[1].Err.Error(), "máximo")
+}
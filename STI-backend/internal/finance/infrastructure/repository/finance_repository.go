@@ -2,8 +2,11 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
 	"log"
+	"time"
 
+	"github.com/lib/pq"
 	"github.com/viteant/stockinsight/internal/finance/domain"
 )
 
@@ -15,42 +18,332 @@ func NewCockroachFinanceRepository(db *sql.DB) *CockroachFinanceRepository {
 	return &CockroachFinanceRepository{DB: db}
 }
 
-func (r *CockroachFinanceRepository) BulkSave(data []domain.Finance) error {
+// BulkSave guarda las velas en una transacción. Las filas que fallan se
+// cuentan en Failed y se devuelven en Rejected.
+func (r *CockroachFinanceRepository) BulkSave(data []domain.Finance) (domain.SaveResult, error) {
+	var result domain.SaveResult
+	if len(data) == 0 {
+		return result, nil
+	}
+
 	tx, err := r.DB.Begin()
 	if err != nil {
-		return err
+		return result, err
 	}
 	defer tx.Rollback()
 
+	existing, err := existingFinanceKeys(tx, data)
+	if err != nil {
+		return result, err
+	}
+
 	stmt, err := tx.Prepare(`
 		INSERT INTO finances (
-			id, ticker, date, open, high, low, close, volume, source, scraped_at
+			id, ticker, date, open, high, low, close, adj_close, volume, source, scraped_at
 		) VALUES (
-			gen_random_uuid(), $1, $2, $3, $4, $5, $6, $7, $8, $9
+			gen_random_uuid(), $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
 		)
 		ON CONFLICT (ticker, date) DO UPDATE SET
 			open = excluded.open,
 			high = excluded.high,
 			low = excluded.low,
 			close = excluded.close,
+			adj_close = excluded.adj_close,
 			volume = excluded.volume,
 			source = excluded.source,
 			scraped_at = excluded.scraped_at
 	`)
 	if err != nil {
-		return err
+		return result, err
 	}
 	defer stmt.Close()
 
+	// Cada fila va en un savepoint: un error aborta la transacción completa si
+	// no se vuelve al savepoint.
 	for _, d := range data {
+		if _, err := tx.Exec("SAVEPOINT finance_row"); err != nil {
+			return result, err
+		}
 		_, err := stmt.Exec(
-			d.Ticker, d.Date, d.Open, d.High, d.Low, d.Close, d.Volume, d.Source, d.ScrapedAt,
+			d.Ticker, d.Date, d.Open, d.High, d.Low, d.Close, nullPrice(d.AdjClose), d.Volume, d.Source, d.ScrapedAt,
 		)
 		if err != nil {
 			log.Printf("Error insertando %s [%s]: %v", d.Ticker, d.Date.Format("2006-01-02"), err)
+			result.Failed++
+			result.Rejected = append(result.Rejected, domain.RejectedFinance{Finance: d, Err: err})
+			if _, err := tx.Exec("ROLLBACK TO SAVEPOINT finance_row"); err != nil {
+				return result, err
+			}
 			continue
 		}
+		if _, err := tx.Exec("RELEASE SAVEPOINT finance_row"); err != nil {
+			return result, err
+		}
+		if existing[financeKey(d.Ticker, d.Date)] {
+			result.Updated++
+		} else {
+			result.Inserted++
+		}
+	}
+
+	return result, tx.Commit()
+}
+
+// SaveCorporateActions guarda dividendos y splits; un evento ya registrado
+// para el mismo ticker, fecha y tipo se actualiza. Por cada evento nuevo se
+// reajusta el adj_close guardado antes de su fecha, para que la serie
+// ajustada siga siendo continua.
+func (r *CockroachFinanceRepository) SaveCorporateActions(actions []domain.CorporateAction) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO corporate_actions (
+			ticker, date, type, amount, numerator, denominator, source, scraped_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8
+		)
+		ON CONFLICT (ticker, date, type) DO UPDATE SET
+			amount = excluded.amount,
+			numerator = excluded.numerator,
+			denominator = excluded.denominator,
+			source = excluded.source,
+			scraped_at = excluded.scraped_at
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, a := range actions {
+		var known bool
+		if err := tx.QueryRow(`
+			SELECT EXISTS (
+				SELECT 1 FROM corporate_actions WHERE ticker = $1 AND date = $2 AND type = $3
+			)
+		`, a.Ticker, a.Date, a.Type).Scan(&known); err != nil {
+			return err
+		}
+		if _, err := stmt.Exec(
+			a.Ticker, a.Date, a.Type, a.Amount, a.Numerator, a.Denominator, a.Source, a.ScrapedAt,
+		); err != nil {
+			return err
+		}
+		if !known {
+			if err := readjustHistory(tx, a); err != nil {
+				return err
+			}
+		}
 	}
 
 	return tx.Commit()
 }
+
+// readjustHistory aplica el factor del evento al adj_close de las velas
+// anteriores a su fecha. Las velas scrapeadas desde esa fecha ya traen el
+// evento incluido en el adj_close del proveedor y no se tocan.
+func readjustHistory(tx *sql.Tx, a domain.CorporateAction) error {
+	var prevClose sql.NullFloat64
+	if a.Type == domain.ActionDividend {
+		err := tx.QueryRow(`
+			SELECT close FROM finances
+			WHERE ticker = $1 AND date < $2 AND close IS NOT NULL
+			ORDER BY date DESC
+			LIMIT 1
+		`, a.Ticker, a.Date).Scan(&prevClose)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	factor := a.AdjustmentFactor(prevClose.Float64)
+	if factor == 1 {
+		return nil
+	}
+
+	res, err := tx.Exec(`
+		UPDATE finances SET adj_close = ROUND(COALESCE(adj_close, close) * $3::DECIMAL, 4)
+		WHERE ticker = $1 AND date < $2 AND scraped_at < $2
+	`, a.Ticker, a.Date, factor)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		log.Printf("%s: %d cierres ajustados reajustados por %s del %s (factor %.6f)",
+			a.Ticker, n, a.Type, a.Date.Format("2006-01-02"), factor)
+	}
+	return nil
+}
+
+// GetDates devuelve las fechas con datos guardados para el ticker en el rango.
+func (r *CockroachFinanceRepository) GetDates(ticker string, from, to time.Time) ([]time.Time, error) {
+	rows, err := r.DB.Query(`
+		SELECT date
+		FROM finances
+		WHERE ticker = $1 AND date BETWEEN $2 AND $3
+		ORDER BY date
+	`, ticker, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var dates []time.Time
+	for rows.Next() {
+		var date time.Time
+		if err := rows.Scan(&date); err != nil {
+			return nil, err
+		}
+		dates = append(dates, date)
+	}
+
+	return dates, rows.Err()
+}
+
+func (r *CockroachFinanceRepository) GetCheckedDates(ticker string, from, to, since time.Time) ([]time.Time, error) {
+	rows, err := r.DB.Query(`
+		SELECT date
+		FROM finance_checked_days
+		WHERE ticker = $1 AND date BETWEEN $2 AND $3 AND checked_at >= $4
+		ORDER BY date
+	`, ticker, from, to, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var dates []time.Time
+	for rows.Next() {
+		var date time.Time
+		if err := rows.Scan(&date); err != nil {
+			return nil, err
+		}
+		dates = append(dates, date)
+	}
+
+	return dates, rows.Err()
+}
+
+func (r *CockroachFinanceRepository) SaveCheckedDates(ticker, source string, dates []time.Time) error {
+	if len(dates) == 0 {
+		return nil
+	}
+	_, err := r.DB.Exec(`
+		INSERT INTO finance_checked_days (ticker, date, source)
+		SELECT $1, d, $2 FROM unnest($3::DATE[]) AS d
+		ON CONFLICT (ticker, date) DO UPDATE SET
+			source = excluded.source,
+			checked_at = now()
+	`, ticker, source, pq.Array(formatDates(dates)))
+	return err
+}
+
+// GetPrices devuelve las velas diarias del ticker en el rango, ordenadas por fecha.
+func (r *CockroachFinanceRepository) GetPrices(ticker string, from, to time.Time) ([]domain.Finance, error) {
+	rows, err := r.DB.Query(`
+		SELECT ticker, date, open, high, low, close, adj_close, volume, source, scraped_at
+		FROM finances
+		WHERE ticker = $1 AND date BETWEEN $2 AND $3
+		ORDER BY date
+	`, ticker, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var prices []domain.Finance
+	for rows.Next() {
+		var f domain.Finance
+		var adjClose sql.NullFloat64
+		var volume sql.NullInt64
+		var source sql.NullString
+		if err := rows.Scan(
+			&f.Ticker,
+			&f.Date,
+			&f.Open,
+			&f.High,
+			&f.Low,
+			&f.Close,
+			&adjClose,
+			&volume,
+			&source,
+			&f.ScrapedAt,
+		); err != nil {
+			return nil, err
+		}
+		f.AdjClose = float32(adjClose.Float64)
+		if !adjClose.Valid {
+			f.AdjClose = f.Close
+		}
+		f.Volume = volume.Int64
+		f.Source = source.String
+		prices = append(prices, f)
+	}
+
+	return prices, rows.Err()
+}
+
+// existingFinanceKeys devuelve las combinaciones ticker/fecha que ya existen
+// en finances dentro del rango de los datos recibidos.
+func existingFinanceKeys(tx *sql.Tx, data []domain.Finance) (map[string]bool, error) {
+	tickers := []string{}
+	seen := map[string]bool{}
+	from, to := data[0].Date, data[0].Date
+	for _, d := range data {
+		if !seen[d.Ticker] {
+			seen[d.Ticker] = true
+			tickers = append(tickers, d.Ticker)
+		}
+		if d.Date.Before(from) {
+			from = d.Date
+		}
+		if d.Date.After(to) {
+			to = d.Date
+		}
+	}
+
+	rows, err := tx.Query(`
+		SELECT ticker, date
+		FROM finances
+		WHERE ticker = ANY($1) AND date BETWEEN $2 AND $3
+	`, pq.Array(tickers), from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	existing := map[string]bool{}
+	for rows.Next() {
+		var ticker string
+		var date time.Time
+		if err := rows.Scan(&ticker, &date); err != nil {
+			return nil, err
+		}
+		existing[financeKey(ticker, date)] = true
+	}
+
+	return existing, rows.Err()
+}
+
+// nullPrice guarda NULL cuando el proveedor no entregó el valor.
+func nullPrice(v float32) sql.NullFloat64 {
+	return sql.NullFloat64{Float64: float64(v), Valid: v != 0}
+}
+
+func formatDates(dates []time.Time) []string {
+	formatted := make([]string, len(dates))
+	for i, d := range dates {
+		formatted[i] = d.Format("2006-01-02")
+	}
+	return formatted
+}
+
+func financeKey(ticker string, date time.Time) string {
+	return ticker + "|" + date.Format("2006-01-02")
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/viteant/stockinsight/internal/finance/domain"
+	quarantinedomain "github.com/viteant/stockinsight/internal/quarantine/domain"
+	quarantinerepository "github.com/viteant/stockinsight/internal/quarantine/infrastructure/repository"
+)
+
+// QuarantineFinances guarda las velas rechazadas con el motivo. Una vela se
+// identifica por ticker, fecha y proveedor, así un reintento del mismo día no
+// la duplica aunque cambie scraped_at.
+func (r *CockroachFinanceRepository) QuarantineFinances(source string, rejected []domain.RejectedFinance) error {
+	records := make([]quarantinedomain.Record, 0, len(rejected))
+	for _, rej := range rejected {
+		payload, err := json.Marshal(rej.Finance)
+		if err != nil {
+			return err
+		}
+		records = append(records, quarantinedomain.Record{
+			Kind:    quarantinedomain.KindFinance,
+			Source:  source,
+			Payload: payload,
+			Reason:  rej.Err.Error(),
+			Fingerprint: strings.Join([]string{
+				rej.Finance.Ticker, rej.Finance.Date.Format("2006-01-02"), rej.Finance.Source,
+			}, "|"),
+		})
+	}
+
+	return quarantinerepository.NewCockroachQuarantineRepository(r.DB).Add(records)
+}
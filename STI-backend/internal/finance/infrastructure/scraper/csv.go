@@ -0,0 +1,111 @@
+package scraper
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/viteant/stockinsight/internal/finance/domain"
+)
+
+// parseOHLCVCSV lee un CSV diario con encabezados Date, Open, High, Low, Close
+// y Volume, y opcionalmente Adj Close (sin distinguir mayúsculas; columnas
+// extra se ignoran). Devuelve las filas con fecha en [from, to).
+func parseOHLCVCSV(r io.Reader, ticker, source string, from, to time.Time) ([]domain.Finance, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo encabezado CSV de %s: %w", ticker, err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"date", "open", "high", "low", "close"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV de %s sin columna %q", ticker, required)
+		}
+	}
+
+	var result []domain.Finance
+	scrapedAt := time.Now()
+	line := 1
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("error leyendo CSV de %s (línea %d): %w", ticker, line, err)
+		}
+
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		date, err := time.Parse("2006-01-02", field("date"))
+		if err != nil {
+			return nil, fmt.Errorf("fecha inválida en CSV de %s (línea %d): %w", ticker, line, err)
+		}
+		if date.Before(domain.Day(from)) || !date.Before(to) {
+			continue
+		}
+
+		f := domain.Finance{
+			Ticker:    strings.ToUpper(ticker),
+			Date:      date,
+			Source:    source,
+			ScrapedAt: scrapedAt,
+		}
+		prices := []struct {
+			name string
+			dest *float32
+		}{
+			{"open", &f.Open},
+			{"high", &f.High},
+			{"low", &f.Low},
+			{"close", &f.Close},
+		}
+		for _, p := range prices {
+			v, err := strconv.ParseFloat(field(p.name), 32)
+			if err != nil {
+				return nil, fmt.Errorf("valor %s inválido en CSV de %s (línea %d): %w", p.name, ticker, line, err)
+			}
+			*p.dest = float32(v)
+		}
+		if v := field("adj close"); v != "" {
+			adjClose, err := strconv.ParseFloat(v, 32)
+			if err != nil {
+				return nil, fmt.Errorf("valor adj close inválido en CSV de %s (línea %d): %w", ticker, line, err)
+			}
+			f.AdjClose = float32(adjClose)
+		}
+		if v := field("volume"); v != "" {
+			volume, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("volumen inválido en CSV de %s (línea %d): %w", ticker, line, err)
+			}
+			f.Volume = int64(volume)
+		}
+
+		result = append(result, f)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,56 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/viteant/stockinsight/internal/finance/domain"
+)
+
+// FallbackScraper prueba los proveedores en orden y devuelve los datos del
+// primero que responde sin error y con filas. Si todos fallan, devuelve los
+// errores combinados (errors.Is sigue detectando domain.ErrRateLimited).
+type FallbackScraper struct {
+	Providers []domain.FinanceScraper
+}
+
+func NewFallbackScraper(providers ...domain.FinanceScraper) *FallbackScraper {
+	return &FallbackScraper{Providers: providers}
+}
+
+func (f *FallbackScraper) Name() string {
+	names := make([]string, 0, len(f.Providers))
+	for _, p := range f.Providers {
+		names = append(names, p.Name())
+	}
+	return strings.Join(names, ",")
+}
+
+func (f *FallbackScraper) GetHistoricalData(
+	ctx context.Context, ticker string, from, to time.Time,
+) (domain.PriceHistory, error) {
+	var errs []error
+
+	for _, p := range f.Providers {
+		history, err := p.GetHistoricalData(ctx, ticker, from, to)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return domain.PriceHistory{}, ctxErr
+		}
+		if err != nil {
+			log.Printf("Proveedor %s falló para %s: %v", p.Name(), ticker, err)
+			errs = append(errs, fmt.Errorf("%s: %w", p.Name(), err))
+			continue
+		}
+		if len(history.Prices) == 0 {
+			log.Printf("Proveedor %s sin datos para %s", p.Name(), ticker)
+			continue
+		}
+		return history, nil
+	}
+
+	return domain.PriceHistory{}, errors.Join(errs...)
+}
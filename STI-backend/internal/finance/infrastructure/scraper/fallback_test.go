@@ -0,0 +1,64 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viteant/stockinsight/internal/finance/domain"
+)
+
+type fakeProvider struct {
+	name   string
+	prices []domain.Finance
+	err    error
+	calls  int
+}
+
+func (f *fakeProvider) Name() string { return f.name }
+
+func (f *fakeProvider) GetHistoricalData(ctx context.Context, ticker string, from, to time.Time) (domain.PriceHistory, error) {
+	f.calls++
+	return domain.PriceHistory{Prices: f.prices}, f.err
+}
+
+func TestFallbackScraper_UsesFirstProviderWithData(t *testing.T) {
+	failing := &fakeProvider{name: "yahoo", err: domain.ErrRateLimited}
+	empty := &fakeProvider{name: "stooq"}
+	local := &fakeProvider{name: "local_csv", prices: []domain.Finance{{Ticker: "AAPL", Source: "local_csv"}}}
+	unused := &fakeProvider{name: "otro"}
+
+	f := NewFallbackScraper(failing, empty, local, unused)
+	history, err := f.GetHistoricalData(context.Background(), "AAPL", time.Now(), time.Now())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "local_csv", history.Prices[0].Source)
+	assert.Equal(t, 0, unused.calls)
+	assert.Equal(t, "yahoo,stooq,local_csv,otro", f.Name())
+}
+
+func TestFallbackScraper_JoinsErrors(t *testing.T) {
+	f := NewFallbackScraper(
+		&fakeProvider{name: "yahoo", err: domain.ErrRateLimited},
+		&fakeProvider{name: "stooq", err: errors.New("HTTP 500")},
+	)
+
+	_, err := f.GetHistoricalData(context.Background(), "AAPL", time.Now(), time.Now())
+
+	assert.ErrorIs(t, err, domain.ErrRateLimited)
+	assert.ErrorContains(t, err, "stooq: HTTP 500")
+}
+
+func TestFallbackScraper_StopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	second := &fakeProvider{name: "stooq"}
+
+	_, err := NewFallbackScraper(&fakeProvider{name: "yahoo", err: context.Canceled}, second).
+		GetHistoricalData(ctx, "AAPL", time.Now(), time.Now())
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 0, second.calls)
+}
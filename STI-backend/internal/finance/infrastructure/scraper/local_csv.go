@@ -0,0 +1,52 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/viteant/stockinsight/internal/finance/domain"
+)
+
+// LocalCSVProvider lee precios desde archivos <TICKER>.csv en un directorio,
+// con el mismo formato OHLCV que Stooq y una columna Adj Close opcional.
+type LocalCSVProvider struct {
+	Dir string
+}
+
+func NewLocalCSVProvider(dir string) *LocalCSVProvider {
+	return &LocalCSVProvider{Dir: dir}
+}
+
+func (p *LocalCSVProvider) Name() string {
+	return "local_csv"
+}
+
+func (p *LocalCSVProvider) GetHistoricalData(
+	ctx context.Context, ticker string, from, to time.Time,
+) (domain.PriceHistory, error) {
+	var history domain.PriceHistory
+	if err := ctx.Err(); err != nil {
+		return history, err
+	}
+
+	for _, name := range []string{strings.ToUpper(ticker), strings.ToLower(ticker)} {
+		file, err := os.Open(filepath.Join(p.Dir, name+".csv"))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return history, fmt.Errorf("error abriendo CSV de %s: %w", ticker, err)
+		}
+		defer file.Close()
+
+		history.Prices, err = parseOHLCVCSV(file, ticker, p.Name(), from, to)
+		return history, err
+	}
+
+	return history, fmt.Errorf("no existe %s.csv en %s", strings.ToUpper(ticker), p.Dir)
+}
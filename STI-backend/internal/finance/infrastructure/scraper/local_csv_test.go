@@ -0,0 +1,53 @@
+package scraper
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalCSVProvider_ReadsTickerFile(t *testing.T) {
+	dir := t.TempDir()
+	csv := "date,open,high,low,close,adj close,volume\n" +
+		"2024-07-01,10,12,9,11,10.5,1000\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "msft.csv"), []byte(csv), 0o644))
+
+	p := NewLocalCSVProvider(dir)
+	from := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	history, err := p.GetHistoricalData(context.Background(), "MSFT", from, from.AddDate(0, 0, 1))
+
+	assert.NoError(t, err)
+	if assert.Len(t, history.Prices, 1) {
+		assert.Equal(t, float32(10.5), history.Prices[0].AdjClose)
+		assert.Equal(t, "local_csv", history.Prices[0].Source)
+	}
+}
+
+func TestLocalCSVProvider_MissingFile(t *testing.T) {
+	p := NewLocalCSVProvider(t.TempDir())
+
+	_, err := p.GetHistoricalData(context.Background(), "MSFT", time.Now().AddDate(0, 0, -1), time.Now())
+
+	assert.ErrorContains(t, err, "no existe MSFT.csv")
+}
+
+func TestParseOHLCVCSV_RejectsInvalidFiles(t *testing.T) {
+	from := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	cases := map[string]string{
+		"sin columna close": "Date,Open,High,Low\n2024-07-01,1,2,0.5\n",
+		"fecha inválida":    "Date,Open,High,Low,Close\n01/07/2024,1,2,0.5,1\n",
+		"precio inválido":   "Date,Open,High,Low,Close\n2024-07-01,1,2,0.5,n/a\n",
+	}
+	for name, csv := range cases {
+		dir := t.TempDir()
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "AAPL.csv"), []byte(csv), 0o644))
+
+		_, err := NewLocalCSVProvider(dir).GetHistoricalData(context.Background(), "AAPL", from, from.AddDate(0, 0, 1))
+
+		assert.Error(t, err, name)
+	}
+}
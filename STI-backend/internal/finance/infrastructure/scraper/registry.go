@@ -0,0 +1,74 @@
+package scraper
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/viteant/stockinsight/internal/cassette"
+	"github.com/viteant/stockinsight/internal/finance/domain"
+)
+
+// Factory construye un proveedor de precios a partir de la configuración. Los
+// proveedores HTTP graban o reproducen sus consultas según tape.
+type Factory func(tape cassette.Config) (domain.FinanceScraper, error)
+
+var registry = map[string]Factory{
+	"yahoo": func(tape cassette.Config) (domain.FinanceScraper, error) {
+		return NewYahooFinanceScraper(tape), nil
+	},
+	"stooq": func(cassette.Config) (domain.FinanceScraper, error) {
+		return NewStooqScraper(), nil
+	},
+	"local_csv": func(cassette.Config) (domain.FinanceScraper, error) {
+		dir := os.Getenv("FINANCE_CSV_DIR")
+		if dir == "" {
+			return nil, errors.New("FINANCE_CSV_DIR no está definido")
+		}
+		return NewLocalCSVProvider(dir), nil
+	},
+}
+
+// Register agrega o reemplaza un proveedor en el registro.
+func Register(name string, factory Factory) {
+	registry[name] = factory
+}
+
+func New(name string, tape cassette.Config) (domain.FinanceScraper, error) {
+	factory, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("proveedor de precios desconocido: %s", name)
+	}
+	return factory(tape)
+}
+
+// NewFromEnv arma la cadena de proveedores de FINANCE_PROVIDERS, una lista
+// separada por comas en orden de preferencia (por defecto: "yahoo").
+func NewFromEnv(tape cassette.Config) (domain.FinanceScraper, error) {
+	names := os.Getenv("FINANCE_PROVIDERS")
+	if names == "" {
+		names = "yahoo"
+	}
+
+	var providers []domain.FinanceScraper
+	for _, name := range strings.Split(names, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		p, err := New(name, tape)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, p)
+	}
+
+	switch len(providers) {
+	case 0:
+		return nil, errors.New("FINANCE_PROVIDERS no contiene proveedores")
+	case 1:
+		return providers[0], nil
+	}
+	return NewFallbackScraper(providers...), nil
+}
@@ -0,0 +1,46 @@
+package scraper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viteant/stockinsight/internal/cassette"
+	"github.com/viteant/stockinsight/internal/finance/domain"
+)
+
+func TestNewFromEnv(t *testing.T) {
+	t.Setenv("FINANCE_PROVIDERS", "")
+	p, err := NewFromEnv(cassette.Config{})
+	assert.NoError(t, err)
+	assert.Equal(t, "yahoo", p.Name())
+
+	t.Setenv("FINANCE_PROVIDERS", " Yahoo , stooq,")
+	p, err = NewFromEnv(cassette.Config{})
+	assert.NoError(t, err)
+	assert.IsType(t, &FallbackScraper{}, p)
+	assert.Equal(t, "yahoo,stooq", p.Name())
+
+	t.Setenv("FINANCE_PROVIDERS", "yahoo,desconocido")
+	_, err = NewFromEnv(cassette.Config{})
+	assert.ErrorContains(t, err, "desconocido")
+
+	t.Setenv("FINANCE_PROVIDERS", "local_csv")
+	t.Setenv("FINANCE_CSV_DIR", "")
+	_, err = NewFromEnv(cassette.Config{})
+	assert.ErrorContains(t, err, "FINANCE_CSV_DIR")
+
+	t.Setenv("FINANCE_PROVIDERS", ",")
+	_, err = NewFromEnv(cassette.Config{})
+	assert.Error(t, err)
+}
+
+func TestRegister(t *testing.T) {
+	Register("fake", func(cassette.Config) (domain.FinanceScraper, error) {
+		return &fakeProvider{name: "fake"}, nil
+	})
+	defer delete(registry, "fake")
+
+	p, err := New("fake", cassette.Config{})
+	assert.NoError(t, err)
+	assert.Equal(t, "fake", p.Name())
+}
@@ -0,0 +1,84 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/viteant/stockinsight/internal/finance/domain"
+)
+
+const defaultStooqURL = "https://stooq.com/q/d/l/"
+
+// StooqScraper descarga el CSV diario de Stooq. Los tickers de EE.UU. llevan
+// el sufijo ".us" en Stooq.
+type StooqScraper struct {
+	BaseURL string
+	Suffix  string
+}
+
+func NewStooqScraper() *StooqScraper {
+	baseURL := os.Getenv("STOOQ_URL")
+	if baseURL == "" {
+		baseURL = defaultStooqURL
+	}
+	return &StooqScraper{
+		BaseURL: baseURL,
+		Suffix:  ".us",
+	}
+}
+
+func (s *StooqScraper) Name() string {
+	return "stooq"
+}
+
+func (s *StooqScraper) GetHistoricalData(
+	ctx context.Context, ticker string, from, to time.Time,
+) (domain.PriceHistory, error) {
+	var history domain.PriceHistory
+
+	query := url.Values{}
+	query.Set("s", strings.ToLower(ticker)+s.Suffix)
+	query.Set("d1", from.Format("20060102"))
+	query.Set("d2", to.Format("20060102"))
+	query.Set("i", "d")
+
+	endpoint := s.BaseURL + "?" + query.Encode()
+	log.Printf("🌐 Consultando Stooq para %s: %s", ticker, endpoint)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return history, fmt.Errorf("error creando solicitud para %s: %w", ticker, err)
+	}
+	req.Header.Set("User-Agent", getRandomUserAgent())
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return history, fmt.Errorf("error HTTP al solicitar %s: %w", ticker, err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return history, fmt.Errorf("error leyendo respuesta para %s: %w", ticker, err)
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || bytes.Contains(body, []byte("Exceeded the daily hits limit")) {
+		return history, fmt.Errorf("límite de Stooq para %s: %w", ticker, domain.ErrRateLimited)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return history, fmt.Errorf("HTTP %d para %s: %s", resp.StatusCode, ticker, string(body))
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(body), []byte("No data")) {
+		return history, nil
+	}
+
+	history.Prices, err = parseOHLCVCSV(bytes.NewReader(body), ticker, s.Name(), from, to)
+	return history, err
+}
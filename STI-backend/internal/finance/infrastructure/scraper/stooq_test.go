@@ -0,0 +1,76 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viteant/stockinsight/internal/finance/domain"
+)
+
+func TestStooqScraper_ParsesCSV(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Write([]byte("Date,Open,High,Low,Close,Volume\n" +
+			"2024-07-01,10,12,9,11,1000\n" +
+			"2024-07-02,11,13,10,12.5,2000\n" +
+			"2024-07-03,12,14,11,13,3000\n"))
+	}))
+	defer server.Close()
+
+	s := &StooqScraper{BaseURL: server.URL, Suffix: ".us"}
+	from := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	history, err := s.GetHistoricalData(context.Background(), "AAPL", from, from.AddDate(0, 0, 2))
+
+	assert.NoError(t, err)
+	assert.Contains(t, query, "s=aapl.us")
+	assert.Contains(t, query, "d1=20240701")
+	// to es exclusivo: el 03/07 queda afuera.
+	if assert.Len(t, history.Prices, 2) {
+		assert.Equal(t, "AAPL", history.Prices[1].Ticker)
+		assert.Equal(t, float32(12.5), history.Prices[1].Close)
+		assert.Equal(t, int64(2000), history.Prices[1].Volume)
+		assert.Equal(t, "stooq", history.Prices[1].Source)
+	}
+}
+
+func TestStooqScraper_Errors(t *testing.T) {
+	cases := []struct {
+		name   string
+		status int
+		body   string
+		check  func(t *testing.T, history domain.PriceHistory, err error)
+	}{
+		{"429", http.StatusTooManyRequests, "", func(t *testing.T, _ domain.PriceHistory, err error) {
+			assert.ErrorIs(t, err, domain.ErrRateLimited)
+		}},
+		{"límite diario", http.StatusOK, "Exceeded the daily hits limit", func(t *testing.T, _ domain.PriceHistory, err error) {
+			assert.ErrorIs(t, err, domain.ErrRateLimited)
+		}},
+		{"sin datos", http.StatusOK, "No data", func(t *testing.T, history domain.PriceHistory, err error) {
+			assert.NoError(t, err)
+			assert.Empty(t, history.Prices)
+		}},
+		{"error del servidor", http.StatusInternalServerError, "boom", func(t *testing.T, _ domain.PriceHistory, err error) {
+			assert.ErrorContains(t, err, "HTTP 500")
+		}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			s := &StooqScraper{BaseURL: server.URL, Suffix: ".us"}
+			history, err := s.GetHistoricalData(context.Background(), "AAPL", time.Now().AddDate(0, 0, -5), time.Now())
+			tc.check(t, history, err)
+		})
+	}
+}
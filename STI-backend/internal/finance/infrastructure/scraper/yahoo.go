@@ -1,6 +1,7 @@
 package scraper
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/viteant/stockinsight/internal/cassette"
 	"github.com/viteant/stockinsight/internal/finance/domain"
 )
 
@@ -20,10 +22,20 @@ var userAgents = []string{
 	// Puedes añadir más variantes
 }
 
-type YahooFinanceScraper struct{}
+type YahooFinanceScraper struct {
+	Client *http.Client
+}
+
+// NewYahooFinanceScraper graba o reproduce las consultas en el cassette
+// "yahoo" si tape tiene directorio.
+func NewYahooFinanceScraper(tape cassette.Config) *YahooFinanceScraper {
+	return &YahooFinanceScraper{
+		Client: &http.Client{Transport: tape.Wrap("yahoo", http.DefaultTransport)},
+	}
+}
 
-func NewYahooFinanceScraper() *YahooFinanceScraper {
-	return &YahooFinanceScraper{}
+func (s *YahooFinanceScraper) Name() string {
+	return "yahoo"
 }
 
 func getRandomUserAgent() string {
@@ -42,79 +54,129 @@ type yahooResponse struct {
 					Close  []float64 `json:"close"`
 					Volume []int64   `json:"volume"`
 				} `json:"quote"`
+				AdjClose []struct {
+					AdjClose []float64 `json:"adjclose"`
+				} `json:"adjclose"`
 			} `json:"indicators"`
+			Events struct {
+				Dividends map[string]struct {
+					Amount float64 `json:"amount"`
+					Date   int64   `json:"date"`
+				} `json:"dividends"`
+				Splits map[string]struct {
+					Date        int64   `json:"date"`
+					Numerator   float64 `json:"numerator"`
+					Denominator float64 `json:"denominator"`
+				} `json:"splits"`
+			} `json:"events"`
 		} `json:"result"`
 		Error any `json:"error"`
 	} `json:"chart"`
 }
 
+// GetHistoricalData hace una sola consulta al endpoint chart de Yahoo. Ante un
+// 429 devuelve domain.ErrRateLimited para que quien llama aplique el backoff.
 func (s *YahooFinanceScraper) GetHistoricalData(
-	ticker string, from, to time.Time,
-) ([]domain.Finance, error) {
+	ctx context.Context, ticker string, from, to time.Time,
+) (domain.PriceHistory, error) {
+	var history domain.PriceHistory
+
 	url := fmt.Sprintf(
 		"https://query2.finance.yahoo.com/v8/finance/chart/%s?period1=%d&period2=%d&interval=1d&events=history&includeAdjustedClose=true",
 		ticker, from.Unix(), to.Unix(),
 	)
 	log.Printf("🌐 Consultando URL para %s: %s", ticker, url)
 
-	const maxRetries = 3
-	delay := 1 * time.Second
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return history, fmt.Errorf("error creando solicitud para %s: %w", ticker, err)
+	}
+	req.Header.Set("User-Agent", getRandomUserAgent())
 
-	for attempt := 0; attempt <= maxRetries; attempt++ {
-		req, _ := http.NewRequest("GET", url, nil)
-		req.Header.Set("User-Agent", getRandomUserAgent())
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return history, fmt.Errorf("error HTTP al solicitar %s: %w", ticker, err)
+	}
+	bodyBytes, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return history, fmt.Errorf("error leyendo respuesta para %s: %w", ticker, err)
+	}
 
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil {
-			return nil, fmt.Errorf("error HTTP al solicitar %s: %w", ticker, err)
-		}
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		resp.Body.Close()
-
-		if resp.StatusCode == http.StatusTooManyRequests {
-			if attempt == maxRetries {
-				return nil, fmt.Errorf("recibido 429 varias veces para %s", ticker)
-			}
-			log.Printf("429 para %s (intento %d/%d), esperando %v...", ticker, attempt+1, maxRetries, delay)
-			time.Sleep(delay)
-			delay *= 2
-			continue
-		}
-		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("HTTP %d para %s: %s", resp.StatusCode, ticker, string(bodyBytes))
-		}
-		if len(bodyBytes) == 0 || (bodyBytes[0] != '{' && bodyBytes[0] != '[') {
-			return nil, fmt.Errorf("respuesta inesperada para %s: %s", ticker, string(bodyBytes))
-		}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return history, fmt.Errorf("429 para %s: %w", ticker, domain.ErrRateLimited)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return history, fmt.Errorf("HTTP %d para %s: %s", resp.StatusCode, ticker, string(bodyBytes))
+	}
+	if len(bodyBytes) == 0 || (bodyBytes[0] != '{' && bodyBytes[0] != '[') {
+		return history, fmt.Errorf("respuesta inesperada para %s: %s", ticker, string(bodyBytes))
+	}
+
+	var yr yahooResponse
+	if err := json.Unmarshal(bodyBytes, &yr); err != nil {
+		return history, fmt.Errorf("error parseando JSON para %s: %w", ticker, err)
+	}
+	if len(yr.Chart.Result) == 0 || len(yr.Chart.Result[0].Timestamp) == 0 {
+		return history, fmt.Errorf("sin datos utiles para %s", ticker)
+	}
+
+	chart := yr.Chart.Result[0]
+	quote := chart.Indicators.Quote[0]
+	var adjClose []float64
+	if len(chart.Indicators.AdjClose) > 0 {
+		adjClose = chart.Indicators.AdjClose[0].AdjClose
+	}
+
+	ticker = strings.ToUpper(ticker)
+	scrapedAt := time.Now()
 
-		var yr yahooResponse
-		if err := json.Unmarshal(bodyBytes, &yr); err != nil {
-			return nil, fmt.Errorf("error parseando JSON para %s: %w", ticker, err)
+	for i, ts := range chart.Timestamp {
+		if i >= len(quote.Open) {
+			break
 		}
-		if len(yr.Chart.Result) == 0 || len(yr.Chart.Result[0].Timestamp) == 0 {
-			return nil, fmt.Errorf("sin datos utiles para %s", ticker)
+		f := domain.Finance{
+			Ticker:    ticker,
+			Date:      yahooDate(ts),
+			Open:      float32(quote.Open[i]),
+			High:      float32(quote.High[i]),
+			Low:       float32(quote.Low[i]),
+			Close:     float32(quote.Close[i]),
+			Volume:    quote.Volume[i],
+			Source:    s.Name(),
+			ScrapedAt: scrapedAt,
 		}
-
-		quote := yr.Chart.Result[0].Indicators.Quote[0]
-		var result []domain.Finance
-		for i, ts := range yr.Chart.Result[0].Timestamp {
-			if i >= len(quote.Open) {
-				break
-			}
-			result = append(result, domain.Finance{
-				Ticker:    strings.ToUpper(ticker),
-				Date:      time.Unix(ts, 0).UTC().Truncate(24 * time.Hour),
-				Open:      float32(quote.Open[i]),
-				High:      float32(quote.High[i]),
-				Low:       float32(quote.Low[i]),
-				Close:     float32(quote.Close[i]),
-				Volume:    quote.Volume[i],
-				Source:    "Yahoo",
-				ScrapedAt: time.Now(),
-			})
+		if i < len(adjClose) {
+			f.AdjClose = float32(adjClose[i])
 		}
-		return result, nil
+		history.Prices = append(history.Prices, f)
+	}
+
+	for _, d := range chart.Events.Dividends {
+		history.Actions = append(history.Actions, domain.CorporateAction{
+			Ticker:    ticker,
+			Date:      yahooDate(d.Date),
+			Type:      domain.ActionDividend,
+			Amount:    d.Amount,
+			Source:    s.Name(),
+			ScrapedAt: scrapedAt,
+		})
 	}
+	for _, sp := range chart.Events.Splits {
+		history.Actions = append(history.Actions, domain.CorporateAction{
+			Ticker:      ticker,
+			Date:        yahooDate(sp.Date),
+			Type:        domain.ActionSplit,
+			Numerator:   sp.Numerator,
+			Denominator: sp.Denominator,
+			Source:      s.Name(),
+			ScrapedAt:   scrapedAt,
+		})
+	}
+
+	return history, nil
+}
 
-	return nil, fmt.Errorf("falló scrapeo de %s tras %d intentos", ticker, maxRetries)
+func yahooDate(ts int64) time.Time {
+	return time.Unix(ts, 0).UTC().Truncate(24 * time.Hour)
 }
@@ -1,25 +1,62 @@
 package interfaces
 
 import (
+	"context"
+
+	"github.com/viteant/stockinsight/internal/cassette"
 	"log"
 
 	"github.com/viteant/stockinsight/internal/db"
+	"github.com/viteant/stockinsight/internal/finance/domain"
 	"github.com/viteant/stockinsight/internal/finance/infrastructure/repository"
 	"github.com/viteant/stockinsight/internal/finance/infrastructure/scraper"
 	usecases "github.com/viteant/stockinsight/internal/finance/use-cases"
+	syncrundomain "github.com/viteant/stockinsight/internal/syncrun/domain"
+	syncrunrepository "github.com/viteant/stockinsight/internal/syncrun/infrastructure/repository"
+	syncrunusecases "github.com/viteant/stockinsight/internal/syncrun/use_cases"
 )
 
-func SyncFinanceHandler() {
+// SyncFinanceHandler actualiza los datos financieros y registra la corrida en
+// sync_runs. tape indica si las consultas se graban o reproducen. onRun recibe
+// el ID de la corrida apenas se registra; onRun y onTicker pueden ser nil.
+func SyncFinanceHandler(ctx context.Context, tape cassette.Config, onRun func(runID string), onTicker func(done, total int, summary domain.UpdateSummary)) error {
 	dataBase := db.NewCockroachDB()
+	defer dataBase.Close()
+
+	provider, err := scraper.NewFromEnv(tape)
+	if err != nil {
+		return err
+	}
 
 	useCase := usecases.NewUpdateFinanceDataUseCase(
 		repository.NewCockroachStockRepository(dataBase),
 		repository.NewCockroachFinanceRepository(dataBase),
-		scraper.NewYahooFinanceScraper(),
+		provider,
 	)
+	useCase.OnTicker = onTicker
+	runs := syncrunrepository.NewCockroachRunRepository(dataBase)
+
+	run, stopHeartbeat, err := syncrunusecases.NewTracker(runs).Start(syncrundomain.KindFinances, provider.Name())
+	if err != nil {
+		return err
+	}
+	if onRun != nil {
+		onRun(run.ID)
+	}
+
+	summary, execErr := useCase.Execute(ctx)
+	stopHeartbeat()
+
+	run.PagesFetched = summary.TickersProcessed
+	run.RowsInserted = summary.RowsInserted
+	run.RowsUpdated = summary.RowsUpdated
+	run.RowsFailed = summary.RowsFailed
+	run.RowsQuarantined = summary.RowsQuarantined
+	run.Complete(execErr)
 
-	if err := useCase.Execute(); err != nil {
-		log.Fatalf("Error ejecutando UpdateFinanceDataUseCase: %v", err)
+	if err := runs.Finish(run); err != nil {
+		log.Printf("Error registrando la corrida %s: %v", run.ID, err)
 	}
 
+	return execErr
 }
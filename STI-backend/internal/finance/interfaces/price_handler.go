@@ -0,0 +1,82 @@
+package interfaces
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/viteant/stockinsight/internal/finance/domain"
+	usecases "github.com/viteant/stockinsight/internal/finance/use-cases"
+	"github.com/viteant/stockinsight/internal/problem"
+)
+
+type PriceHandler struct {
+	useCase *usecases.PriceService
+}
+
+func NewPriceHandler(useCase *usecases.PriceService) *PriceHandler {
+	return &PriceHandler{
+		useCase: useCase,
+	}
+}
+
+// GetPrices godoc
+// @Summary Serie de precios de un ticker
+// @Description Devuelve velas OHLCV del ticker, agregadas en el servidor por día, semana o mes.
+// @Tags Tickers
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param ticker path string true "Ticker"
+// @Param from query string false "Fecha inicial (YYYY-MM-DD, default: un año antes de to)"
+// @Param to query string false "Fecha final (YYYY-MM-DD, default: hoy)"
+// @Param interval query string false "Intervalo (1d, 1w o 1mo, default: 1d)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 429 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /api/tickers/{ticker}/prices [get]
+func (h *PriceHandler) GetPrices(c *fiber.Ctx) error {
+	to := domain.Day(time.Now())
+	if v := c.Query("to"); v != "" {
+		parsed, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return problem.Invalid(c, problem.FieldError{Field: "to", Value: v, Reason: "se esperaba una fecha YYYY-MM-DD"})
+		}
+		to = parsed
+	}
+
+	from := to.AddDate(-1, 0, 0)
+	if v := c.Query("from"); v != "" {
+		parsed, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return problem.Invalid(c, problem.FieldError{Field: "from", Value: v, Reason: "se esperaba una fecha YYYY-MM-DD"})
+		}
+		from = parsed
+	}
+	if from.After(to) {
+		return problem.Invalid(c, problem.FieldError{Field: "from", Value: from.Format("2006-01-02"), Reason: "debe ser anterior o igual a to"})
+	}
+
+	interval := c.Query("interval", domain.IntervalDaily)
+	switch interval {
+	case domain.IntervalDaily, domain.IntervalWeekly, domain.IntervalMonthly:
+	default:
+		return problem.Invalid(c, problem.FieldError{Field: "interval", Value: interval, Reason: "debe ser 1d, 1w o 1mo"})
+	}
+
+	bars, err := h.useCase.GetPrices(c.Params("ticker"), from, to, interval)
+	if err != nil {
+		return problem.Internal(c, "Error fetching prices", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"ticker":   strings.ToUpper(c.Params("ticker")),
+		"interval": interval,
+		"from":     from.Format("2006-01-02"),
+		"to":       to.Format("2006-01-02"),
+		"items":    bars,
+	})
+}
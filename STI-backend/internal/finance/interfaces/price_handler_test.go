@@ -0,0 +1,56 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/viteant/stockinsight/internal/finance/domain"
+	usecases "github.com/viteant/stockinsight/internal/finance/use-cases"
+	"github.com/viteant/stockinsight/internal/problem"
+)
+
+type fakePrices struct {
+	domain.FinanceRepository
+	from, to time.Time
+}
+
+func (f *fakePrices) GetPrices(ticker string, from, to time.Time) ([]domain.Finance, error) {
+	f.from, f.to = from, to
+	return nil, nil
+}
+
+func TestGetPrices_ValidatesDateRange(t *testing.T) {
+	repo := &fakePrices{}
+	app := fiber.New()
+	app.Get("/api/tickers/:ticker/prices", NewPriceHandler(&usecases.PriceService{Repo: repo}).GetPrices)
+
+	cases := []struct {
+		query string
+		field string
+	}{
+		{"from=2025-03-10&to=2025-03-01", "from"},
+		{"from=10/03/2025", "from"},
+		{"to=2025-13-01", "to"},
+		{"interval=1h", "interval"},
+	}
+	for _, tc := range cases {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/tickers/aapl/prices?"+tc.query, nil))
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode, tc.query)
+
+		var body problem.Problem
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		if assert.Len(t, body.Errors, 1, tc.query) {
+			assert.Equal(t, tc.field, body.Errors[0].Field, tc.query)
+		}
+	}
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/tickers/aapl/prices?from=2025-03-10&to=2025-03-10", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, repo.from, repo.to)
+}
@@ -0,0 +1,17 @@
+package interfaces
+
+import (
+	"database/sql"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/viteant/stockinsight/internal/finance/infrastructure/repository"
+	usecases "github.com/viteant/stockinsight/internal/finance/use-cases"
+)
+
+func RegisterFinanceRoutes(app fiber.Router, db *sql.DB) {
+	financeRepo := repository.NewCockroachFinanceRepository(db)
+	priceService := &usecases.PriceService{Repo: financeRepo}
+	priceHandler := NewPriceHandler(priceService)
+
+	app.Get("/tickers/:ticker/prices", priceHandler.GetPrices)
+}
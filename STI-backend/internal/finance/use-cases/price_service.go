@@ -0,0 +1,20 @@
+package usecases
+
+import (
+	"strings"
+	"time"
+
+	"github.com/viteant/stockinsight/internal/finance/domain"
+)
+
+type PriceService struct {
+	Repo domain.FinanceRepository
+}
+
+func (s *PriceService) GetPrices(ticker string, from, to time.Time, interval string) ([]domain.PriceBar, error) {
+	data, err := s.Repo.GetPrices(strings.ToUpper(ticker), from, to)
+	if err != nil {
+		return nil, err
+	}
+	return domain.Resample(data, interval)
+}
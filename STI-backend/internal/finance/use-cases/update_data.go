@@ -1,63 +1,392 @@
 package usecases
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"os"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/viteant/stockinsight/internal/finance/domain"
+	"github.com/viteant/stockinsight/internal/ratelimit"
+)
+
+const (
+	defaultWorkers       = 4
+	defaultMaxAttempts   = 3
+	defaultLookaheadDays = 90
+	defaultRecheckDays   = 30
+	baseBackoff          = 2 * time.Second
+	maxBackoff           = time.Minute
+
+	// Con más huecos que este límite se pide un único rango que los cubra.
+	maxRangesPerTicker = 5
 )
 
 type UpdateFinanceDataUseCase struct {
 	StockRepo   domain.StockRepository
 	FinanceRepo domain.FinanceRepository
 	Scraper     domain.FinanceScraper
+	Workers     int
+	MaxAttempts int
+	Limiter     *ratelimit.TokenBucket
+	// Backoff es la pausa del pool ante el primer 429; se duplica con cada
+	// 429 consecutivo hasta maxBackoff.
+	Backoff time.Duration
+
+	// Calendar define los días hábiles esperados en finances y LookaheadDays
+	// cuántos días hábiles después del último rating se siguen completando.
+	Calendar      *domain.TradingCalendar
+	LookaheadDays int
+	Now           func() time.Time
+
+	// RecheckDays es cuántos días se esperan antes de volver a pedir al
+	// proveedor un día hábil para el que no devolvió una vela válida.
+	RecheckDays int
+
+	// OnTicker, si está definido, recibe el resumen acumulado cada vez que
+	// termina un ticker.
+	OnTicker func(done, total int, summary domain.UpdateSummary)
+
+	mu             sync.Mutex
+	consecutive429 int
 }
 
+// NewUpdateFinanceDataUseCase configura el pool con FINANCE_WORKERS workers y
+// un limitador compartido de FINANCE_RATE_PER_SEC consultas por segundo. Si
+// solo está definido THROTTLE_MS, la tasa se deriva de ese intervalo. La
+// ventana posterior al último rating se toma de FINANCE_LOOKAHEAD_DAYS y la
+// espera para volver a pedir días sin datos de FINANCE_RECHECK_DAYS.
 func NewUpdateFinanceDataUseCase(
 	stockRepo domain.StockRepository,
 	financeRepo domain.FinanceRepository,
 	scraper domain.FinanceScraper,
 ) *UpdateFinanceDataUseCase {
+	rate := 2.0
+	if v := os.Getenv("THROTTLE_MS"); v != "" {
+		if ms, err := strconv.Atoi(v); err == nil && ms > 0 {
+			rate = 1000 / float64(ms)
+		}
+	}
+	if v := os.Getenv("FINANCE_RATE_PER_SEC"); v != "" {
+		if r, err := strconv.ParseFloat(v, 64); err == nil && r > 0 {
+			rate = r
+		}
+	}
+
+	workers := defaultWorkers
+	if v := os.Getenv("FINANCE_WORKERS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			workers = n
+		}
+	}
+
+	lookahead := defaultLookaheadDays
+	if v := os.Getenv("FINANCE_LOOKAHEAD_DAYS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			lookahead = n
+		}
+	}
+
+	recheck := defaultRecheckDays
+	if v := os.Getenv("FINANCE_RECHECK_DAYS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			recheck = n
+		}
+	}
+
 	return &UpdateFinanceDataUseCase{
-		StockRepo:   stockRepo,
-		FinanceRepo: financeRepo,
-		Scraper:     scraper,
+		StockRepo:     stockRepo,
+		FinanceRepo:   financeRepo,
+		Scraper:       scraper,
+		Workers:       workers,
+		MaxAttempts:   defaultMaxAttempts,
+		Limiter:       ratelimit.NewTokenBucket(rate, 1),
+		Backoff:       baseBackoff,
+		Calendar:      domain.NewNYSECalendar(),
+		LookaheadDays: lookahead,
+		Now:           time.Now,
+		RecheckDays:   recheck,
 	}
 }
 
-func (u *UpdateFinanceDataUseCase) Execute() error {
+// Execute reparte los tickers entre los workers. Todos comparten el limitador,
+// y un 429 en cualquiera de ellos pausa el pool completo. Al cancelar ctx no
+// se despachan más tickers y se devuelve el resumen parcial.
+func (u *UpdateFinanceDataUseCase) Execute(ctx context.Context) (domain.UpdateSummary, error) {
+	var summary domain.UpdateSummary
+
 	tickers, err := u.StockRepo.GetTickersDateRange()
 	if err != nil {
-		return err
+		return summary, err
 	}
 
-	throttle := 500 * time.Millisecond
-	if v := os.Getenv("THROTTLE_MS"); v != "" {
-		if ms, err := strconv.Atoi(v); err == nil {
-			throttle = time.Duration(ms) * time.Millisecond
+	workers := u.Workers
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+
+	jobs := make(chan domain.TickerRange)
+	results := make(chan domain.TickerResult)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range jobs {
+				results <- u.processTicker(ctx, t)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for _, t := range tickers {
+			select {
+			case <-ctx.Done():
+				return
+			case jobs <- t:
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	done := 0
+	for r := range results {
+		summary.Add(r)
+		done++
+		if u.OnTicker != nil {
+			u.OnTicker(done, len(tickers), summary)
 		}
 	}
 
-	for _, t := range tickers {
-		adjustedStart := t.StartDate.Add(-1 * time.Hour)
-		log.Printf("Scrapeando %s desde %s hasta %s", t.Ticker, adjustedStart.Format("2006-01-02"), t.EndDate.Format("2006-01-02"))
+	logSummary(summary, len(tickers))
+	return summary, ctx.Err()
+}
+
+// processTicker consulta al proveedor solo los días hábiles que faltan en
+// finances entre el primer rating y LookaheadDays días hábiles después del
+// último. Los días pedidos para los que el proveedor no devolvió una vela
+// válida se registran y no se vuelven a pedir durante RecheckDays días.
+func (u *UpdateFinanceDataUseCase) processTicker(ctx context.Context, t domain.TickerRange) domain.TickerResult {
+	result := domain.TickerResult{Ticker: t.Ticker}
 
-		data, err := u.Scraper.GetHistoricalData(t.Ticker, adjustedStart, t.EndDate)
+	ranges, known, err := u.missingRanges(t)
+	if err != nil {
+		result.Err = fmt.Errorf("error calculando huecos de %s: %w", t.Ticker, err)
+		log.Print(result.Err)
+		return result
+	}
+	if len(ranges) == 0 {
+		log.Printf("%s ya está completo", t.Ticker)
+		return result
+	}
+
+	var data []domain.Finance
+	var actions []domain.CorporateAction
+	var fetched []domain.DateRange
+	for _, r := range ranges {
+		history, attempts, err := u.scrape(ctx, t.Ticker, r)
+		result.Attempts += attempts
 		if err != nil {
+			result.Err = err
 			log.Printf("Error scrapeando %s: %v", t.Ticker, err)
-		} else if len(data) == 0 {
+			break
+		}
+		fetched = append(fetched, r)
+		data = append(data, history.Prices...)
+		actions = append(actions, history.Actions...)
+	}
+
+	if len(actions) > 0 {
+		if err := u.FinanceRepo.SaveCorporateActions(actions); err != nil {
+			log.Printf("Error guardando eventos corporativos de %s: %v", t.Ticker, err)
+		}
+	}
+
+	valid, rejected := domain.SplitValid(data)
+	u.recordChecked(t.Ticker, fetched, known, valid)
+
+	if len(data) == 0 {
+		if result.Err == nil {
 			log.Printf("Sin datos para %s", t.Ticker)
+		}
+		return result
+	}
+
+	result.Rows = len(data)
+	if len(valid) > 0 {
+		saved, err := u.FinanceRepo.BulkSave(valid)
+		if err != nil {
+			// La transacción se deshizo: ninguna vela válida quedó guardada.
+			result.Err = fmt.Errorf("error guardando %s: %w", t.Ticker, err)
+			log.Print(result.Err)
+			for _, f := range valid {
+				rejected = append(rejected, domain.RejectedFinance{Finance: f, Err: err})
+			}
 		} else {
-			if err := u.FinanceRepo.BulkSave(data); err != nil {
-				log.Printf("Error guardando %s: %v", t.Ticker, err)
-			} else {
-				log.Printf("%d registros guardados para %s", len(data), t.Ticker)
+			rejected = append(rejected, saved.Rejected...)
+			result.Inserted = saved.Inserted
+			result.Updated = saved.Updated
+			log.Printf("%d registros guardados para %s", len(valid), t.Ticker)
+		}
+	}
+
+	// Cada vela rechazada cuenta una sola vez: en cuarentena o como fallida.
+	result.Quarantined = u.quarantine(t.Ticker, rejected)
+	result.Failed = len(rejected) - result.Quarantined
+	return result
+}
+
+// quarantine envía las velas rechazadas a la cuarentena y devuelve cuántas
+// quedaron registradas.
+func (u *UpdateFinanceDataUseCase) quarantine(ticker string, rejected []domain.RejectedFinance) int {
+	if len(rejected) == 0 {
+		return 0
+	}
+	if err := u.FinanceRepo.QuarantineFinances(u.Scraper.Name(), rejected); err != nil {
+		log.Printf("Error enviando %d velas de %s a cuarentena: %v", len(rejected), ticker, err)
+		return 0
+	}
+	log.Printf("%d velas de %s enviadas a cuarentena", len(rejected), ticker)
+	return len(rejected)
+}
+
+// missingRanges devuelve los rangos a pedir y los días que no hace falta
+// pedir: los que ya están en finances y los consultados sin éxito hace menos
+// de RecheckDays días. No se piden días anteriores al inicio del calendario.
+func (u *UpdateFinanceDataUseCase) missingRanges(t domain.TickerRange) ([]domain.DateRange, map[string]bool, error) {
+	from := domain.Day(t.StartDate)
+	if since := u.Calendar.Since(); from.Before(since) {
+		from = since
+	}
+	to := u.Calendar.AddTradingDays(t.EndDate, u.LookaheadDays)
+	if yesterday := domain.Day(u.Now()).AddDate(0, 0, -1); to.After(yesterday) {
+		to = yesterday
+	}
+	if to.Before(from) {
+		return nil, nil, nil
+	}
+
+	dates, err := u.FinanceRepo.GetDates(t.Ticker, from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	checked, err := u.FinanceRepo.GetCheckedDates(t.Ticker, from, to, u.Now().AddDate(0, 0, -u.RecheckDays))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	known := make(map[string]bool, len(dates)+len(checked))
+	for _, d := range append(dates, checked...) {
+		known[d.Format("2006-01-02")] = true
+	}
+
+	ranges := domain.MissingRanges(u.Calendar.TradingDays(from, to), known)
+	if len(ranges) > maxRangesPerTicker {
+		ranges = []domain.DateRange{{From: ranges[0].From, To: ranges[len(ranges)-1].To}}
+	}
+	return ranges, known, nil
+}
+
+// recordChecked registra los días hábiles pedidos al proveedor que no
+// estaban en known y para los que no llegó una vela válida.
+func (u *UpdateFinanceDataUseCase) recordChecked(ticker string, fetched []domain.DateRange, known map[string]bool, valid []domain.Finance) {
+	received := make(map[string]bool, len(valid))
+	for _, f := range valid {
+		received[f.Date.Format("2006-01-02")] = true
+	}
+
+	var empty []time.Time
+	for _, r := range fetched {
+		for _, d := range u.Calendar.TradingDays(r.From, r.To) {
+			key := d.Format("2006-01-02")
+			if !known[key] && !received[key] {
+				empty = append(empty, d)
 			}
 		}
+	}
+	if len(empty) == 0 {
+		return
+	}
+
+	if err := u.FinanceRepo.SaveCheckedDates(ticker, u.Scraper.Name(), empty); err != nil {
+		log.Printf("Error registrando %d días sin datos de %s: %v", len(empty), ticker, err)
+		return
+	}
+	log.Printf("%d días hábiles sin datos válidos para %s, no se vuelven a pedir durante %d días", len(empty), ticker, u.RecheckDays)
+}
+
+// scrape pide un rango al proveedor y reintenta tras pausar el pool ante un 429.
+func (u *UpdateFinanceDataUseCase) scrape(ctx context.Context, ticker string, r domain.DateRange) (domain.PriceHistory, int, error) {
+	maxAttempts := max(u.MaxAttempts, 1)
+	// period2 de Yahoo es exclusivo, se pide hasta el día siguiente.
+	from, to := r.From, r.To.AddDate(0, 0, 1)
+
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if err := u.Limiter.Wait(ctx); err != nil {
+			return domain.PriceHistory{}, attempt - 1, err
+		}
+
+		log.Printf("Scrapeando %s desde %s hasta %s", ticker, r.From.Format("2006-01-02"), r.To.Format("2006-01-02"))
+
+		var history domain.PriceHistory
+		history, err = u.Scraper.GetHistoricalData(ctx, ticker, from, to)
+		if errors.Is(err, domain.ErrRateLimited) {
+			u.backoff(ticker)
+			continue
+		}
+		u.resetBackoff()
+		return history, attempt, err
+	}
 
-		time.Sleep(throttle)
+	return domain.PriceHistory{}, maxAttempts, err
+}
+
+// backoff pausa el limitador compartido; la espera se duplica con cada 429
+// consecutivo del pool.
+func (u *UpdateFinanceDataUseCase) backoff(ticker string) {
+	base := u.Backoff
+	if base <= 0 {
+		base = baseBackoff
+	}
+
+	u.mu.Lock()
+	u.consecutive429++
+	delay := base << (u.consecutive429 - 1)
+	u.mu.Unlock()
+
+	if delay > maxBackoff || delay <= 0 {
+		delay = maxBackoff
+	}
+	log.Printf("429 para %s, pausando todos los workers %v...", ticker, delay)
+	u.Limiter.PauseFor(delay)
+}
+
+func (u *UpdateFinanceDataUseCase) resetBackoff() {
+	u.mu.Lock()
+	u.consecutive429 = 0
+	u.mu.Unlock()
+}
+
+func logSummary(summary domain.UpdateSummary, total int) {
+	for _, r := range summary.Results {
+		if r.Err != nil {
+			log.Printf("❌ %s: %v (intentos: %d)", r.Ticker, r.Err, r.Attempts)
+		} else {
+			log.Printf("✅ %s: %d filas (%d nuevas, %d actualizadas, %d fallidas)", r.Ticker, r.Rows, r.Inserted, r.Updated, r.Failed)
+		}
 	}
-	return nil
+	log.Printf("Resumen: %d/%d tickers procesados, %d exitosos, %d fallidos, %d filas insertadas, %d actualizadas, %d fallidas, %d en cuarentena",
+		summary.TickersProcessed, total, summary.TickersSucceeded, summary.TickersFailed,
+		summary.RowsInserted, summary.RowsUpdated, summary.RowsFailed, summary.RowsQuarantined)
 }
@@ -0,0 +1,262 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viteant/stockinsight/internal/finance/domain"
+	"github.com/viteant/stockinsight/internal/ratelimit"
+)
+
+var (
+	rangeStart = time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	rangeEnd   = time.Date(2025, 3, 7, 0, 0, 0, 0, time.UTC)
+)
+
+type fakeTickers struct {
+	ranges []domain.TickerRange
+}
+
+func (f *fakeTickers) GetTickersDateRange() ([]domain.TickerRange, error) {
+	return f.ranges, nil
+}
+
+// fakeScraper devuelve, por ticker, los errores de errs en orden y después
+// una vela con el cierre de closes (1 si no está).
+type fakeScraper struct {
+	mu     sync.Mutex
+	errs   map[string][]error
+	closes map[string]float32
+	calls  []time.Time
+}
+
+func (f *fakeScraper) Name() string { return "fake" }
+
+func (f *fakeScraper) GetHistoricalData(ctx context.Context, ticker string, from, to time.Time) (domain.PriceHistory, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, time.Now())
+
+	if errs := f.errs[ticker]; len(errs) > 0 {
+		f.errs[ticker] = errs[1:]
+		return domain.PriceHistory{}, errs[0]
+	}
+	closePrice, ok := f.closes[ticker]
+	if !ok {
+		closePrice = 1
+	}
+	return domain.PriceHistory{Prices: []domain.Finance{{Ticker: ticker, Date: from, Close: closePrice}}}, nil
+}
+
+type fakeFinances struct {
+	mu          sync.Mutex
+	failSave    map[string]bool
+	saved       []domain.Finance
+	quarantined []domain.RejectedFinance
+}
+
+func (f *fakeFinances) BulkSave(data []domain.Finance) (domain.SaveResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failSave[data[0].Ticker] {
+		return domain.SaveResult{}, errors.New("db caída")
+	}
+	f.saved = append(f.saved, data...)
+	return domain.SaveResult{Inserted: len(data)}, nil
+}
+
+func (f *fakeFinances) GetDates(ticker string, from, to time.Time) ([]time.Time, error) {
+	return nil, nil
+}
+
+func (f *fakeFinances) GetCheckedDates(ticker string, from, to, since time.Time) ([]time.Time, error) {
+	return nil, nil
+}
+
+func (f *fakeFinances) SaveCheckedDates(ticker, source string, dates []time.Time) error {
+	return nil
+}
+
+func (f *fakeFinances) SaveCorporateActions(actions []domain.CorporateAction) error {
+	return nil
+}
+
+func (f *fakeFinances) GetPrices(ticker string, from, to time.Time) ([]domain.Finance, error) {
+	return nil, nil
+}
+
+func (f *fakeFinances) QuarantineFinances(source string, rejected []domain.RejectedFinance) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.quarantined = append(f.quarantined, rejected...)
+	return nil
+}
+
+func newTestUseCase(scraper *fakeScraper, finances *fakeFinances, tickers ...string) *UpdateFinanceDataUseCase {
+	ranges := make([]domain.TickerRange, len(tickers))
+	for i, t := range tickers {
+		ranges[i] = domain.TickerRange{Ticker: t, StartDate: rangeStart, EndDate: rangeEnd}
+	}
+	if scraper.errs == nil {
+		scraper.errs = map[string][]error{}
+	}
+
+	return &UpdateFinanceDataUseCase{
+		StockRepo:   &fakeTickers{ranges: ranges},
+		FinanceRepo: finances,
+		Scraper:     scraper,
+		Workers:     4,
+		MaxAttempts: 3,
+		Limiter:     ratelimit.NewTokenBucket(1000, 1),
+		Backoff:     time.Millisecond,
+		Calendar:    domain.NewNYSECalendar(),
+		Now:         func() time.Time { return time.Date(2025, 3, 20, 0, 0, 0, 0, time.UTC) },
+	}
+}
+
+func resultsByTicker(summary domain.UpdateSummary) map[string]domain.TickerResult {
+	out := make(map[string]domain.TickerResult, len(summary.Results))
+	for _, r := range summary.Results {
+		out[r.Ticker] = r
+	}
+	return out
+}
+
+func TestExecute_IsolatesTickerFailures(t *testing.T) {
+	cases := []struct {
+		ticker      string
+		errs        []error
+		closePrice  float32
+		failSave    bool
+		wantErr     error
+		wantAttempt int
+		wantResult  domain.TickerResult
+	}{
+		{ticker: "OK", wantAttempt: 1, wantResult: domain.TickerResult{Rows: 1, Inserted: 1}},
+		{ticker: "ERR", errs: []error{errors.New("timeout")}, wantErr: errors.New("timeout"), wantAttempt: 1},
+		{ticker: "RETRY", errs: []error{domain.ErrRateLimited, domain.ErrRateLimited}, wantAttempt: 3, wantResult: domain.TickerResult{Rows: 1, Inserted: 1}},
+		{ticker: "LIMIT", errs: []error{domain.ErrRateLimited, domain.ErrRateLimited, domain.ErrRateLimited}, wantErr: domain.ErrRateLimited, wantAttempt: 3},
+		{ticker: "BAD", closePrice: -1, wantAttempt: 1, wantResult: domain.TickerResult{Rows: 1, Quarantined: 1}},
+		{ticker: "SAVE", failSave: true, wantErr: errors.New("db caída"), wantAttempt: 1, wantResult: domain.TickerResult{Rows: 1, Quarantined: 1}},
+	}
+
+	scraper := &fakeScraper{errs: map[string][]error{}, closes: map[string]float32{}}
+	finances := &fakeFinances{failSave: map[string]bool{}}
+	var tickers []string
+	for _, c := range cases {
+		tickers = append(tickers, c.ticker)
+		scraper.errs[c.ticker] = c.errs
+		if c.closePrice != 0 {
+			scraper.closes[c.ticker] = c.closePrice
+		}
+		finances.failSave[c.ticker] = c.failSave
+	}
+
+	summary, err := newTestUseCase(scraper, finances, tickers...).Execute(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, len(cases), summary.TickersProcessed)
+	assert.Equal(t, 3, summary.TickersSucceeded)
+	assert.Equal(t, 3, summary.TickersFailed)
+	assert.Equal(t, 2, summary.RowsInserted)
+	assert.Equal(t, 2, summary.RowsQuarantined)
+	assert.Equal(t, 0, summary.RowsFailed)
+
+	got := resultsByTicker(summary)
+	for _, c := range cases {
+		r := got[c.ticker]
+		assert.Equal(t, c.wantAttempt, r.Attempts, c.ticker)
+		if c.wantErr != nil {
+			assert.ErrorContains(t, r.Err, c.wantErr.Error(), c.ticker)
+		} else {
+			assert.NoError(t, r.Err, c.ticker)
+		}
+		r.Ticker, r.Err, r.Attempts = "", nil, 0
+		assert.Equal(t, c.wantResult, r, c.ticker)
+	}
+}
+
+func TestExecute_SharesTheLimiterAcrossWorkers(t *testing.T) {
+	const rate = 50.0
+	scraper := &fakeScraper{}
+	u := newTestUseCase(scraper, &fakeFinances{}, "A", "B", "C", "D", "E", "F")
+	u.Workers = 4
+	u.Limiter = ratelimit.NewTokenBucket(rate, 1)
+
+	summary, err := u.Execute(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 6, summary.TickersSucceeded)
+
+	// Con un solo limitador, cuatro workers no superan la tasa: entre dos
+	// pedidos consecutivos pasa al menos un intervalo, con margen por el reloj.
+	calls := slices.Clone(scraper.calls)
+	slices.SortFunc(calls, func(a, b time.Time) int { return a.Compare(b) })
+	interval := time.Duration(float64(time.Second) / rate)
+	for i := 1; i < len(calls); i++ {
+		assert.GreaterOrEqual(t, calls[i].Sub(calls[i-1]), interval*8/10, "pedido %d", i)
+	}
+}
+
+func TestBackoff_PausesThePoolAndResets(t *testing.T) {
+	u := newTestUseCase(&fakeScraper{}, &fakeFinances{})
+	u.Backoff = 20 * time.Millisecond
+
+	cases := []struct {
+		name    string
+		reset   bool
+		atLeast time.Duration
+		atMost  time.Duration
+	}{
+		{name: "primer 429", atLeast: 10 * time.Millisecond, atMost: 20 * time.Millisecond},
+		{name: "segundo 429 duplica", atLeast: 30 * time.Millisecond, atMost: 40 * time.Millisecond},
+		{name: "tras un pedido exitoso vuelve a la base", reset: true, atLeast: 10 * time.Millisecond, atMost: 20 * time.Millisecond},
+	}
+	for _, c := range cases {
+		if c.reset {
+			u.resetBackoff()
+		}
+		u.Limiter = ratelimit.NewTokenBucket(1000, 1)
+		u.backoff("AAPL")
+
+		// La pausa es del limitador compartido: ningún worker obtiene token.
+		ok, _, wait := u.Limiter.Allow()
+		assert.False(t, ok, c.name)
+		assert.Greater(t, wait, c.atLeast, c.name)
+		assert.LessOrEqual(t, wait, c.atMost, c.name)
+	}
+
+	u.consecutive429 = 20
+	u.Limiter = ratelimit.NewTokenBucket(1000, 1)
+	u.backoff("AAPL")
+	_, _, wait := u.Limiter.Allow()
+	assert.LessOrEqual(t, wait, maxBackoff)
+	assert.Greater(t, wait, maxBackoff-time.Second)
+}
+
+func TestExecute_StopsDispatchingOnCancel(t *testing.T) {
+	scraper := &fakeScraper{}
+	u := newTestUseCase(scraper, &fakeFinances{}, "A", "B", "C", "D", "E", "F")
+	u.Workers = 1
+
+	ctx, cancel := context.WithCancel(context.Background())
+	u.OnTicker = func(done, total int, summary domain.UpdateSummary) {
+		if done == 2 {
+			cancel()
+		}
+	}
+
+	summary, err := u.Execute(ctx)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	// El ticker que el worker ya había tomado puede terminar; no se despachan
+	// más.
+	assert.GreaterOrEqual(t, summary.TickersProcessed, 2)
+	assert.LessOrEqual(t, summary.TickersProcessed, 3)
+	assert.LessOrEqual(t, len(scraper.calls), summary.TickersProcessed)
+}
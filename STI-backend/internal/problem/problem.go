@@ -0,0 +1,136 @@
+// Package problem arma las respuestas de error de la API con el formato
+// application/problem+json de RFC 7807.
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"maps"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	ContentType = "application/problem+json"
+
+	// TypeValidation identifica los errores de parámetros o cuerpo inválidos;
+	// el detalle por campo va en errors.
+	TypeValidation = "/problems/validation"
+
+	internalDetail = "error interno del servidor"
+)
+
+// FieldError describe por qué un parámetro no pasó la validación.
+type FieldError struct {
+	Field  string `json:"field"`
+	Value  string `json:"value,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// Problem es el cuerpo de una respuesta de error. Extensions agrega miembros
+// propios del endpoint al mismo nivel que los estándar.
+type Problem struct {
+	Type       string         `json:"type"`
+	Title      string         `json:"title"`
+	Status     int            `json:"status"`
+	Detail     string         `json:"detail,omitempty"`
+	Instance   string         `json:"instance,omitempty"`
+	Errors     []FieldError   `json:"errors,omitempty"`
+	Extensions map[string]any `json:"-" swaggerignore:"true"`
+}
+
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type plain Problem
+	base, err := json.Marshal(plain(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return base, err
+	}
+
+	merged := maps.Clone(p.Extensions)
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(base, &fields); err != nil {
+		return nil, err
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return json.Marshal(merged)
+}
+
+// New crea un problema sin tipo específico (about:blank).
+func New(status int, title, detail string) Problem {
+	return Problem{Type: "about:blank", Title: title, Status: status, Detail: detail}
+}
+
+// Validation crea un problema 400 con los errores de cada campo.
+func Validation(errs ...FieldError) Problem {
+	return Problem{
+		Type:   TypeValidation,
+		Title:  "Invalid request parameters",
+		Status: fiber.StatusBadRequest,
+		Detail: "uno o más parámetros no son válidos",
+		Errors: errs,
+	}
+}
+
+// With devuelve una copia del problema con un miembro de extensión.
+func (p Problem) With(key string, value any) Problem {
+	ext := make(map[string]any, len(p.Extensions)+1)
+	maps.Copy(ext, p.Extensions)
+	ext[key] = value
+	p.Extensions = ext
+	return p
+}
+
+// Send responde con el problema; instance es el path del pedido.
+func Send(c *fiber.Ctx, p Problem) error {
+	if p.Instance == "" {
+		p.Instance = c.Path()
+	}
+	body, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderContentType, ContentType)
+	return c.Status(p.Status).Send(body)
+}
+
+// Write responde con un problema sin tipo específico.
+func Write(c *fiber.Ctx, status int, title, detail string) error {
+	return Send(c, New(status, title, detail))
+}
+
+// Invalid responde 400 con los errores de cada campo.
+func Invalid(c *fiber.Ctx, errs ...FieldError) error {
+	return Send(c, Validation(errs...))
+}
+
+// Internal registra err en el log y responde 500 sin exponerlo: los errores
+// de la base o de proveedores externos pueden incluir consultas o URLs.
+func Internal(c *fiber.Ctx, title string, err error) error {
+	log.Printf("%s %s: %s: %v", c.Method(), c.Path(), title, err)
+	return Write(c, fiber.StatusInternalServerError, title, internalDetail)
+}
+
+// ErrorHandler es el manejador de errores de Fiber: las rutas inexistentes,
+// los métodos no permitidos y los errores no atendidos por los handlers
+// también responden con el formato de problema.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	title := "Internal server error"
+	detail := ""
+
+	var fe *fiber.Error
+	if errors.As(err, &fe) {
+		status = fe.Code
+		title = fe.Message
+		if status >= fiber.StatusInternalServerError {
+			title = "Internal server error"
+		}
+	}
+	if status >= fiber.StatusInternalServerError {
+		detail = internalDetail
+	}
+	return Write(c, status, title, detail)
+}
@@ -0,0 +1,81 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func decode(t *testing.T, app *fiber.App, path string) (int, string, map[string]any) {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest("GET", path, nil))
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+
+	var got map[string]any
+	assert.NoError(t, json.Unmarshal(body, &got))
+	return resp.StatusCode, resp.Header.Get(fiber.HeaderContentType), got
+}
+
+func TestInvalid(t *testing.T) {
+	app := fiber.New()
+	app.Get("/stocks", func(c *fiber.Ctx) error {
+		return Invalid(c, FieldError{Field: "limit", Value: "0", Reason: "debe estar entre 1 y 100"})
+	})
+
+	status, contentType, got := decode(t, app, "/stocks?limit=0")
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	assert.Equal(t, ContentType, contentType)
+	assert.Equal(t, TypeValidation, got["type"])
+	assert.Equal(t, float64(400), got["status"])
+	assert.Equal(t, "/stocks", got["instance"])
+	assert.Equal(t, []any{map[string]any{"field": "limit", "value": "0", "reason": "debe estar entre 1 y 100"}}, got["errors"])
+}
+
+func TestExtensions(t *testing.T) {
+	app := fiber.New()
+	app.Get("/records/1/retry", func(c *fiber.Ctx) error {
+		p := New(fiber.StatusUnprocessableEntity, "Replay failed", "sigue siendo inválido").With("record", map[string]string{"id": "1"})
+		return Send(c, p)
+	})
+
+	status, _, got := decode(t, app, "/records/1/retry")
+	assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+	assert.Equal(t, "about:blank", got["type"])
+	assert.Equal(t, "Replay failed", got["title"])
+	assert.Equal(t, map[string]any{"id": "1"}, got["record"])
+	assert.NotContains(t, got, "errors")
+}
+
+func TestInternal(t *testing.T) {
+	app := fiber.New()
+	app.Get("/consensus", func(c *fiber.Ctx) error {
+		return Internal(c, "Error fetching consensus", errors.New(`pq: relation "broker_scores" does not exist`))
+	})
+
+	status, _, got := decode(t, app, "/consensus")
+	assert.Equal(t, fiber.StatusInternalServerError, status)
+	assert.Equal(t, "Error fetching consensus", got["title"])
+	assert.Equal(t, "error interno del servidor", got["detail"])
+}
+
+func TestErrorHandler(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Get("/boom", func(c *fiber.Ctx) error {
+		return assert.AnError
+	})
+
+	status, contentType, got := decode(t, app, "/no-existe")
+	assert.Equal(t, fiber.StatusNotFound, status)
+	assert.Equal(t, ContentType, contentType)
+	assert.Equal(t, "Cannot GET /no-existe", got["title"])
+
+	status, _, got = decode(t, app, "/boom")
+	assert.Equal(t, fiber.StatusInternalServerError, status)
+	assert.Equal(t, "Internal server error", got["title"])
+}
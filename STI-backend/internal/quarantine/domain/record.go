@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	KindStock   = "stock"
+	KindFinance = "finance"
+
+	StatusPending   = "pending"
+	StatusResolved  = "resolved"
+	StatusDiscarded = "discarded"
+)
+
+var (
+	ErrRecordNotFound   = errors.New("quarantined record not found")
+	ErrRecordNotPending = errors.New("quarantined record is not pending")
+	ErrUnknownKind      = errors.New("unknown quarantine kind")
+	ErrReplayFailed     = errors.New("replay of quarantined record failed")
+)
+
+var (
+	Kinds    = []string{KindStock, KindFinance}
+	Statuses = []string{StatusPending, StatusResolved, StatusDiscarded}
+)
+
+// Record es un registro de ingesta rechazado. Payload es el registro original
+// (un domain.RawStock de stock o un domain.Finance de finance) y Errors, si el
+// rechazo fue por validación, la lista de campos inválidos.
+type Record struct {
+	ID         string          `json:"id"`
+	Kind       string          `json:"kind"`
+	Source     string          `json:"source"`
+	Payload    json.RawMessage `json:"payload" swaggertype:"object"`
+	Reason     string          `json:"reason"`
+	Errors     json.RawMessage `json:"errors,omitempty" swaggertype:"array,object"`
+	Status     string          `json:"status"`
+	Attempts   int             `json:"attempts"`
+	LastError  string          `json:"last_error,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	LastSeenAt time.Time       `json:"last_seen_at"`
+	ResolvedAt *time.Time      `json:"resolved_at"`
+
+	// Fingerprint identifica el registro dentro de su tipo; si está vacío se
+	// usa un hash del payload.
+	Fingerprint string `json:"-"`
+}
+
+type Filter struct {
+	Kind   string
+	Status string
+	Limit  int
+}
+
+type QuarantineRepository interface {
+	Add(records []Record) error
+	List(filter Filter) ([]Record, error)
+	GetByID(id string) (Record, error)
+	// SetStatus cambia el estado de un registro pendiente.
+	SetStatus(id, status string) (Record, error)
+	RecordFailedAttempt(id string, cause error) (Record, error)
+}
+
+// ReplaySummary resume una reinyección de registros en cuarentena.
+type ReplaySummary struct {
+	Processed int
+	Resolved  int
+	Failed    int
+}
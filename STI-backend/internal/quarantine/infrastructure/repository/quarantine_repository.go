@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/viteant/stockinsight/internal/quarantine/domain"
+)
+
+type PersistenceQuarantineRepository struct {
+	DB *sql.DB
+}
+
+func NewCockroachQuarantineRepository(db *sql.DB) *PersistenceQuarantineRepository {
+	return &PersistenceQuarantineRepository{DB: db}
+}
+
+// Add guarda los registros rechazados. Un mismo registro (según Fingerprint)
+// se guarda una sola vez por tipo; si vuelve a llegar se actualizan payload,
+// motivo y last_seen_at, y si ya estaba resuelto vuelve a quedar pendiente.
+func (r *PersistenceQuarantineRepository) Add(records []domain.Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	const columns = 6
+
+	seen := make(map[string]bool, len(records))
+	values := make([]string, 0, len(records))
+	args := make([]interface{}, 0, len(records)*columns)
+	for _, rec := range records {
+		fingerprint := rec.Fingerprint
+		if fingerprint == "" {
+			fingerprint = Fingerprint(rec.Payload)
+		}
+		if seen[rec.Kind+fingerprint] {
+			continue
+		}
+		seen[rec.Kind+fingerprint] = true
+
+		p := len(values) * columns
+		values = append(values, fmt.Sprintf(
+			"($%d, $%d, $%d, $%d::JSONB, $%d, $%d::JSONB)",
+			p+1, p+2, p+3, p+4, p+5, p+6,
+		))
+		args = append(args, rec.Kind, rec.Source, fingerprint, string(rec.Payload), rec.Reason, nullJSON(rec.Errors))
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO quarantined_records (kind, source, fingerprint, payload, reason, errors)
+		VALUES %s
+		ON CONFLICT (kind, fingerprint) DO UPDATE SET
+			source = excluded.source,
+			payload = excluded.payload,
+			reason = excluded.reason,
+			errors = excluded.errors,
+			last_seen_at = now(),
+			status = CASE WHEN quarantined_records.status = '%s'
+				THEN '%s' ELSE quarantined_records.status END
+	`, strings.Join(values, ", "), domain.StatusResolved, domain.StatusPending)
+
+	_, err := r.DB.Exec(query, args...)
+	return err
+}
+
+// Fingerprint es el hash de un payload.
+func Fingerprint(payload []byte) string {
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+const selectRecordColumns = `
+	SELECT id, kind, source, payload::STRING, reason, errors::STRING, status,
+	       attempts, last_error, created_at, last_seen_at, resolved_at
+	FROM quarantined_records
+`
+
+func (r *PersistenceQuarantineRepository) List(filter domain.Filter) ([]domain.Record, error) {
+	rows, err := r.DB.Query(selectRecordColumns+`
+		WHERE ($1 = '' OR kind = $1) AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC
+		LIMIT $3
+	`, filter.Kind, filter.Status, filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []domain.Record{}
+	for rows.Next() {
+		rec, err := scanRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+	return records, rows.Err()
+}
+
+func (r *PersistenceQuarantineRepository) GetByID(id string) (domain.Record, error) {
+	rec, err := scanRecord(r.DB.QueryRow(selectRecordColumns+`WHERE id::STRING = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return rec, domain.ErrRecordNotFound
+	}
+	return rec, err
+}
+
+func (r *PersistenceQuarantineRepository) SetStatus(id, status string) (domain.Record, error) {
+	res, err := r.DB.Exec(`
+		UPDATE quarantined_records SET
+			status = $2,
+			resolved_at = now()
+		WHERE id::STRING = $1 AND status = $3
+	`, id, status, domain.StatusPending)
+	if err != nil {
+		return domain.Record{}, err
+	}
+	return r.afterUpdate(id, res)
+}
+
+func (r *PersistenceQuarantineRepository) RecordFailedAttempt(id string, cause error) (domain.Record, error) {
+	res, err := r.DB.Exec(`
+		UPDATE quarantined_records SET
+			attempts = attempts + 1,
+			last_error = $2
+		WHERE id::STRING = $1 AND status = $3
+	`, id, cause.Error(), domain.StatusPending)
+	if err != nil {
+		return domain.Record{}, err
+	}
+	return r.afterUpdate(id, res)
+}
+
+// afterUpdate devuelve el registro actualizado, o distingue si no existe o si
+// ya no estaba pendiente.
+func (r *PersistenceQuarantineRepository) afterUpdate(id string, res sql.Result) (domain.Record, error) {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return domain.Record{}, err
+	}
+
+	rec, err := r.GetByID(id)
+	if err != nil {
+		return rec, err
+	}
+	if affected == 0 {
+		return rec, domain.ErrRecordNotPending
+	}
+	return rec, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanRecord(row rowScanner) (domain.Record, error) {
+	var rec domain.Record
+	var payload string
+	var recErrors, lastError sql.NullString
+	var resolvedAt sql.NullTime
+
+	err := row.Scan(
+		&rec.ID,
+		&rec.Kind,
+		&rec.Source,
+		&payload,
+		&rec.Reason,
+		&recErrors,
+		&rec.Status,
+		&rec.Attempts,
+		&lastError,
+		&rec.CreatedAt,
+		&rec.LastSeenAt,
+		&resolvedAt,
+	)
+	if err != nil {
+		return rec, err
+	}
+
+	rec.Payload = []byte(payload)
+	if recErrors.Valid {
+		rec.Errors = []byte(recErrors.String)
+	}
+	rec.LastError = lastError.String
+	if resolvedAt.Valid {
+		rec.ResolvedAt = &resolvedAt.Time
+	}
+	return rec, nil
+}
+
+func nullJSON(b []byte) sql.NullString {
+	return sql.NullString{String: string(b), Valid: len(b) > 0}
+}
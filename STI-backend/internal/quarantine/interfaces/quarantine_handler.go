@@ -0,0 +1,155 @@
+package interfaces
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/viteant/stockinsight/internal/problem"
+	"github.com/viteant/stockinsight/internal/quarantine/domain"
+	"github.com/viteant/stockinsight/internal/quarantine/use_cases"
+)
+
+const (
+	defaultQuarantineLimit = 50
+	maxQuarantineLimit     = 500
+)
+
+type QuarantineHandler struct {
+	useCase *use_cases.QuarantineService
+}
+
+func NewQuarantineHandler(useCase *use_cases.QuarantineService) *QuarantineHandler {
+	return &QuarantineHandler{
+		useCase: useCase,
+	}
+}
+
+// ListRecords godoc
+// @Summary Registros en cuarentena
+// @Description Devuelve los ratings y velas rechazados en la ingesta, con el payload original, la fuente y el motivo, del más reciente al más antiguo.
+// @Tags Quarantine
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param kind query string false "Filtra por tipo (stock o finance)"
+// @Param status query string false "Filtra por estado (pending, resolved o discarded)"
+// @Param limit query int false "Cantidad de registros (default: 50, máximo: 500)"
+// @Success 200 {array} domain.Record
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 429 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /api/quarantine [get]
+func (h *QuarantineHandler) ListRecords(c *fiber.Ctx) error {
+	kind, status := c.Query("kind"), c.Query("status")
+	if kind != "" && !slices.Contains(domain.Kinds, kind) {
+		return problem.Invalid(c, problem.FieldError{Field: "kind", Value: kind, Reason: "debe ser stock o finance"})
+	}
+	if status != "" && !slices.Contains(domain.Statuses, status) {
+		return problem.Invalid(c, problem.FieldError{Field: "status", Value: status, Reason: "debe ser pending, resolved o discarded"})
+	}
+
+	limit, err := strconv.Atoi(c.Query("limit", strconv.Itoa(defaultQuarantineLimit)))
+	if err != nil || limit <= 0 {
+		limit = defaultQuarantineLimit
+	}
+	if limit > maxQuarantineLimit {
+		limit = maxQuarantineLimit
+	}
+
+	records, err := h.useCase.List(domain.Filter{Kind: kind, Status: status, Limit: limit})
+	if err != nil {
+		return problem.Write(c, fiber.StatusInternalServerError, "Error fetching quarantined records", "")
+	}
+	return c.JSON(records)
+}
+
+// GetRecord godoc
+// @Summary Detalle de un registro en cuarentena
+// @Tags Quarantine
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param id path string true "ID del registro"
+// @Success 200 {object} domain.Record
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 429 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /api/quarantine/{id} [get]
+func (h *QuarantineHandler) GetRecord(c *fiber.Ctx) error {
+	rec, err := h.useCase.Get(c.Params("id"))
+	if err != nil {
+		return recordError(c, err)
+	}
+	return c.JSON(rec)
+}
+
+// RetryRecord godoc
+// @Summary Reintentar un registro en cuarentena
+// @Description Vuelve a validar y guardar un registro pendiente. Si se guarda queda resolved; si no, sigue pending con el intento y el error registrados.
+// @Tags Quarantine
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param id path string true "ID del registro"
+// @Success 200 {object} domain.Record
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Failure 422 {object} problem.Problem
+// @Failure 429 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /api/quarantine/{id}/retry [post]
+func (h *QuarantineHandler) RetryRecord(c *fiber.Ctx) error {
+	rec, err := h.useCase.Retry(c.Params("id"))
+	if errors.Is(err, domain.ErrReplayFailed) {
+		p := problem.New(fiber.StatusUnprocessableEntity, "Replay failed", err.Error()).With("record", rec)
+		return problem.Send(c, p)
+	}
+	if err != nil {
+		return recordError(c, err)
+	}
+	return c.JSON(rec)
+}
+
+// DiscardRecord godoc
+// @Summary Descartar un registro en cuarentena
+// @Description Marca un registro pendiente como discarded; no se vuelve a reintentar.
+// @Tags Quarantine
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param id path string true "ID del registro"
+// @Success 200 {object} domain.Record
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Failure 429 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /api/quarantine/{id}/discard [post]
+func (h *QuarantineHandler) DiscardRecord(c *fiber.Ctx) error {
+	rec, err := h.useCase.Discard(c.Params("id"))
+	if err != nil {
+		return recordError(c, err)
+	}
+	return c.JSON(rec)
+}
+
+func recordError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrRecordNotFound):
+		return problem.Write(c, fiber.StatusNotFound, "Quarantined record not found", "")
+	case errors.Is(err, domain.ErrRecordNotPending):
+		return problem.Write(c, fiber.StatusConflict, "Quarantined record is not pending", "solo se pueden reintentar o descartar registros pending")
+	}
+	return problem.Write(c, fiber.StatusInternalServerError, "Error updating quarantined record", "")
+}
@@ -0,0 +1,37 @@
+package interfaces
+
+import (
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/viteant/stockinsight/internal/db"
+	"github.com/viteant/stockinsight/internal/quarantine/domain"
+	"github.com/viteant/stockinsight/internal/quarantine/use_cases"
+)
+
+// RunReplay reintenta los registros pendientes en cuarentena. kind es stock,
+// finance o all.
+func RunReplay(kind string) error {
+	if kind == "all" {
+		kind = ""
+	} else if !slices.Contains(domain.Kinds, kind) {
+		return fmt.Errorf("%w: %s (usa stock, finance o all)", domain.ErrUnknownKind, kind)
+	}
+
+	dbConn := db.NewCockroachDB()
+	defer dbConn.Close()
+
+	service, err := newQuarantineService(dbConn)
+	if err != nil {
+		return err
+	}
+	summary, err := service.Replay(kind, use_cases.DefaultReplayLimit)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Reinyección completada: %d procesados, %d resueltos, %d siguen en cuarentena",
+		summary.Processed, summary.Resolved, summary.Failed)
+	return nil
+}
@@ -0,0 +1,39 @@
+package interfaces
+
+import (
+	"database/sql"
+
+	"github.com/gofiber/fiber/v2"
+	financerepository "github.com/viteant/stockinsight/internal/finance/infrastructure/repository"
+	"github.com/viteant/stockinsight/internal/quarantine/infrastructure/repository"
+	"github.com/viteant/stockinsight/internal/quarantine/use_cases"
+	stockrepository "github.com/viteant/stockinsight/internal/stock/infrastructure/repository"
+)
+
+// RegisterQuarantineRoutes registra los endpoints de cuarentena. requireAdmin
+// protege los que modifican registros.
+func RegisterQuarantineRoutes(app fiber.Router, db *sql.DB, requireAdmin fiber.Handler) error {
+	service, err := newQuarantineService(db)
+	if err != nil {
+		return err
+	}
+	quarantineHandler := NewQuarantineHandler(service)
+
+	app.Get("/quarantine", quarantineHandler.ListRecords)
+	app.Get("/quarantine/:id", quarantineHandler.GetRecord)
+	app.Post("/quarantine/:id/retry", requireAdmin, quarantineHandler.RetryRecord)
+	app.Post("/quarantine/:id/discard", requireAdmin, quarantineHandler.DiscardRecord)
+	return nil
+}
+
+func newQuarantineService(db *sql.DB) (*use_cases.QuarantineService, error) {
+	stocks, err := stockrepository.NewCockroachStockRepository(db)
+	if err != nil {
+		return nil, err
+	}
+	return &use_cases.QuarantineService{
+		Repo:     repository.NewCockroachQuarantineRepository(db),
+		Stocks:   stocks,
+		Finances: financerepository.NewCockroachFinanceRepository(db),
+	}, nil
+}
@@ -0,0 +1,118 @@
+package use_cases
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	financedomain "github.com/viteant/stockinsight/internal/finance/domain"
+	"github.com/viteant/stockinsight/internal/quarantine/domain"
+	stockdomain "github.com/viteant/stockinsight/internal/stock/domain"
+)
+
+// DefaultReplayLimit es la cantidad máxima de registros que reinyecta Replay.
+const DefaultReplayLimit = 1000
+
+type StockSaver interface {
+	Save(stock stockdomain.Stock) (bool, error)
+}
+
+type FinanceSaver interface {
+	BulkSave(data []financedomain.Finance) (financedomain.SaveResult, error)
+}
+
+type QuarantineService struct {
+	Repo     domain.QuarantineRepository
+	Stocks   StockSaver
+	Finances FinanceSaver
+}
+
+func (s *QuarantineService) List(filter domain.Filter) ([]domain.Record, error) {
+	return s.Repo.List(filter)
+}
+
+func (s *QuarantineService) Get(id string) (domain.Record, error) {
+	return s.Repo.GetByID(id)
+}
+
+// Retry vuelve a validar y guardar un registro pendiente. Si lo logra queda
+// resuelto; si no, se registra el intento y se devuelve ErrReplayFailed junto
+// con el registro actualizado.
+func (s *QuarantineService) Retry(id string) (domain.Record, error) {
+	rec, err := s.Repo.GetByID(id)
+	if err != nil {
+		return rec, err
+	}
+	if rec.Status != domain.StatusPending {
+		return rec, domain.ErrRecordNotPending
+	}
+
+	if replayErr := s.replay(rec); replayErr != nil {
+		rec, err := s.Repo.RecordFailedAttempt(id, replayErr)
+		if err != nil {
+			return rec, err
+		}
+		return rec, fmt.Errorf("%w: %v", domain.ErrReplayFailed, replayErr)
+	}
+	return s.Repo.SetStatus(id, domain.StatusResolved)
+}
+
+func (s *QuarantineService) Discard(id string) (domain.Record, error) {
+	return s.Repo.SetStatus(id, domain.StatusDiscarded)
+}
+
+// Replay reintenta hasta limit registros pendientes del tipo indicado (todos
+// los tipos si kind está vacío).
+func (s *QuarantineService) Replay(kind string, limit int) (domain.ReplaySummary, error) {
+	var summary domain.ReplaySummary
+
+	records, err := s.Repo.List(domain.Filter{Kind: kind, Status: domain.StatusPending, Limit: limit})
+	if err != nil {
+		return summary, err
+	}
+
+	for _, rec := range records {
+		summary.Processed++
+		if _, err := s.Retry(rec.ID); err != nil {
+			summary.Failed++
+			log.Printf("Registro %s (%s) sigue en cuarentena: %v", rec.ID, rec.Kind, err)
+			continue
+		}
+		summary.Resolved++
+	}
+	return summary, nil
+}
+
+func (s *QuarantineService) replay(rec domain.Record) error {
+	switch rec.Kind {
+	case domain.KindStock:
+		var raw stockdomain.RawStock
+		if err := json.Unmarshal(rec.Payload, &raw); err != nil {
+			return err
+		}
+		stock, err := raw.Parse()
+		if err != nil {
+			return err
+		}
+		_, err = s.Stocks.Save(stock)
+		return err
+
+	case domain.KindFinance:
+		var f financedomain.Finance
+		if err := json.Unmarshal(rec.Payload, &f); err != nil {
+			return err
+		}
+		if err := f.Validate(); err != nil {
+			return err
+		}
+		result, err := s.Finances.BulkSave([]financedomain.Finance{f})
+		if err != nil {
+			return err
+		}
+		if len(result.Rejected) > 0 {
+			return result.Rejected[0].Err
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: %s", domain.ErrUnknownKind, rec.Kind)
+}
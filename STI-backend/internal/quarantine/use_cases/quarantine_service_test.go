@@ -0,0 +1,131 @@
+package use_cases
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	financedomain "github.com/viteant/stockinsight/internal/finance/domain"
+	"github.com/viteant/stockinsight/internal/quarantine/domain"
+	stockdomain "github.com/viteant/stockinsight/internal/stock/domain"
+)
+
+type fakeQuarantineRepository struct {
+	records map[string]domain.Record
+}
+
+func (f *fakeQuarantineRepository) Add(records []domain.Record) error { return nil }
+
+func (f *fakeQuarantineRepository) List(filter domain.Filter) ([]domain.Record, error) {
+	var out []domain.Record
+	for _, r := range f.records {
+		if (filter.Kind == "" || r.Kind == filter.Kind) && (filter.Status == "" || r.Status == filter.Status) {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeQuarantineRepository) GetByID(id string) (domain.Record, error) {
+	r, ok := f.records[id]
+	if !ok {
+		return r, domain.ErrRecordNotFound
+	}
+	return r, nil
+}
+
+func (f *fakeQuarantineRepository) SetStatus(id, status string) (domain.Record, error) {
+	r, err := f.GetByID(id)
+	if err != nil {
+		return r, err
+	}
+	if r.Status != domain.StatusPending {
+		return r, domain.ErrRecordNotPending
+	}
+	r.Status = status
+	f.records[id] = r
+	return r, nil
+}
+
+func (f *fakeQuarantineRepository) RecordFailedAttempt(id string, cause error) (domain.Record, error) {
+	r := f.records[id]
+	r.Attempts++
+	r.LastError = cause.Error()
+	f.records[id] = r
+	return r, nil
+}
+
+type fakeStockSaver struct{ saved []stockdomain.Stock }
+
+func (f *fakeStockSaver) Save(stock stockdomain.Stock) (bool, error) {
+	f.saved = append(f.saved, stock)
+	return true, nil
+}
+
+type fakeFinanceSaver struct{ err error }
+
+func (f *fakeFinanceSaver) BulkSave(data []financedomain.Finance) (financedomain.SaveResult, error) {
+	if f.err != nil {
+		return financedomain.SaveResult{Failed: 1, Rejected: []financedomain.RejectedFinance{{Finance: data[0], Err: f.err}}}, nil
+	}
+	return financedomain.SaveResult{Inserted: len(data)}, nil
+}
+
+func newRecord(t *testing.T, id, kind string, payload any) domain.Record {
+	t.Helper()
+	b, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return domain.Record{ID: id, Kind: kind, Status: domain.StatusPending, Payload: b}
+}
+
+func TestRetry(t *testing.T) {
+	repo := &fakeQuarantineRepository{records: map[string]domain.Record{
+		"fixed":   newRecord(t, "fixed", domain.KindStock, stockdomain.RawStock{Ticker: "AAPL", TargetTo: "$1,250.00", Time: "2025-03-10T00:00:00Z"}),
+		"invalid": newRecord(t, "invalid", domain.KindStock, stockdomain.RawStock{Ticker: "AAPL", TargetTo: "N/A", Time: "2025-03-10T00:00:00Z"}),
+	}}
+	stocks := &fakeStockSaver{}
+	svc := &QuarantineService{Repo: repo, Stocks: stocks}
+
+	rec, err := svc.Retry("fixed")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.StatusResolved, rec.Status)
+	assert.Equal(t, float32(1250), stocks.saved[0].TargetTo)
+
+	rec, err = svc.Retry("invalid")
+	assert.ErrorIs(t, err, domain.ErrReplayFailed)
+	assert.Equal(t, domain.StatusPending, rec.Status)
+	assert.Equal(t, 1, rec.Attempts)
+	assert.Contains(t, rec.LastError, "target_to")
+
+	_, err = svc.Retry("fixed")
+	assert.ErrorIs(t, err, domain.ErrRecordNotPending)
+
+	_, err = svc.Retry("missing")
+	assert.ErrorIs(t, err, domain.ErrRecordNotFound)
+}
+
+func TestReplay_Finance(t *testing.T) {
+	day := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	repo := &fakeQuarantineRepository{records: map[string]domain.Record{
+		"ok":     newRecord(t, "ok", domain.KindFinance, financedomain.Finance{Ticker: "AAPL", Date: day, Close: 10}),
+		"zero":   newRecord(t, "zero", domain.KindFinance, financedomain.Finance{Ticker: "AAPL", Date: day}),
+		"stock":  newRecord(t, "stock", domain.KindStock, stockdomain.RawStock{}),
+		"closed": {ID: "closed", Kind: domain.KindFinance, Status: domain.StatusDiscarded},
+	}}
+	svc := &QuarantineService{Repo: repo, Finances: &fakeFinanceSaver{}}
+
+	summary, err := svc.Replay(domain.KindFinance, DefaultReplayLimit)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.ReplaySummary{Processed: 2, Resolved: 1, Failed: 1}, summary)
+	assert.Equal(t, domain.StatusResolved, repo.records["ok"].Status)
+	assert.Equal(t, domain.StatusPending, repo.records["zero"].Status)
+
+	svc.Finances = &fakeFinanceSaver{err: errors.New("duplicate key")}
+	repo.records["ok2"] = newRecord(t, "ok2", domain.KindFinance, financedomain.Finance{Ticker: "MSFT", Date: day, Close: 10})
+	_, err = svc.Retry("ok2")
+	assert.ErrorIs(t, err, domain.ErrReplayFailed)
+}
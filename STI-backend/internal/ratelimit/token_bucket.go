@@ -0,0 +1,122 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// TokenBucket es un limitador de tasa seguro para uso concurrente. Se recargan
+// rate tokens por segundo hasta un máximo de burst. PauseFor detiene a todos
+// los consumidores hasta que vence la pausa.
+type TokenBucket struct {
+	mu          sync.Mutex
+	rate        float64
+	burst       float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+}
+
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	if rate <= 0 {
+		rate = 1
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &TokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait bloquea hasta obtener un token o hasta que se cancele el contexto.
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	for {
+		delay := b.reserve()
+		if delay == 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// PauseFor suspende la entrega de tokens durante d. Si ya hay una pausa más
+// larga en curso, se conserva.
+func (b *TokenBucket) PauseFor(d time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	until := time.Now().Add(d)
+	if until.After(b.pausedUntil) {
+		b.pausedUntil = until
+	}
+}
+
+// Allow toma un token sin bloquear y devuelve los tokens que quedan. Si lo
+// obtuvo, reset es cuánto falta para que el balde vuelva a estar lleno; si
+// no, es cuánto falta para que haya un token.
+func (b *TokenBucket) Allow() (ok bool, remaining int, reset time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(b.pausedUntil) {
+		return false, 0, b.pausedUntil.Sub(now)
+	}
+
+	b.refill(now)
+	if b.tokens < 1 {
+		return false, 0, b.secondsFor(1 - b.tokens)
+	}
+	b.tokens--
+	return true, int(b.tokens), b.secondsFor(b.burst - b.tokens)
+}
+
+// Burst es la cantidad máxima de tokens del balde.
+func (b *TokenBucket) Burst() int {
+	return int(b.burst)
+}
+
+func (b *TokenBucket) secondsFor(tokens float64) time.Duration {
+	return time.Duration(tokens / b.rate * float64(time.Second))
+}
+
+// reserve toma un token si hay disponible y devuelve 0; si no, devuelve
+// cuánto falta para poder intentarlo de nuevo.
+func (b *TokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(b.pausedUntil) {
+		return b.pausedUntil.Sub(now)
+	}
+
+	b.refill(now)
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+
+	return b.secondsFor(1 - b.tokens)
+}
+
+func (b *TokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	b.last = now
+	b.tokens += elapsed * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+}
@@ -0,0 +1,25 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenBucket_Allow(t *testing.T) {
+	b := NewTokenBucket(2, 3)
+
+	for want := 2; want >= 0; want-- {
+		ok, remaining, reset := b.Allow()
+		assert.True(t, ok)
+		assert.Equal(t, want, remaining)
+		assert.Greater(t, reset, time.Duration(0))
+	}
+
+	ok, remaining, retry := b.Allow()
+	assert.False(t, ok)
+	assert.Equal(t, 0, remaining)
+	assert.InDelta(t, 500*time.Millisecond, retry, float64(50*time.Millisecond))
+	assert.Equal(t, 3, b.Burst())
+}
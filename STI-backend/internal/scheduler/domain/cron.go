@@ -0,0 +1,176 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule es una expresión cron de cinco campos: minuto, hora, día del mes,
+// mes y día de la semana (0 = domingo). Acepta *, listas (1,15), rangos
+// (1-5), pasos (*/15, 8-18/2) y los atajos @hourly, @daily, @weekly y
+// @monthly. Como en cron, si día del mes y día de la semana están
+// restringidos basta con que coincida uno de los dos. Un campo que cubre todo
+// su rango (*, */1, 1-31, 0-6, 0-7) no está restringido.
+type Schedule struct {
+	Expr   string
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+	anyDom bool
+	anyDow bool
+}
+
+var cronAliases = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = []cronField{
+	{"minuto", 0, 59},
+	{"hora", 0, 23},
+	{"día del mes", 1, 31},
+	{"mes", 1, 12},
+	{"día de la semana", 0, 7},
+}
+
+func ParseCron(expr string) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	spec := expr
+	if alias, ok := cronAliases[strings.ToLower(spec)]; ok {
+		spec = alias
+	}
+
+	parts := strings.Fields(spec)
+	if len(parts) != len(cronFields) {
+		return Schedule{}, fmt.Errorf("expresión cron %q: se esperan 5 campos", expr)
+	}
+
+	var bits [5]uint64
+	for i, part := range parts {
+		b, err := parseCronField(part, cronFields[i])
+		if err != nil {
+			return Schedule{}, fmt.Errorf("expresión cron %q: %w", expr, err)
+		}
+		bits[i] = b
+	}
+
+	// El 7 también es domingo.
+	dow := bits[4]
+	if dow&(1<<7) != 0 {
+		dow = dow&^(1<<7) | 1
+	}
+
+	return Schedule{
+		Expr:   expr,
+		minute: bits[0],
+		hour:   bits[1],
+		dom:    bits[2],
+		month:  bits[3],
+		dow:    dow,
+		anyDom: bits[2] == fieldMask(1, 31),
+		anyDow: dow == fieldMask(0, 6),
+	}, nil
+}
+
+// fieldMask tiene encendidos los bits de lo a hi.
+func fieldMask(lo, hi int) uint64 {
+	return (1<<(hi+1) - 1) &^ (1<<lo - 1)
+}
+
+func parseCronField(s string, f cronField) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(s, ",") {
+		rng, step := item, 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			n, err := strconv.Atoi(item[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("paso inválido en %s: %q", f.name, item)
+			}
+			rng, step = item[:i], n
+		}
+
+		lo, hi := f.min, f.max
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			a, b, _ := strings.Cut(rng, "-")
+			var err1, err2 error
+			lo, err1 = strconv.Atoi(a)
+			hi, err2 = strconv.Atoi(b)
+			if err1 != nil || err2 != nil || lo > hi {
+				return 0, fmt.Errorf("rango inválido en %s: %q", f.name, item)
+			}
+		default:
+			n, err := strconv.Atoi(rng)
+			if err != nil {
+				return 0, fmt.Errorf("valor inválido en %s: %q", f.name, item)
+			}
+			lo = n
+			if step == 1 {
+				hi = n
+			}
+		}
+
+		if lo < f.min || hi > f.max {
+			return 0, fmt.Errorf("%s fuera de rango (%d-%d): %q", f.name, f.min, f.max, item)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// Next devuelve el primer minuto posterior a t que cumple la expresión, en
+// la zona horaria de t.
+func (s Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+
+	// Cinco años alcanzan para cualquier expresión válida (p. ej. 29 de febrero).
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s Schedule) matchDay(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	switch {
+	case s.anyDom && s.anyDow:
+		return true
+	case s.anyDom:
+		return dow
+	case s.anyDow:
+		return dom
+	}
+	return dom || dow
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCron_Next(t *testing.T) {
+	// Miércoles 3 de julio de 2024, 10:07 UTC.
+	now := time.Date(2024, 7, 3, 10, 7, 30, 0, time.UTC)
+
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"*/30 * * * *", time.Date(2024, 7, 3, 10, 30, 0, 0, time.UTC)},
+		{"0 * * * *", time.Date(2024, 7, 3, 11, 0, 0, 0, time.UTC)},
+		{"30 22 * * 1-5", time.Date(2024, 7, 3, 22, 30, 0, 0, time.UTC)},
+		{"0 9 * * 6,0", time.Date(2024, 7, 6, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * 7", time.Date(2024, 7, 7, 9, 0, 0, 0, time.UTC)},
+		{"15 8-18/4 * * *", time.Date(2024, 7, 3, 12, 15, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 7, 4, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Día del mes o día de la semana: el 5 (viernes) llega antes que un lunes.
+		{"0 0 5 * 1", time.Date(2024, 7, 5, 0, 0, 0, 0, time.UTC)},
+		// Un campo que cubre todo su rango no restringe: solo cuenta el otro.
+		{"0 0 */1 * 1", time.Date(2024, 7, 8, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1-31 * 1", time.Date(2024, 7, 8, 0, 0, 0, 0, time.UTC)},
+		{"0 0 10 * 0-6", time.Date(2024, 7, 10, 0, 0, 0, 0, time.UTC)},
+		{"0 0 10 * 0-7", time.Date(2024, 7, 10, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		s, err := ParseCron(c.expr)
+		if assert.NoError(t, err, c.expr) {
+			assert.Equal(t, c.want, s.Next(now), c.expr)
+		}
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		_, err := ParseCron(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestSchedule_NextNever(t *testing.T) {
+	s, err := ParseCron("0 0 30 2 *")
+	assert.NoError(t, err)
+	assert.True(t, s.Next(time.Now()).IsZero())
+}
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	StatusRunning  = "running"
+	StatusSuccess  = "success"
+	StatusFailed   = "failed"
+	StatusCanceled = "canceled"
+)
+
+var (
+	ErrExecutionNotFound   = errors.New("ejecución no encontrada")
+	ErrExecutionNotRunning = errors.New("la ejecución ya terminó")
+	ErrUnknownJob          = errors.New("trabajo desconocido")
+	ErrJobBusy             = errors.New("el trabajo ya se está ejecutando")
+)
+
+// Progress es el avance de una ejecución. La sincronización informa páginas;
+// la actualización de precios y la recalificación, tickers. RunID es la
+// corrida en sync_runs, que se puede consultar desde cualquier réplica.
+type Progress struct {
+	RunID           string `json:"run_id,omitempty"`
+	Pages           int    `json:"pages"`
+	TickersDone     int    `json:"tickers_done"`
+	TickersTotal    int    `json:"tickers_total"`
+	RowsInserted    int    `json:"rows_inserted"`
+	RowsUpdated     int    `json:"rows_updated"`
+	RowsFailed      int    `json:"rows_failed"`
+	RowsQuarantined int    `json:"rows_quarantined"`
+}
+
+// Execution es una ejecución de un trabajo lanzada desde la API.
+type Execution struct {
+	ID         string     `json:"id"`
+	Job        string     `json:"job"`
+	Status     string     `json:"status"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+	Progress   Progress   `json:"progress"`
+	Error      string     `json:"error,omitempty"`
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Nombres de los trabajos programados, en el orden en que se ejecutan cuando
+// coinciden: los precios se completan para los tickers recién sincronizados
+// y los puntajes se recalculan con ambos.
+const (
+	JobSync    = "sync"
+	JobFinance = "finance"
+	JobRescore = "rescore"
+)
+
+var JobOrder = []string{JobSync, JobFinance, JobRescore}
+
+// ErrLeaseLost indica que el lease venció y pudo tomarlo otra réplica.
+var ErrLeaseLost = errors.New("lease perdido")
+
+// Runner ejecuta un trabajo e informa su avance con report. Debe terminar
+// pronto al cancelarse ctx.
+type Runner func(ctx context.Context, report func(Progress)) error
+
+// Job es un trabajo programado.
+type Job struct {
+	Name     string
+	Schedule Schedule
+	Run      Runner
+}
+
+// LeaseRepository reparte los trabajos entre réplicas. Acquire toma el lease
+// de name para la ejecución programada en scheduledAt; devuelve false si otra
+// réplica lo tiene vigente o si esa ejecución ya se tomó. Renew extiende el
+// lease mientras el trabajo sigue corriendo y Release lo libera al terminar.
+type LeaseRepository interface {
+	Acquire(name, holder string, scheduledAt time.Time, ttl time.Duration) (bool, error)
+	Renew(name, holder string, ttl time.Duration) error
+	Release(name, holder string) error
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/viteant/stockinsight/internal/scheduler/domain"
+)
+
+type PersistenceLeaseRepository struct {
+	DB *sql.DB
+}
+
+func NewCockroachLeaseRepository(db *sql.DB) *PersistenceLeaseRepository {
+	return &PersistenceLeaseRepository{DB: db}
+}
+
+// Acquire solo toma el lease si venció y si scheduled_at es posterior a la
+// última ejecución tomada, así dos réplicas con relojes algo corridos no
+// repiten la misma ejecución.
+func (r *PersistenceLeaseRepository) Acquire(name, holder string, scheduledAt time.Time, ttl time.Duration) (bool, error) {
+	var got string
+	err := r.DB.QueryRow(`
+		INSERT INTO job_leases (name, holder, scheduled_at, acquired_at, expires_at)
+		VALUES ($1, $2, $3, now(), now() + $4::INT * INTERVAL '1 millisecond')
+		ON CONFLICT (name) DO UPDATE SET
+			holder = excluded.holder,
+			scheduled_at = excluded.scheduled_at,
+			acquired_at = excluded.acquired_at,
+			expires_at = excluded.expires_at
+		WHERE job_leases.expires_at < now()
+			AND job_leases.scheduled_at < excluded.scheduled_at
+		RETURNING holder
+	`, name, holder, scheduledAt, ttl.Milliseconds()).Scan(&got)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return got == holder, nil
+}
+
+func (r *PersistenceLeaseRepository) Renew(name, holder string, ttl time.Duration) error {
+	res, err := r.DB.Exec(`
+		UPDATE job_leases
+		SET expires_at = now() + $3::INT * INTERVAL '1 millisecond'
+		WHERE name = $1 AND holder = $2
+	`, name, holder, ttl.Milliseconds())
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return domain.ErrLeaseLost
+	}
+	return err
+}
+
+// Release vence el lease pero conserva scheduled_at para que la misma
+// ejecución no se vuelva a tomar.
+func (r *PersistenceLeaseRepository) Release(name, holder string) error {
+	_, err := r.DB.Exec(`
+		UPDATE job_leases SET expires_at = now()
+		WHERE name = $1 AND holder = $2
+	`, name, holder)
+	return err
+}
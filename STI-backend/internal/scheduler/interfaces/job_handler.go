@@ -0,0 +1,123 @@
+package interfaces
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/viteant/stockinsight/internal/problem"
+	"github.com/viteant/stockinsight/internal/scheduler/domain"
+	"github.com/viteant/stockinsight/internal/scheduler/use_cases"
+)
+
+type JobHandler struct {
+	useCase *use_cases.JobService
+}
+
+func NewJobHandler(useCase *use_cases.JobService) *JobHandler {
+	return &JobHandler{
+		useCase: useCase,
+	}
+}
+
+// StartJob godoc
+// @Summary Lanzar un trabajo
+// @Description Ejecuta en segundo plano la sincronización de ratings (sync), la actualización de precios (finance) o la recalificación de brokers (rescore) y devuelve la ejecución. progress.run_id es la corrida en sync_runs, consultable en /api/sync/runs/{id} desde cualquier réplica.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param job path string true "Trabajo (sync, finance o rescore)"
+// @Success 202 {object} domain.Execution
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Failure 429 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /api/admin/jobs/{job} [post]
+func (h *JobHandler) StartJob(c *fiber.Ctx) error {
+	exec, err := h.useCase.Start(c.Params("job"))
+	if err != nil {
+		return jobError(c, err)
+	}
+	return c.Status(fiber.StatusAccepted).JSON(exec)
+}
+
+// ListJobs godoc
+// @Summary Ejecuciones lanzadas desde la API
+// @Description Devuelve las últimas ejecuciones de este proceso, de la más reciente a la más antigua.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Success 200 {array} domain.Execution
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 429 {object} problem.Problem
+// @Router /api/admin/jobs [get]
+func (h *JobHandler) ListJobs(c *fiber.Ctx) error {
+	return c.JSON(h.useCase.List())
+}
+
+// GetJob godoc
+// @Summary Estado de una ejecución
+// @Description Devuelve el estado y el avance de la ejecución: páginas procesadas en sync y tickers procesados en finance y rescore. Solo la réplica que lanzó la ejecución la conoce; en las demás se usa progress.run_id con /api/sync/runs/{id}.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param id path string true "ID de la ejecución"
+// @Success 200 {object} domain.Execution
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 429 {object} problem.Problem
+// @Router /api/admin/jobs/{id} [get]
+func (h *JobHandler) GetJob(c *fiber.Ctx) error {
+	exec, err := h.useCase.Get(c.Params("id"))
+	if err != nil {
+		return jobError(c, err)
+	}
+	return c.JSON(exec)
+}
+
+// CancelJob godoc
+// @Summary Cancelar una ejecución
+// @Description Pide la cancelación de una ejecución en curso. El estado pasa a canceled cuando el trabajo se detiene; la sincronización termina la página actual y guarda el cursor.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param id path string true "ID de la ejecución"
+// @Success 202 {object} domain.Execution
+// @Failure 401 {object} problem.Problem
+// @Failure 403 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 409 {object} problem.Problem
+// @Failure 429 {object} problem.Problem
+// @Router /api/admin/jobs/{id}/cancel [post]
+func (h *JobHandler) CancelJob(c *fiber.Ctx) error {
+	exec, err := h.useCase.Cancel(c.Params("id"))
+	if err != nil {
+		return jobError(c, err)
+	}
+	return c.Status(fiber.StatusAccepted).JSON(exec)
+}
+
+func jobError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrUnknownJob):
+		return problem.Write(c, fiber.StatusNotFound, "Unknown job", "el trabajo debe ser sync, finance o rescore")
+	case errors.Is(err, domain.ErrExecutionNotFound):
+		return problem.Write(c, fiber.StatusNotFound, "Job execution not found", "")
+	case errors.Is(err, domain.ErrJobBusy):
+		return problem.Write(c, fiber.StatusConflict, "Job already running", "el trabajo ya se está ejecutando en esta u otra réplica")
+	case errors.Is(err, domain.ErrExecutionNotRunning):
+		return problem.Write(c, fiber.StatusConflict, "Job execution is not running", "solo se pueden cancelar ejecuciones en curso")
+	}
+	return problem.Write(c, fiber.StatusInternalServerError, "Error starting job", "")
+}
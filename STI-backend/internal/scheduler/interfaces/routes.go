@@ -0,0 +1,35 @@
+package interfaces
+
+import (
+	"database/sql"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/viteant/stockinsight/internal/cassette"
+	"github.com/viteant/stockinsight/internal/scheduler/infrastructure/repository"
+	"github.com/viteant/stockinsight/internal/scheduler/use_cases"
+)
+
+// jobService guarda las ejecuciones lanzadas desde la API para poder
+// cancelarlas al apagar el servidor.
+var jobService *use_cases.JobService
+
+// RegisterJobRoutes registra los endpoints de trabajos. app debe estar
+// protegido con el scope admin; tape es la configuración de cassettes de los
+// trabajos lanzados desde la API.
+func RegisterJobRoutes(app fiber.Router, db *sql.DB, tape cassette.Config) {
+	jobService = use_cases.NewJobService(Runners(tape), repository.NewCockroachLeaseRepository(db), holderID())
+	jobHandler := NewJobHandler(jobService)
+
+	app.Get("/jobs", jobHandler.ListJobs)
+	app.Post("/jobs/:job", jobHandler.StartJob)
+	app.Get("/jobs/:id", jobHandler.GetJob)
+	app.Post("/jobs/:id/cancel", jobHandler.CancelJob)
+}
+
+// StopJobs cancela las ejecuciones lanzadas desde la API y espera a que
+// terminen.
+func StopJobs() {
+	if jobService != nil {
+		jobService.Shutdown()
+	}
+}
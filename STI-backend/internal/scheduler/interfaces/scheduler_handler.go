@@ -0,0 +1,183 @@
+package interfaces
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	brokerinterfaces "github.com/viteant/stockinsight/internal/broker/interfaces"
+	"github.com/viteant/stockinsight/internal/cassette"
+	"github.com/viteant/stockinsight/internal/db"
+	financedomain "github.com/viteant/stockinsight/internal/finance/domain"
+	financeinterfaces "github.com/viteant/stockinsight/internal/finance/interfaces"
+	"github.com/viteant/stockinsight/internal/scheduler/domain"
+	"github.com/viteant/stockinsight/internal/scheduler/infrastructure/repository"
+	"github.com/viteant/stockinsight/internal/scheduler/use_cases"
+	stockdomain "github.com/viteant/stockinsight/internal/stock/domain"
+	stockinterfaces "github.com/viteant/stockinsight/internal/stock/interfaces"
+)
+
+// Expresiones por defecto: la sincronización cada 30 minutos y, después del
+// cierre de NYSE, los precios y la recalificación.
+var defaultSchedules = map[string]string{
+	domain.JobSync:    "*/30 * * * *",
+	domain.JobFinance: "30 22 * * 1-5",
+	domain.JobRescore: "0 23 * * 1-5",
+}
+
+var scheduleEnv = map[string]string{
+	domain.JobSync:    "SCHEDULE_SYNC",
+	domain.JobFinance: "SCHEDULE_FINANCE",
+	domain.JobRescore: "SCHEDULE_RESCORE",
+}
+
+// RunScheduler ejecuta los trabajos programados hasta que se cancela ctx.
+func RunScheduler(ctx context.Context, tape cassette.Config) error {
+	jobs, err := JobsFromEnv(tape)
+	if err != nil {
+		return err
+	}
+
+	loc := time.UTC
+	if tz := os.Getenv("SCHEDULER_TIMEZONE"); tz != "" {
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return fmt.Errorf("SCHEDULER_TIMEZONE inválida: %w", err)
+		}
+	}
+
+	dbConn := db.NewCockroachDB()
+	defer dbConn.Close()
+
+	scheduler := use_cases.NewScheduler(jobs, repository.NewCockroachLeaseRepository(dbConn), holderID())
+	scheduler.Now = func() time.Time { return time.Now().In(loc) }
+	return scheduler.Run(ctx)
+}
+
+// JobsFromEnv arma los trabajos con las expresiones de SCHEDULE_SYNC,
+// SCHEDULE_FINANCE y SCHEDULE_RESCORE. Con "off" el trabajo no se programa.
+func JobsFromEnv(tape cassette.Config) ([]domain.Job, error) {
+	runners := Runners(tape)
+
+	var jobs []domain.Job
+	for _, name := range domain.JobOrder {
+		expr := os.Getenv(scheduleEnv[name])
+		if expr == "" {
+			expr = defaultSchedules[name]
+		}
+		if strings.EqualFold(expr, "off") {
+			continue
+		}
+
+		schedule, err := domain.ParseCron(expr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", scheduleEnv[name], err)
+		}
+		jobs = append(jobs, domain.Job{Name: name, Schedule: schedule, Run: runners[name]})
+	}
+	return jobs, nil
+}
+
+// Runners devuelve los trabajos que se pueden programar o lanzar desde la API.
+// tape indica si la sincronización y los precios graban o reproducen sus
+// pedidos HTTP.
+func Runners(tape cassette.Config) map[string]domain.Runner {
+	return map[string]domain.Runner{
+		domain.JobSync: syncRunner(tape, false),
+		domain.JobFinance: func(ctx context.Context, report func(domain.Progress)) error {
+			progress := &runProgress{report: report}
+			return financeinterfaces.SyncFinanceHandler(ctx, tape, progress.started, func(done, total int, summary financedomain.UpdateSummary) {
+				progress.update(domain.Progress{
+					TickersDone:     done,
+					TickersTotal:    total,
+					RowsInserted:    summary.RowsInserted,
+					RowsUpdated:     summary.RowsUpdated,
+					RowsFailed:      summary.RowsFailed,
+					RowsQuarantined: summary.RowsQuarantined,
+				})
+			})
+		},
+		domain.JobRescore: func(ctx context.Context, report func(domain.Progress)) error {
+			progress := &runProgress{report: report}
+			err := brokerinterfaces.RunRescore(ctx, progress.started, func(done, total int) {
+				progress.update(domain.Progress{TickersDone: done, TickersTotal: total})
+			})
+			if err != nil {
+				return err
+			}
+			return stockinterfaces.RefreshBrokerScores()
+		},
+	}
+}
+
+// RunSync ejecuta la sincronización de stocks en primer plano con el mismo
+// lease que el scheduler y la API. Devuelve domain.ErrJobBusy si ya hay una
+// en curso en esta u otra réplica.
+func RunSync(ctx context.Context, tape cassette.Config, full bool) error {
+	return runExclusive(ctx, domain.JobSync, syncRunner(tape, full))
+}
+
+// RunJob ejecuta en primer plano uno de los trabajos de Runners tomando su
+// lease. Devuelve domain.ErrJobBusy si ya está en curso en esta u otra
+// réplica.
+func RunJob(ctx context.Context, tape cassette.Config, name string) error {
+	run, ok := Runners(tape)[name]
+	if !ok {
+		return domain.ErrUnknownJob
+	}
+	return runExclusive(ctx, name, run)
+}
+
+func runExclusive(ctx context.Context, name string, run domain.Runner) error {
+	dbConn := db.NewCockroachDB()
+	defer dbConn.Close()
+
+	jobs := use_cases.NewJobService(
+		map[string]domain.Runner{name: run},
+		repository.NewCockroachLeaseRepository(dbConn),
+		holderID(),
+	)
+	return jobs.Run(ctx, name, func(domain.Progress) {})
+}
+
+func syncRunner(tape cassette.Config, full bool) domain.Runner {
+	return func(ctx context.Context, report func(domain.Progress)) error {
+		progress := &runProgress{report: report}
+		return stockinterfaces.RunStockSync(ctx, tape, full, progress.started, func(stats stockdomain.SyncStats) {
+			progress.update(domain.Progress{
+				Pages:           stats.PagesFetched,
+				RowsInserted:    stats.RowsInserted,
+				RowsUpdated:     stats.RowsUpdated,
+				RowsFailed:      stats.RowsFailed,
+				RowsQuarantined: stats.RowsQuarantined,
+			})
+		})
+	}
+}
+
+// runProgress agrega a cada avance el ID de la corrida en sync_runs, que se
+// conoce al registrarla.
+type runProgress struct {
+	report func(domain.Progress)
+	runID  string
+}
+
+func (p *runProgress) started(runID string) {
+	p.runID = runID
+	p.report(domain.Progress{RunID: runID})
+}
+
+func (p *runProgress) update(progress domain.Progress) {
+	progress.RunID = p.runID
+	p.report(progress)
+}
+
+// holderID identifica a esta réplica en job_leases.
+func holderID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "desconocido"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
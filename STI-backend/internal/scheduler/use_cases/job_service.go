@@ -0,0 +1,277 @@
+package use_cases
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/viteant/stockinsight/internal/scheduler/domain"
+)
+
+const (
+	// Cantidad de ejecuciones que se conservan en memoria.
+	maxExecutions = 100
+	// Tiempo que Start espera el primer avance del trabajo.
+	defaultRunIDWait = 5 * time.Second
+)
+
+// JobService lanza trabajos en segundo plano desde la API. Las ejecuciones
+// viven en la memoria del proceso, así que cada réplica conoce solo las suyas;
+// el ID de la corrida en sync_runs que informa el trabajo se puede consultar
+// desde cualquiera. Cada ejecución toma el mismo lease que el scheduler, así
+// que no se superpone con una ejecución programada ni con otra réplica, y
+// cubre las ejecuciones programadas anteriores a su inicio.
+type JobService struct {
+	Runners  map[string]domain.Runner
+	Leases   domain.LeaseRepository
+	Holder   string
+	LeaseTTL time.Duration
+	// RunIDWait es cuánto espera Start el primer avance, que trae el ID de la
+	// corrida, antes de responder sin él.
+	RunIDWait time.Duration
+
+	mu         sync.Mutex
+	executions map[string]*execution
+	// starting marca los trabajos que están tomando el lease, para no pedirlo
+	// dos veces mientras s.mu está libre.
+	starting map[string]bool
+	order    []string
+	wg       sync.WaitGroup
+}
+
+type execution struct {
+	domain.Execution
+	cancel context.CancelFunc
+}
+
+// NewJobService toma la duración del lease de SCHEDULER_LEASE_TTL_SEC.
+func NewJobService(runners map[string]domain.Runner, leases domain.LeaseRepository, holder string) *JobService {
+	ttl := envSeconds("SCHEDULER_LEASE_TTL_SEC", defaultLeaseTTL)
+	if ttl <= 0 {
+		ttl = defaultLeaseTTL
+	}
+
+	return &JobService{
+		Runners:    runners,
+		Leases:     leases,
+		Holder:     holder,
+		LeaseTTL:   ttl,
+		RunIDWait:  defaultRunIDWait,
+		executions: make(map[string]*execution),
+		starting:   make(map[string]bool),
+	}
+}
+
+// Start toma el lease del trabajo y lo ejecuta en segundo plano. Devuelve la
+// ejecución con su primer avance, o sin él si no llega en RunIDWait, y
+// domain.ErrJobBusy si ya se está ejecutando en este proceso o en otro.
+func (s *JobService) Start(name string) (domain.Execution, error) {
+	run, ok := s.Runners[name]
+	if !ok {
+		return domain.Execution{}, domain.ErrUnknownJob
+	}
+
+	now := time.Now()
+	if err := s.acquire(name, now); err != nil {
+		return domain.Execution{}, err
+	}
+
+	s.mu.Lock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e := &execution{
+		Execution: domain.Execution{
+			ID:        newExecutionID(),
+			Job:       name,
+			Status:    domain.StatusRunning,
+			StartedAt: now,
+		},
+		cancel: cancel,
+	}
+	s.executions[e.ID] = e
+	s.order = append(s.order, e.ID)
+	s.prune()
+
+	// first recibe el estado al primer avance o al terminar, lo que ocurra
+	// antes.
+	first := make(chan domain.Execution, 1)
+	s.wg.Add(1)
+	go s.execute(ctx, e, run, first)
+
+	log.Printf("▶️  Ejecución %s de %s lanzada desde la API", e.ID, name)
+	started := e.Execution
+	s.mu.Unlock()
+
+	timer := time.NewTimer(s.RunIDWait)
+	defer timer.Stop()
+	select {
+	case exec := <-first:
+		return exec, nil
+	case <-timer.C:
+		return started, nil
+	}
+}
+
+// Run toma el lease del trabajo y lo ejecuta en primer plano hasta que
+// termina o se cancela ctx. Es lo que usa la CLI, para no superponerse con el
+// scheduler ni con las ejecuciones lanzadas desde la API.
+func (s *JobService) Run(ctx context.Context, name string, report func(domain.Progress)) error {
+	run, ok := s.Runners[name]
+	if !ok {
+		return domain.ErrUnknownJob
+	}
+	if err := s.acquire(name, time.Now()); err != nil {
+		return err
+	}
+
+	return runHoldingLease(ctx, s.Leases, name, s.Holder, s.LeaseTTL, func(ctx context.Context) error {
+		return run(ctx, report)
+	})
+}
+
+// acquire toma el lease de name. La consulta a la base se hace sin s.mu para
+// no bloquear Get, List ni el avance de otras ejecuciones mientras tanto.
+func (s *JobService) acquire(name string, now time.Time) error {
+	s.mu.Lock()
+	busy := s.starting[name]
+	for _, e := range s.executions {
+		if e.Job == name && e.Status == domain.StatusRunning {
+			busy = true
+		}
+	}
+	if busy {
+		s.mu.Unlock()
+		return domain.ErrJobBusy
+	}
+	s.starting[name] = true
+	s.mu.Unlock()
+
+	acquired, err := s.Leases.Acquire(name, s.Holder, now, s.LeaseTTL)
+
+	s.mu.Lock()
+	delete(s.starting, name)
+	s.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		return domain.ErrJobBusy
+	}
+	return nil
+}
+
+func (s *JobService) execute(ctx context.Context, e *execution, run domain.Runner, first chan<- domain.Execution) {
+	defer s.wg.Done()
+	defer e.cancel()
+
+	notify := func() {
+		select {
+		case first <- e.Execution:
+		default:
+		}
+	}
+	err := runHoldingLease(ctx, s.Leases, e.Job, s.Holder, s.LeaseTTL, func(ctx context.Context) error {
+		return run(ctx, func(p domain.Progress) {
+			s.mu.Lock()
+			e.Progress = p
+			notify()
+			s.mu.Unlock()
+		})
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	finished := time.Now()
+	e.FinishedAt = &finished
+	switch {
+	case err != nil && errors.Is(ctx.Err(), context.Canceled):
+		e.Status = domain.StatusCanceled
+		e.Error = err.Error()
+	case err != nil:
+		e.Status = domain.StatusFailed
+		e.Error = err.Error()
+	default:
+		e.Status = domain.StatusSuccess
+	}
+	notify()
+	log.Printf("Ejecución %s de %s terminada: %s", e.ID, e.Job, e.Status)
+}
+
+func (s *JobService) Get(id string) (domain.Execution, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.executions[id]
+	if !ok {
+		return domain.Execution{}, domain.ErrExecutionNotFound
+	}
+	return e.Execution, nil
+}
+
+// List devuelve las ejecuciones de la más reciente a la más antigua.
+func (s *JobService) List() []domain.Execution {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]domain.Execution, 0, len(s.order))
+	for i := len(s.order) - 1; i >= 0; i-- {
+		list = append(list, s.executions[s.order[i]].Execution)
+	}
+	return list
+}
+
+// Cancel pide la cancelación de una ejecución en curso. El estado pasa a
+// canceled cuando el trabajo efectivamente se detiene.
+func (s *JobService) Cancel(id string) (domain.Execution, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.executions[id]
+	if !ok {
+		return domain.Execution{}, domain.ErrExecutionNotFound
+	}
+	if e.Status != domain.StatusRunning {
+		return e.Execution, domain.ErrExecutionNotRunning
+	}
+	e.cancel()
+	log.Printf("Cancelación de la ejecución %s de %s solicitada", e.ID, e.Job)
+	return e.Execution, nil
+}
+
+// Shutdown cancela las ejecuciones en curso y espera a que terminen.
+func (s *JobService) Shutdown() {
+	s.mu.Lock()
+	for _, e := range s.executions {
+		if e.Status == domain.StatusRunning {
+			e.cancel()
+		}
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// prune descarta las ejecuciones terminadas más antiguas por encima de
+// maxExecutions. Se llama con s.mu tomado.
+func (s *JobService) prune() {
+	for i := 0; len(s.order) > maxExecutions && i < len(s.order); {
+		id := s.order[i]
+		if s.executions[id].Status == domain.StatusRunning {
+			i++
+			continue
+		}
+		delete(s.executions, id)
+		s.order = append(s.order[:i], s.order[i+1:]...)
+	}
+}
+
+func newExecutionID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
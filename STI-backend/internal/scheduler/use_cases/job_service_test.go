@@ -0,0 +1,166 @@
+package use_cases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viteant/stockinsight/internal/scheduler/domain"
+)
+
+func waitFinished(t *testing.T, s *JobService, id string) domain.Execution {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		exec, err := s.Get(id)
+		assert.NoError(t, err)
+		if exec.Status != domain.StatusRunning {
+			return exec
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("la ejecución no terminó")
+	return domain.Execution{}
+}
+
+func TestJobService_ReportsProgressAndResult(t *testing.T) {
+	leases := &fakeLeases{}
+	s := NewJobService(map[string]domain.Runner{
+		domain.JobFinance: func(ctx context.Context, report func(domain.Progress)) error {
+			report(domain.Progress{TickersDone: 1, TickersTotal: 2})
+			report(domain.Progress{TickersDone: 2, TickersTotal: 2, RowsInserted: 40})
+			return nil
+		},
+		domain.JobSync: func(ctx context.Context, report func(domain.Progress)) error {
+			report(domain.Progress{Pages: 3})
+			return errors.New("API caída")
+		},
+	}, leases, "test")
+
+	exec, err := s.Start(domain.JobFinance)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.StatusRunning, exec.Status)
+	assert.NotEmpty(t, exec.ID)
+
+	done := waitFinished(t, s, exec.ID)
+	assert.Equal(t, domain.StatusSuccess, done.Status)
+	assert.Equal(t, domain.Progress{TickersDone: 2, TickersTotal: 2, RowsInserted: 40}, done.Progress)
+	assert.NotNil(t, done.FinishedAt)
+
+	exec, err = s.Start(domain.JobSync)
+	assert.NoError(t, err)
+	failed := waitFinished(t, s, exec.ID)
+	assert.Equal(t, domain.StatusFailed, failed.Status)
+	assert.Equal(t, "API caída", failed.Error)
+	assert.Equal(t, 3, failed.Progress.Pages)
+
+	assert.Len(t, s.List(), 2)
+	assert.Equal(t, exec.ID, s.List()[0].ID)
+	assert.ElementsMatch(t, []string{domain.JobFinance, domain.JobSync}, leases.released)
+}
+
+func TestJobService_CancelAndBusy(t *testing.T) {
+	started := make(chan struct{})
+	s := NewJobService(map[string]domain.Runner{
+		domain.JobSync: func(ctx context.Context, report func(domain.Progress)) error {
+			report(domain.Progress{RunID: "run-1"})
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}, &fakeLeases{}, "test")
+
+	exec, err := s.Start(domain.JobSync)
+	assert.NoError(t, err)
+	// Start responde con el primer avance, que trae la corrida en sync_runs.
+	assert.Equal(t, domain.StatusRunning, exec.Status)
+	assert.Equal(t, "run-1", exec.Progress.RunID)
+	<-started
+
+	_, err = s.Start(domain.JobSync)
+	assert.ErrorIs(t, err, domain.ErrJobBusy)
+
+	_, err = s.Cancel(exec.ID)
+	assert.NoError(t, err)
+	canceled := waitFinished(t, s, exec.ID)
+	assert.Equal(t, domain.StatusCanceled, canceled.Status)
+
+	_, err = s.Cancel(exec.ID)
+	assert.ErrorIs(t, err, domain.ErrExecutionNotRunning)
+	_, err = s.Cancel("no-existe")
+	assert.ErrorIs(t, err, domain.ErrExecutionNotFound)
+}
+
+func TestJobService_RejectsUnknownAndLeasedJobs(t *testing.T) {
+	leases := &fakeLeases{taken: map[string]string{domain.JobRescore: "otra"}}
+	s := NewJobService(map[string]domain.Runner{
+		domain.JobRescore: func(ctx context.Context, report func(domain.Progress)) error { return nil },
+	}, leases, "test")
+
+	_, err := s.Start("backup")
+	assert.ErrorIs(t, err, domain.ErrUnknownJob)
+
+	_, err = s.Start(domain.JobRescore)
+	assert.ErrorIs(t, err, domain.ErrJobBusy)
+	assert.Empty(t, s.List())
+}
+
+// blockingLeases demora Acquire hasta que se cierra release.
+type blockingLeases struct {
+	fakeLeases
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingLeases) Acquire(name, holder string, scheduledAt time.Time, ttl time.Duration) (bool, error) {
+	close(b.entered)
+	<-b.release
+	return b.fakeLeases.Acquire(name, holder, scheduledAt, ttl)
+}
+
+func TestJobService_AcquiresLeaseWithoutHoldingTheLock(t *testing.T) {
+	leases := &blockingLeases{entered: make(chan struct{}), release: make(chan struct{})}
+	s := NewJobService(map[string]domain.Runner{
+		domain.JobSync: func(ctx context.Context, report func(domain.Progress)) error { return nil },
+	}, leases, "test")
+
+	started := make(chan error, 1)
+	go func() {
+		_, err := s.Start(domain.JobSync)
+		started <- err
+	}()
+	<-leases.entered
+
+	// Mientras se toma el lease, la lista responde y un segundo Start no
+	// vuelve a pedirlo.
+	assert.Empty(t, s.List())
+	_, err := s.Start(domain.JobSync)
+	assert.ErrorIs(t, err, domain.ErrJobBusy)
+
+	close(leases.release)
+	assert.NoError(t, <-started)
+	assert.Len(t, s.List(), 1)
+}
+
+func TestJobService_RunHoldsTheLease(t *testing.T) {
+	leases := &fakeLeases{}
+	var report []domain.Progress
+	s := NewJobService(map[string]domain.Runner{
+		domain.JobSync: func(ctx context.Context, r func(domain.Progress)) error {
+			r(domain.Progress{Pages: 1})
+			assert.Equal(t, "cli", leases.taken[domain.JobSync])
+			return nil
+		},
+	}, leases, "cli")
+
+	err := s.Run(context.Background(), domain.JobSync, func(p domain.Progress) { report = append(report, p) })
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Progress{{Pages: 1}}, report)
+	assert.Equal(t, []string{domain.JobSync}, leases.released)
+
+	leases.taken = map[string]string{domain.JobSync: "otra"}
+	err = s.Run(context.Background(), domain.JobSync, func(domain.Progress) {})
+	assert.ErrorIs(t, err, domain.ErrJobBusy)
+}
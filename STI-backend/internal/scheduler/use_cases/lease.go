@@ -0,0 +1,55 @@
+package use_cases
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/viteant/stockinsight/internal/scheduler/domain"
+)
+
+// runHoldingLease corre fn con un lease ya tomado, lo renueva mientras dure y
+// lo libera al terminar.
+func runHoldingLease(
+	ctx context.Context,
+	leases domain.LeaseRepository,
+	name, holder string,
+	ttl time.Duration,
+	fn func(ctx context.Context) error,
+) error {
+	runCtx, cancel := context.WithCancel(ctx)
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		keepLease(runCtx, cancel, leases, name, holder, ttl)
+	}()
+
+	err := fn(runCtx)
+	cancel()
+	<-renewed
+
+	if releaseErr := leases.Release(name, holder); releaseErr != nil {
+		log.Printf("Error liberando el lease de %s: %v", name, releaseErr)
+	}
+	return err
+}
+
+// keepLease renueva el lease cada tercio de su duración. Si se pierde, cancela
+// el trabajo para que no lo ejecuten dos réplicas a la vez.
+func keepLease(ctx context.Context, cancel context.CancelFunc, leases domain.LeaseRepository, name, holder string, ttl time.Duration) {
+	ticker := time.NewTicker(max(ttl/3, time.Second))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := leases.Renew(name, holder, ttl); err != nil {
+				log.Printf("No se pudo renovar el lease de %s, se cancela: %v", name, err)
+				cancel()
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package use_cases
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/viteant/stockinsight/internal/scheduler/domain"
+)
+
+const (
+	defaultJitter   = 30 * time.Second
+	defaultLeaseTTL = 10 * time.Minute
+)
+
+// Scheduler ejecuta los trabajos según sus expresiones cron. Nunca corre dos
+// trabajos a la vez: los que vencen mientras otro está en curso quedan
+// pendientes y se ejecutan al terminar, en el orden de Jobs, y si un trabajo
+// vuelve a vencer antes de ejecutarse se corre una sola vez. Antes de cada
+// trabajo se toma su lease, así que con varias réplicas solo una lo ejecuta.
+type Scheduler struct {
+	Jobs     []domain.Job
+	Leases   domain.LeaseRepository
+	Holder   string
+	LeaseTTL time.Duration
+	// Jitter es la espera aleatoria máxima antes de empezar, para que las
+	// réplicas no compitan todas en el mismo instante.
+	Jitter time.Duration
+	Now    func() time.Time
+
+	mu      sync.Mutex
+	pending map[string]time.Time
+	running bool
+	wg      sync.WaitGroup
+}
+
+// NewScheduler toma el jitter de SCHEDULER_JITTER_SEC (por defecto: 30) y la
+// duración del lease de SCHEDULER_LEASE_TTL_SEC (por defecto: 600).
+func NewScheduler(jobs []domain.Job, leases domain.LeaseRepository, holder string) *Scheduler {
+	ttl := envSeconds("SCHEDULER_LEASE_TTL_SEC", defaultLeaseTTL)
+	if ttl <= 0 {
+		ttl = defaultLeaseTTL
+	}
+
+	return &Scheduler{
+		Jobs:     jobs,
+		Leases:   leases,
+		Holder:   holder,
+		LeaseTTL: ttl,
+		Jitter:   envSeconds("SCHEDULER_JITTER_SEC", defaultJitter),
+		Now:      time.Now,
+	}
+}
+
+// Run bloquea hasta que se cancela ctx. Al cancelarse, el trabajo en curso
+// recibe la cancelación, se descartan los pendientes y Run vuelve cuando ese
+// trabajo termina.
+func (s *Scheduler) Run(ctx context.Context) error {
+	if len(s.Jobs) == 0 {
+		return errors.New("no hay trabajos programados")
+	}
+
+	next := make([]time.Time, len(s.Jobs))
+	now := s.Now()
+	for i, job := range s.Jobs {
+		next[i] = job.Schedule.Next(now)
+		log.Printf("⏰ Trabajo %s programado con %q, próxima ejecución: %s", job.Name, job.Schedule.Expr, next[i].Format(time.RFC3339))
+	}
+
+	for {
+		// Sin próximas ejecuciones (p. ej. "0 0 30 2 *") solo se espera ctx.
+		timer := time.NewTimer(time.Duration(1<<63 - 1))
+		if at, ok := earliest(next); ok {
+			timer.Reset(at.Sub(s.Now()))
+		}
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			log.Println("Deteniendo el scheduler, esperando el trabajo en curso...")
+			s.wg.Wait()
+			return nil
+		case <-timer.C:
+		}
+
+		now := s.Now()
+		for i, job := range s.Jobs {
+			if !next[i].IsZero() && !next[i].After(now) {
+				s.enqueue(job.Name, next[i])
+				next[i] = job.Schedule.Next(now)
+			}
+		}
+		s.start(ctx)
+	}
+}
+
+func earliest(times []time.Time) (time.Time, bool) {
+	var first time.Time
+	for _, t := range times {
+		if !t.IsZero() && (first.IsZero() || t.Before(first)) {
+			first = t
+		}
+	}
+	return first, !first.IsZero()
+}
+
+// enqueue deja el trabajo pendiente para la ejecución programada en at.
+func (s *Scheduler) enqueue(name string, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.pending == nil {
+		s.pending = make(map[string]time.Time)
+	}
+	if prev, ok := s.pending[name]; ok {
+		log.Printf("%s sigue pendiente desde %s, se ejecutará una sola vez", name, prev.Format(time.RFC3339))
+	}
+	s.pending[name] = at
+}
+
+// start lanza el worker si no hay uno corriendo.
+func (s *Scheduler) start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running || len(s.pending) == 0 {
+		return
+	}
+	s.running = true
+	s.wg.Add(1)
+	go s.work(ctx)
+}
+
+func (s *Scheduler) work(ctx context.Context) {
+	defer s.wg.Done()
+
+	if s.Jitter > 0 {
+		wait := time.Duration(rand.Int64N(int64(s.Jitter)))
+		select {
+		case <-ctx.Done():
+		case <-time.After(wait):
+		}
+	}
+
+	for {
+		job, at, ok := s.nextPending(ctx)
+		if !ok {
+			return
+		}
+		s.runJob(ctx, job, at)
+	}
+}
+
+// nextPending saca el primer trabajo pendiente según el orden de Jobs. Si no
+// quedan, o si ctx se canceló, marca al worker como terminado.
+func (s *Scheduler) nextPending(ctx context.Context) (domain.Job, time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ctx.Err() != nil {
+		s.pending = nil
+	}
+	for _, job := range s.Jobs {
+		if at, ok := s.pending[job.Name]; ok {
+			delete(s.pending, job.Name)
+			return job, at, true
+		}
+	}
+	s.running = false
+	return domain.Job{}, time.Time{}, false
+}
+
+func (s *Scheduler) runJob(ctx context.Context, job domain.Job, at time.Time) {
+	ok, err := s.Leases.Acquire(job.Name, s.Holder, at, s.LeaseTTL)
+	if err != nil {
+		log.Printf("Error tomando el lease de %s: %v", job.Name, err)
+		return
+	}
+	if !ok {
+		log.Printf("%s de las %s ya lo ejecuta otra réplica", job.Name, at.Format(time.RFC3339))
+		return
+	}
+
+	log.Printf("▶️  Iniciando %s (programado %s)", job.Name, at.Format(time.RFC3339))
+	started := time.Now()
+	err = runHoldingLease(ctx, s.Leases, job.Name, s.Holder, s.LeaseTTL, func(ctx context.Context) error {
+		return job.Run(ctx, func(domain.Progress) {})
+	})
+
+	switch {
+	case err != nil && ctx.Err() != nil:
+		log.Printf("%s cancelado tras %v: %v", job.Name, time.Since(started).Round(time.Second), err)
+	case err != nil:
+		log.Printf("Error ejecutando %s: %v", job.Name, err)
+	default:
+		log.Printf("✅ %s completado en %v", job.Name, time.Since(started).Round(time.Second))
+	}
+}
+
+func envSeconds(key string, fallback time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			return time.Duration(n) * time.Second
+		}
+	}
+	return fallback
+}
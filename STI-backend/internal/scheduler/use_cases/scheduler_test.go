@@ -0,0 +1,160 @@
+package use_cases
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viteant/stockinsight/internal/scheduler/domain"
+)
+
+type fakeLeases struct {
+	mu       sync.Mutex
+	taken    map[string]string
+	released []string
+}
+
+func (f *fakeLeases) Acquire(name, holder string, scheduledAt time.Time, ttl time.Duration) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.taken == nil {
+		f.taken = map[string]string{}
+	}
+	if h, ok := f.taken[name]; ok && h != holder {
+		return false, nil
+	}
+	f.taken[name] = holder
+	return true, nil
+}
+
+func (f *fakeLeases) Renew(name, holder string, ttl time.Duration) error {
+	return nil
+}
+
+func (f *fakeLeases) Release(name, holder string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.taken, name)
+	f.released = append(f.released, name)
+	return nil
+}
+
+type recorder struct {
+	mu  sync.Mutex
+	ran []string
+}
+
+func (r *recorder) job(name string, run func(ctx context.Context) error) domain.Job {
+	return domain.Job{Name: name, Run: func(ctx context.Context, report func(domain.Progress)) error {
+		r.mu.Lock()
+		r.ran = append(r.ran, name)
+		r.mu.Unlock()
+		if run != nil {
+			return run(ctx)
+		}
+		return nil
+	}}
+}
+
+func newTestScheduler(leases domain.LeaseRepository, jobs ...domain.Job) *Scheduler {
+	return &Scheduler{Jobs: jobs, Leases: leases, Holder: "test", LeaseTTL: time.Minute, Now: time.Now}
+}
+
+func TestScheduler_RunsPendingJobsInOrder(t *testing.T) {
+	rec := &recorder{}
+	s := newTestScheduler(&fakeLeases{},
+		rec.job(domain.JobSync, nil), rec.job(domain.JobFinance, nil), rec.job(domain.JobRescore, nil))
+
+	at := time.Date(2024, 7, 3, 22, 30, 0, 0, time.UTC)
+	s.enqueue(domain.JobRescore, at)
+	s.enqueue(domain.JobSync, at)
+	s.enqueue(domain.JobFinance, at)
+	s.start(context.Background())
+	s.wg.Wait()
+
+	assert.Equal(t, []string{domain.JobSync, domain.JobFinance, domain.JobRescore}, rec.ran)
+}
+
+func TestScheduler_CoalescesJobsDueWhileRunning(t *testing.T) {
+	rec := &recorder{}
+	release := make(chan struct{})
+	started := make(chan struct{})
+	s := newTestScheduler(&fakeLeases{},
+		rec.job(domain.JobSync, func(ctx context.Context) error {
+			select {
+			case started <- struct{}{}:
+			default:
+			}
+			<-release
+			return nil
+		}),
+		rec.job(domain.JobFinance, nil))
+
+	ctx := context.Background()
+	at := time.Date(2024, 7, 3, 10, 0, 0, 0, time.UTC)
+	s.enqueue(domain.JobSync, at)
+	s.start(ctx)
+	<-started
+
+	// Mientras sync corre vencen sync (dos veces) y finance.
+	s.enqueue(domain.JobSync, at.Add(30*time.Minute))
+	s.enqueue(domain.JobFinance, at.Add(30*time.Minute))
+	s.enqueue(domain.JobSync, at.Add(time.Hour))
+	s.start(ctx)
+	close(release)
+	s.wg.Wait()
+
+	assert.Equal(t, []string{domain.JobSync, domain.JobSync, domain.JobFinance}, rec.ran)
+}
+
+func TestScheduler_SkipsJobLeasedByAnotherReplica(t *testing.T) {
+	rec := &recorder{}
+	leases := &fakeLeases{taken: map[string]string{domain.JobSync: "otra"}}
+	s := newTestScheduler(leases, rec.job(domain.JobSync, nil), rec.job(domain.JobFinance, nil))
+
+	at := time.Date(2024, 7, 3, 10, 0, 0, 0, time.UTC)
+	s.enqueue(domain.JobSync, at)
+	s.enqueue(domain.JobFinance, at)
+	s.start(context.Background())
+	s.wg.Wait()
+
+	assert.Equal(t, []string{domain.JobFinance}, rec.ran)
+	assert.Equal(t, []string{domain.JobFinance}, leases.released)
+}
+
+func TestScheduler_CancelStopsRunningJobAndDropsPending(t *testing.T) {
+	rec := &recorder{}
+	leases := &fakeLeases{}
+	started := make(chan struct{})
+	s := newTestScheduler(leases,
+		rec.job(domain.JobSync, func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		}),
+		rec.job(domain.JobFinance, nil))
+	s.Jobs[0].Schedule, _ = domain.ParseCron("* * * * *")
+	s.Jobs[1].Schedule, _ = domain.ParseCron("* * * * *")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	at := time.Date(2024, 7, 3, 10, 0, 0, 0, time.UTC)
+	s.enqueue(domain.JobSync, at)
+	s.enqueue(domain.JobFinance, at)
+	s.start(ctx)
+	<-started
+
+	done := make(chan error)
+	go func() { done <- s.Run(ctx) }()
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("el scheduler no se detuvo")
+	}
+	assert.Equal(t, []string{domain.JobSync}, rec.ran)
+	assert.Equal(t, []string{domain.JobSync}, leases.released)
+}
@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"math"
+	"strings"
+)
+
+// Tipos normalizados de Stock.Action.
+const (
+	ActionUpgrade       = "upgrade"
+	ActionDowngrade     = "downgrade"
+	ActionInitiation    = "initiation"
+	ActionReiteration   = "reiteration"
+	ActionTargetRaised  = "target_raised"
+	ActionTargetLowered = "target_lowered"
+	ActionTargetSet     = "target_set"
+	ActionOther         = "other"
+)
+
+var ActionTypes = []string{
+	ActionUpgrade,
+	ActionDowngrade,
+	ActionInitiation,
+	ActionReiteration,
+	ActionTargetRaised,
+	ActionTargetLowered,
+	ActionTargetSet,
+	ActionOther,
+}
+
+// ClassifyAction da prioridad a un cambio en la calificación normalizada
+// (sell < hold < buy); si no cambió, usa el texto de la acción del feed.
+func ClassifyAction(action, normalizedFrom, normalizedTo string) string {
+	from, to := ratingRank(normalizedFrom), ratingRank(normalizedTo)
+	if normalizedFrom != "" && normalizedTo != "" && from != to {
+		if to > from {
+			return ActionUpgrade
+		}
+		return ActionDowngrade
+	}
+
+	a := strings.ToLower(strings.TrimSpace(action))
+	switch {
+	case strings.HasPrefix(a, "upgraded"):
+		return ActionUpgrade
+	case strings.HasPrefix(a, "downgraded"):
+		return ActionDowngrade
+	case strings.HasPrefix(a, "initiated"):
+		return ActionInitiation
+	case strings.HasPrefix(a, "reiterated"):
+		return ActionReiteration
+	case strings.HasPrefix(a, "target raised"):
+		return ActionTargetRaised
+	case strings.HasPrefix(a, "target lowered"):
+		return ActionTargetLowered
+	case strings.HasPrefix(a, "target set"):
+		return ActionTargetSet
+	}
+	return ActionOther
+}
+
+// TargetChangePct es la variación porcentual de target_from a target_to, o
+// nil si no hay target anterior.
+func TargetChangePct(from, to float32) *float64 {
+	if from <= 0 || to <= 0 {
+		return nil
+	}
+	pct := math.Round(float64(to-from)/float64(from)*100*10000) / 10000
+	return &pct
+}
+
+// Classify completa ActionType y TargetChangePct a partir de los demás campos.
+func (s *Stock) Classify() {
+	s.ActionType = ClassifyAction(s.Action, s.NormalizeRatingFrom, s.NormalizeRatingTo)
+	s.TargetChangePct = TargetChangePct(s.TargetFrom, s.TargetTo)
+}
+
+func ratingRank(rating string) int {
+	switch rating {
+	case "buy":
+		return 1
+	case "sell":
+		return -1
+	}
+	return 0
+}
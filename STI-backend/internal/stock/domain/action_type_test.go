@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyAction(t *testing.T) {
+	cases := []struct {
+		action, from, to string
+		want             string
+	}{
+		{"upgraded by", "hold", "buy", ActionUpgrade},
+		{"downgraded by", "buy", "hold", ActionDowngrade},
+		{"initiated by", "buy", "buy", ActionInitiation},
+		{"reiterated by", "hold", "hold", ActionReiteration},
+		{"target raised by", "buy", "buy", ActionTargetRaised},
+		{"target lowered by", "buy", "buy", ActionTargetLowered},
+		{"target set by", "", "", ActionTargetSet},
+		// Un cambio de calificación manda sobre el texto del feed.
+		{"target raised by", "hold", "buy", ActionUpgrade},
+		{"Upgraded By", "", "", ActionUpgrade},
+		{"coverage dropped", "", "", ActionOther},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.want, ClassifyAction(c.action, c.from, c.to), c.action)
+	}
+}
+
+func TestTargetChangePct(t *testing.T) {
+	assert.Equal(t, 25.0, *TargetChangePct(80, 100))
+	assert.Equal(t, -5.8824, *TargetChangePct(17, 16))
+	assert.Nil(t, TargetChangePct(0, 100))
+}
@@ -0,0 +1,206 @@
+package domain
+
+import (
+	"math"
+
+	financedomain "github.com/viteant/stockinsight/internal/finance/domain"
+)
+
+// ScoreMethodBetaBinomial identifica el puntaje de precisión encogido hacia
+// la media del grupo con un prior Beta.
+const ScoreMethodBetaBinomial = "beta_binomial"
+
+// ScoreMethodPrior identifica a los brokers sin puntaje calculado, que se
+// ordenan con la media del prior.
+const ScoreMethodPrior = "prior"
+
+// ScoreMethodTimeDecaySuffix se agrega al método cuando las recomendaciones
+// aplican decaimiento temporal al puntaje.
+const ScoreMethodTimeDecaySuffix = "+time_decay"
+
+// BrokerRecord son los aciertos de un brokerage a un horizonte y base de
+// precio. HorizonDays 0 corresponde a la vista broker_evaluation.
+type BrokerRecord struct {
+	Brokerage   string
+	HorizonDays int
+	PriceBasis  financedomain.PriceBasis
+	Predictions int
+	Hits        int
+}
+
+type BrokerScore struct {
+	Brokerage   string                   `json:"brokerage"`
+	HorizonDays int                      `json:"horizon_days"`
+	PriceBasis  financedomain.PriceBasis `json:"price_basis"`
+	Predictions int                      `json:"total_predictions"`
+	Hits        int                      `json:"total_hits"`
+	RawAccuracy float64                  `json:"raw_accuracy"`
+	PriorAlpha  float64                  `json:"prior_alpha"`
+	PriorBeta   float64                  `json:"prior_beta"`
+	Score       float64                  `json:"score"`
+	CILow       float64                  `json:"ci_low"`
+	CIHigh      float64                  `json:"ci_high"`
+	Method      string                   `json:"method"`
+}
+
+type scoreGroup struct {
+	horizon int
+	basis   financedomain.PriceBasis
+}
+
+// ScoreBrokers calcula para cada registro la media posterior de una
+// Beta-binomial y su intervalo creíble del 95%. El prior de cada grupo
+// (horizonte y base de precio) tiene como media la precisión agregada de
+// todos los brokers del grupo y un peso de priorStrength predicciones, así
+// un broker con 2 o 3 ratings queda cerca de la media y uno con cientos
+// conserva su propia precisión.
+func ScoreBrokers(records []BrokerRecord, priorStrength float64) []BrokerScore {
+	type totals struct{ n, hits int }
+	pooled := make(map[scoreGroup]totals)
+	for _, r := range records {
+		g := scoreGroup{r.HorizonDays, r.PriceBasis}
+		t := pooled[g]
+		t.n += r.Predictions
+		t.hits += r.Hits
+		pooled[g] = t
+	}
+
+	scores := make([]BrokerScore, 0, len(records))
+	for _, r := range records {
+		t := pooled[scoreGroup{r.HorizonDays, r.PriceBasis}]
+		mean := 0.5
+		if t.n > 0 {
+			mean = float64(t.hits) / float64(t.n)
+		}
+		// Evita priors degenerados cuando todos aciertan o todos fallan.
+		mean = math.Min(math.Max(mean, 0.01), 0.99)
+
+		alpha0 := mean * priorStrength
+		beta0 := (1 - mean) * priorStrength
+		alpha := alpha0 + float64(r.Hits)
+		beta := beta0 + float64(r.Predictions-r.Hits)
+
+		ciLow, ciHigh := CredibleInterval(alpha, beta)
+
+		raw := 0.0
+		if r.Predictions > 0 {
+			raw = float64(r.Hits) / float64(r.Predictions)
+		}
+
+		scores = append(scores, BrokerScore{
+			Brokerage:   r.Brokerage,
+			HorizonDays: r.HorizonDays,
+			PriceBasis:  r.PriceBasis,
+			Predictions: r.Predictions,
+			Hits:        r.Hits,
+			RawAccuracy: round4(raw),
+			PriorAlpha:  round4(alpha0),
+			PriorBeta:   round4(beta0),
+			Score:       round4(alpha / (alpha + beta)),
+			CILow:       ciLow,
+			CIHigh:      ciHigh,
+			Method:      ScoreMethodBetaBinomial,
+		})
+	}
+	return scores
+}
+
+// CredibleInterval devuelve el intervalo creíble del 95% de una Beta(alpha,
+// beta), redondeado a 4 decimales.
+func CredibleInterval(alpha, beta float64) (low, high float64) {
+	return round4(BetaQuantile(0.025, alpha, beta)), round4(BetaQuantile(0.975, alpha, beta))
+}
+
+// BetaQuantile invierte por bisección la función de distribución de una Beta(a, b).
+func BetaQuantile(p, a, b float64) float64 {
+	lo, hi := 0.0, 1.0
+	for i := 0; i < 60; i++ {
+		mid := (lo + hi) / 2
+		if RegularizedIncompleteBeta(mid, a, b) < p {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
+
+// RegularizedIncompleteBeta es I_x(a, b), la función de distribución de una
+// Beta(a, b), evaluada con la fracción continua de Lentz.
+func RegularizedIncompleteBeta(x, a, b float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+
+	lga, _ := math.Lgamma(a)
+	lgb, _ := math.Lgamma(b)
+	lgab, _ := math.Lgamma(a + b)
+	front := math.Exp(lgab - lga - lgb + a*math.Log(x) + b*math.Log(1-x))
+
+	// La fracción converge rápido para x < (a+1)/(a+b+2); si no, se usa la simetría.
+	if x < (a+1)/(a+b+2) {
+		return front * betaContinuedFraction(x, a, b) / a
+	}
+	return 1 - front*betaContinuedFraction(1-x, b, a)/b
+}
+
+func betaContinuedFraction(x, a, b float64) float64 {
+	const (
+		maxIterations = 300
+		epsilon       = 1e-14
+		tiny          = 1e-300
+	)
+
+	qab := a + b
+	qap := a + 1
+	qam := a - 1
+	c := 1.0
+	d := 1 - qab*x/qap
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+
+	for m := 1; m <= maxIterations; m++ {
+		fm := float64(m)
+		m2 := 2 * fm
+
+		aa := fm * (b - fm) * x / ((qam + m2) * (a + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+
+		aa = -(a + fm) * (qab + fm) * x / ((a + m2) * (qap + m2))
+		d = 1 + aa*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + aa/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+
+		if math.Abs(del-1) < epsilon {
+			break
+		}
+	}
+	return h
+}
+
+func round4(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
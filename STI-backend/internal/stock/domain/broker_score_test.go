@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBetaQuantile(t *testing.T) {
+	// Beta(1, 1) es uniforme.
+	assert.InDelta(t, 0.025, BetaQuantile(0.025, 1, 1), 1e-6)
+	// Beta(2, 1) tiene F(x) = x², la mediana es √0.5.
+	assert.InDelta(t, 0.7071, BetaQuantile(0.5, 2, 1), 1e-4)
+	// Beta(10, 10) es simétrica.
+	assert.InDelta(t, 1-BetaQuantile(0.025, 10, 10), BetaQuantile(0.975, 10, 10), 1e-6)
+}
+
+func TestCredibleInterval(t *testing.T) {
+	low, high := CredibleInterval(1, 1)
+	assert.Equal(t, 0.025, low)
+	assert.Equal(t, 0.975, high)
+
+	// Menos evidencia efectiva (decaimiento) ensancha el intervalo.
+	fullLow, fullHigh := CredibleInterval(41, 21)
+	decLow, decHigh := CredibleInterval(11, 6)
+	assert.Greater(t, decHigh-decLow, fullHigh-fullLow)
+}
+
+func TestScoreBrokers_ShrinksSmallSamples(t *testing.T) {
+	records := []BrokerRecord{
+		{Brokerage: "Small", PriceBasis: "raw", Predictions: 2, Hits: 2},
+		{Brokerage: "Large", PriceBasis: "raw", Predictions: 200, Hits: 140},
+		{Brokerage: "Other", PriceBasis: "raw", Predictions: 98, Hits: 38},
+	}
+
+	scores := ScoreBrokers(records, 10)
+	small, large := scores[0], scores[1]
+
+	// Precisión agregada del grupo: 180 / 300 = 0.6.
+	assert.InDelta(t, 6, small.PriorAlpha, 1e-9)
+	assert.InDelta(t, 4, small.PriorBeta, 1e-9)
+
+	// 2 de 2 queda cerca del prior; 140 de 200 casi no se mueve.
+	assert.InDelta(t, 8.0/12.0, small.Score, 1e-4)
+	assert.Less(t, small.Score, large.Score)
+	assert.InDelta(t, 146.0/210.0, large.Score, 1e-4)
+
+	// El intervalo del broker con pocas predicciones es más ancho.
+	assert.Greater(t, small.CIHigh-small.CILow, large.CIHigh-large.CILow)
+	assert.Less(t, large.CILow, large.Score)
+	assert.Greater(t, large.CIHigh, large.Score)
+	assert.Equal(t, ScoreMethodBetaBinomial, large.Method)
+}
@@ -0,0 +1,155 @@
+package domain
+
+import (
+	"math"
+	"sort"
+
+	financedomain "github.com/viteant/stockinsight/internal/finance/domain"
+)
+
+const (
+	// DefaultBrokerWeight es el peso de un brokerage sin puntaje en
+	// broker_scores: la media de un prior Beta(1, 1).
+	DefaultBrokerWeight = 0.5
+
+	DefaultConsensusLimit = 50
+	MaxConsensusLimit     = 500
+)
+
+// ConsensusParams son las opciones de consulta de consenso. Ticker vacío
+// devuelve todos los tickers.
+type ConsensusParams struct {
+	Ticker      string
+	PriceBasis  financedomain.PriceBasis
+	HorizonDays int
+	Limit       int
+}
+
+// TickerConsensus agrega el último rating de cada brokerage sobre un ticker.
+// Las estadísticas de target usan solo los targets en TargetCurrency, la
+// moneda más usada por los brokers. ImpliedUpside es el porcentaje entre el
+// último cierre y el target medio; los cierres de finances están en USD, así
+// que solo se calcula con targets en USD. Score va de -1 (todos sell) a 1
+// (todos buy), ponderando cada brokerage por su puntaje.
+type TickerConsensus struct {
+	Ticker  string `json:"ticker"`
+	Company string `json:"company"`
+	Consensus
+	TargetCurrency string       `json:"target_currency,omitempty"`
+	TargetMean     *float64     `json:"target_mean"`
+	TargetMedian   *float64     `json:"target_median"`
+	TargetHigh     *float64     `json:"target_high"`
+	TargetLow      *float64     `json:"target_low"`
+	LatestClose    *LatestClose `json:"latest_close"`
+	ImpliedUpside  *float64     `json:"implied_upside"`
+	Score          float64      `json:"score"`
+	Ratings        []Stock      `json:"ratings"`
+}
+
+// BuildTickerConsensus recibe los ratings de un ticker; weights es el puntaje
+// de cada brokerage.
+func BuildTickerConsensus(ratings []Stock, latestClose *LatestClose, weights map[string]float64) TickerConsensus {
+	latest := LatestByBrokerage(ratings)
+
+	tc := TickerConsensus{
+		Consensus:   BuildConsensus(latest),
+		LatestClose: latestClose,
+		Ratings:     latest,
+	}
+	if len(latest) == 0 {
+		return tc
+	}
+	tc.Ticker = latest[0].Ticker
+	tc.Company = latest[0].Company
+
+	tc.TargetCurrency = targetCurrency(latest)
+
+	var targets []float64
+	var weighted, totalWeight float64
+	for _, r := range latest {
+		if r.TargetTo > 0 && ratingCurrency(r) == tc.TargetCurrency {
+			targets = append(targets, float64(r.TargetTo))
+		}
+
+		w, ok := weights[r.Brokerage]
+		if !ok {
+			w = DefaultBrokerWeight
+		}
+		weighted += w * ratingValue(r.NormalizeRatingTo)
+		totalWeight += w
+	}
+	if totalWeight > 0 {
+		tc.Score = round4(weighted / totalWeight)
+	}
+
+	if len(targets) > 0 {
+		sort.Float64s(targets)
+		var sum float64
+		for _, t := range targets {
+			sum += t
+		}
+		mean := round2(sum / float64(len(targets)))
+		median := targets[len(targets)/2]
+		if len(targets)%2 == 0 {
+			median = (targets[len(targets)/2-1] + median) / 2
+		}
+		median = round2(median)
+		low, high := round2(targets[0]), round2(targets[len(targets)-1])
+
+		tc.TargetMean = &mean
+		tc.TargetMedian = &median
+		tc.TargetLow = &low
+		tc.TargetHigh = &high
+
+		if latestClose != nil && latestClose.Close > 0 && tc.TargetCurrency == DefaultCurrency {
+			upside := round2((mean - float64(latestClose.Close)) / float64(latestClose.Close) * 100)
+			tc.ImpliedUpside = &upside
+		}
+	}
+
+	return tc
+}
+
+// targetCurrency devuelve la moneda de la mayoría de los targets. En un
+// empate gana DefaultCurrency y, si no está, la primera alfabéticamente.
+func targetCurrency(ratings []Stock) string {
+	counts := make(map[string]int)
+	for _, r := range ratings {
+		if r.TargetTo > 0 {
+			counts[ratingCurrency(r)]++
+		}
+	}
+
+	best := ""
+	for currency, n := range counts {
+		switch {
+		case best == "", n > counts[best]:
+			best = currency
+		case n == counts[best] && best != DefaultCurrency && (currency == DefaultCurrency || currency < best):
+			best = currency
+		}
+	}
+	return best
+}
+
+// ratingCurrency trata como DefaultCurrency los targets sin moneda guardada.
+func ratingCurrency(r Stock) string {
+	if r.Currency == "" {
+		return DefaultCurrency
+	}
+	return r.Currency
+}
+
+func ratingValue(rating string) float64 {
+	switch rating {
+	case "buy":
+		return 1
+	case "sell":
+		return -1
+	}
+	return 0
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildTickerConsensus(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 7, d, 0, 0, 0, 0, time.UTC) }
+
+	ratings := []Stock{
+		{Ticker: "AAA", Company: "Acme", Brokerage: "A", NormalizeRatingTo: "sell", TargetTo: 80, ReportedAt: day(1)},
+		{Ticker: "AAA", Company: "Acme", Brokerage: "A", NormalizeRatingTo: "buy", TargetTo: 120, ReportedAt: day(5)},
+		{Ticker: "AAA", Company: "Acme", Brokerage: "B", NormalizeRatingTo: "hold", TargetTo: 100, ReportedAt: day(2)},
+		{Ticker: "AAA", Company: "Acme", Brokerage: "C", NormalizeRatingTo: "sell", TargetTo: 90, ReportedAt: day(3)},
+	}
+	weights := map[string]float64{"A": 0.8, "C": 0.2}
+
+	tc := BuildTickerConsensus(ratings, &LatestClose{Date: day(8), Close: 100}, weights)
+
+	assert.Equal(t, "AAA", tc.Ticker)
+	assert.Len(t, tc.Ratings, 3)
+	assert.Equal(t, Consensus{Buy: 1, Hold: 1, Sell: 1, Brokers: 3, Rating: "hold"}, tc.Consensus)
+	assert.Equal(t, 103.33, *tc.TargetMean)
+	assert.Equal(t, 100.0, *tc.TargetMedian)
+	assert.Equal(t, 90.0, *tc.TargetLow)
+	assert.Equal(t, 120.0, *tc.TargetHigh)
+	assert.Equal(t, 3.33, *tc.ImpliedUpside)
+	// (0.8·1 + 0.5·0 + 0.2·-1) / 1.5; B no tiene puntaje y pesa 0.5.
+	assert.InDelta(t, 0.4, tc.Score, 1e-4)
+}
+
+func TestBuildTickerConsensus_TargetsInOneCurrency(t *testing.T) {
+	cases := []struct {
+		name     string
+		ratings  []Stock
+		currency string
+		mean     *float64
+		upside   bool
+	}{
+		{
+			name: "mayoría en EUR: no se promedian los USD ni se calcula upside",
+			ratings: []Stock{
+				{Brokerage: "A", TargetTo: 100, Currency: "EUR"},
+				{Brokerage: "B", TargetTo: 110, Currency: "EUR"},
+				{Brokerage: "C", TargetTo: 500, Currency: "USD"},
+			},
+			currency: "EUR",
+			mean:     ptr(105.0),
+		},
+		{
+			name: "empate: gana USD",
+			ratings: []Stock{
+				{Brokerage: "A", TargetTo: 100, Currency: "EUR"},
+				{Brokerage: "B", TargetTo: 120},
+			},
+			currency: "USD",
+			mean:     ptr(120.0),
+			upside:   true,
+		},
+		{
+			name: "empate sin USD: la primera alfabéticamente",
+			ratings: []Stock{
+				{Brokerage: "A", TargetTo: 100, Currency: "GBP"},
+				{Brokerage: "B", TargetTo: 120, Currency: "EUR"},
+			},
+			currency: "EUR",
+			mean:     ptr(120.0),
+		},
+		{
+			name:    "sin targets",
+			ratings: []Stock{{Brokerage: "A", Currency: "EUR"}},
+		},
+	}
+	for _, c := range cases {
+		for i := range c.ratings {
+			c.ratings[i].Ticker = "AAA"
+		}
+		tc := BuildTickerConsensus(c.ratings, &LatestClose{Close: 100}, nil)
+
+		assert.Equal(t, c.currency, tc.TargetCurrency, c.name)
+		assert.Equal(t, c.mean, tc.TargetMean, c.name)
+		assert.Equal(t, c.upside, tc.ImpliedUpside != nil, c.name)
+	}
+}
+
+func ptr(v float64) *float64 { return &v }
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Errores de la API externa de ratings.
+var (
+	// ErrUnauthorized indica que la API rechazó el token (401 o 403).
+	ErrUnauthorized = errors.New("ratings API rejected the credentials")
+	// ErrRateLimited indica que la API respondió 429 y se agotaron los reintentos.
+	ErrRateLimited = errors.New("rate limited by ratings API")
+	// ErrUpstream indica que la API no respondió, respondió 5xx, algo que no es
+	// JSON, o que el circuito está abierto tras fallas consecutivas.
+	ErrUpstream = errors.New("ratings API unavailable")
+)
+
+// RateLimitError es un ErrRateLimited con la espera que pidió la API en
+// Retry-After (0 si no la indicó).
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%v (Retry-After: %v)", ErrRateLimited, e.RetryAfter)
+	}
+	return ErrRateLimited.Error()
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
@@ -1,25 +1,123 @@
 package domain
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
-func NormalizeBrokerRating(raw string) string {
-	r := strings.ToLower(strings.TrimSpace(raw))
+// Escala de 5 niveles. La de 3 niveles (buy, hold, sell) se obtiene uniendo
+// strong_buy con buy y strong_sell con sell.
+const (
+	RatingStrongBuy  = "strong_buy"
+	RatingBuy        = "buy"
+	RatingHold       = "hold"
+	RatingSell       = "sell"
+	RatingStrongSell = "strong_sell"
+)
 
-	switch r {
-	case "buy", "strong-buy", "outperform", "outperformer",
-		"market outperform", "mkt outperform", "overweight",
-		"positive", "sector outperform", "speculative buy", "moderate buy":
-		return "buy"
+var RatingLevels = []string{RatingStrongBuy, RatingBuy, RatingHold, RatingSell, RatingStrongSell}
 
-	case "hold", "neutral", "equal weight", "in-line",
-		"market perform", "peer perform", "sector perform", "sector weight", "":
-		return "hold"
+// NormalizedRating es una calificación en ambas escalas.
+type NormalizedRating struct {
+	Level3 string
+	Level5 string
+}
+
+// RatingMapping traduce las calificaciones de los brokers a la escala de 5
+// niveles. Version identifica el archivo de mapeo con que se normalizó cada fila.
+type RatingMapping struct {
+	Version int
+	levels  map[string]string
+}
+
+// NewRatingMapping recibe, por cada nivel de la escala de 5, las
+// calificaciones del feed que le corresponden.
+func NewRatingMapping(version int, levels map[string][]string) (*RatingMapping, error) {
+	m := &RatingMapping{Version: version, levels: make(map[string]string)}
+	for level, raws := range levels {
+		if !isRatingLevel(level) {
+			return nil, fmt.Errorf("nivel de rating desconocido: %q", level)
+		}
+		for _, raw := range raws {
+			key := RatingKey(raw)
+			if prev, ok := m.levels[key]; ok && prev != level {
+				return nil, fmt.Errorf("el rating %q está en %q y en %q", raw, prev, level)
+			}
+			m.levels[key] = level
+		}
+	}
+	return m, nil
+}
 
-	case "sell", "underweight", "underperform", "underperformer",
-		"sector underperform", "reduce", "negative":
-		return "sell"
+// Normalize devuelve false si raw no está en el mapeo; en ese caso la
+// calificación queda como hold. Un rating vacío es hold.
+func (m *RatingMapping) Normalize(raw string) (NormalizedRating, bool) {
+	key := RatingKey(raw)
+	if key == "" {
+		return NormalizedRating{Level3: RatingHold, Level5: RatingHold}, true
+	}
+
+	level, ok := m.levels[key]
+	if !ok {
+		return NormalizedRating{Level3: RatingHold, Level5: RatingHold}, false
+	}
+	return NormalizedRating{Level3: toLevel3(level), Level5: level}, true
+}
+
+// NormalizeRatings completa las calificaciones normalizadas del rating y
+// devuelve las que no están en el mapeo.
+func (s *Stock) NormalizeRatings(m *RatingMapping) []string {
+	var unmapped []string
 
-	default:
-		return "hold"
+	from, ok := m.Normalize(s.RatingFrom)
+	if !ok {
+		unmapped = append(unmapped, s.RatingFrom)
+	}
+	to, ok := m.Normalize(s.RatingTo)
+	if !ok && RatingKey(s.RatingTo) != RatingKey(s.RatingFrom) {
+		unmapped = append(unmapped, s.RatingTo)
 	}
+
+	s.NormalizeRatingFrom = from.Level3
+	s.NormalizeRatingTo = to.Level3
+	s.NormalizeRatingFrom5 = from.Level5
+	s.NormalizeRatingTo5 = to.Level5
+	s.RatingMappingVersion = m.Version
+	return unmapped
+}
+
+func toLevel3(level string) string {
+	switch level {
+	case RatingStrongBuy:
+		return RatingBuy
+	case RatingStrongSell:
+		return RatingSell
+	}
+	return level
+}
+
+func isRatingLevel(level string) bool {
+	for _, l := range RatingLevels {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
+
+// RatingKey es la forma canónica de un rating: sin distinguir mayúsculas ni
+// espacios repetidos.
+func RatingKey(raw string) string {
+	return strings.Join(strings.Fields(strings.ToLower(raw)), " ")
+}
+
+// UnmappedRating es una calificación del feed que no está en el mapeo.
+type UnmappedRating struct {
+	RawRating      string    `json:"raw_rating"`
+	Occurrences    int       `json:"occurrences"`
+	LastBrokerage  string    `json:"last_brokerage"`
+	MappingVersion int       `json:"mapping_version"`
+	FirstSeenAt    time.Time `json:"first_seen_at"`
+	LastSeenAt     time.Time `json:"last_seen_at"`
 }
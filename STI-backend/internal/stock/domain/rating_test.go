@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStockNormalizeRatings(t *testing.T) {
+	m, err := NewRatingMapping(3, map[string][]string{
+		RatingStrongBuy: {"Strong-Buy"},
+		RatingBuy:       {"buy"},
+		RatingHold:      {"neutral"},
+	})
+	assert.NoError(t, err)
+
+	s := Stock{RatingFrom: "Neutral", RatingTo: "strong-buy"}
+	assert.Empty(t, s.NormalizeRatings(m))
+	assert.Equal(t, "hold", s.NormalizeRatingFrom)
+	assert.Equal(t, "buy", s.NormalizeRatingTo)
+	assert.Equal(t, "strong_buy", s.NormalizeRatingTo5)
+	assert.Equal(t, 3, s.RatingMappingVersion)
+
+	// Un rating desconocido queda como hold y se informa una sola vez.
+	s = Stock{RatingFrom: "Top Pick", RatingTo: "top pick"}
+	assert.Equal(t, []string{"Top Pick"}, s.NormalizeRatings(m))
+	assert.Equal(t, "hold", s.NormalizeRatingTo)
+
+	// El rating vacío es hold y no se informa.
+	s = Stock{RatingFrom: "", RatingTo: "buy"}
+	assert.Empty(t, s.NormalizeRatings(m))
+	assert.Equal(t, "hold", s.NormalizeRatingFrom5)
+}
@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RawStock es un rating tal como lo entrega la API externa.
+type RawStock struct {
+	Ticker     string `json:"ticker"`
+	Company    string `json:"company"`
+	Brokerage  string `json:"brokerage"`
+	Action     string `json:"action"`
+	RatingFrom string `json:"rating_from"`
+	RatingTo   string `json:"rating_to"`
+	TargetFrom string `json:"target_from"`
+	TargetTo   string `json:"target_to"`
+	Time       string `json:"time"`
+}
+
+// FieldError describe por qué un campo no pasó la validación.
+type FieldError struct {
+	Field  string `json:"field"`
+	Value  string `json:"value"`
+	Reason string `json:"reason"`
+}
+
+// ValidationError agrupa los errores de todos los campos de un registro.
+type ValidationError struct {
+	Fields []FieldError `json:"fields"`
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		parts[i] = fmt.Sprintf("%s: %s", f.Field, f.Reason)
+	}
+	return "registro inválido: " + strings.Join(parts, "; ")
+}
+
+func (e *ValidationError) add(field, value, reason string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Value: value, Reason: reason})
+}
+
+// RejectedStock es un rating que no pasó la validación (Err es un
+// *ValidationError) o que no se pudo guardar.
+type RejectedStock struct {
+	Raw RawStock
+	Err error
+}
+
+// StockPage es una página del feed: los ratings válidos, los rechazados y el
+// cursor de la página siguiente.
+type StockPage struct {
+	Stocks   []Stock
+	Rejected []RejectedStock
+	NextPage string
+}
+
+// RawFromStock devuelve el rating en el formato del feed, para guardarlo en
+// cuarentena y reinyectarlo con Parse.
+func RawFromStock(s Stock) RawStock {
+	return RawStock{
+		Ticker:     s.Ticker,
+		Company:    s.Company,
+		Brokerage:  s.Brokerage,
+		Action:     s.Action,
+		RatingFrom: s.RatingFrom,
+		RatingTo:   s.RatingTo,
+		TargetFrom: formatTarget(s.TargetFrom, s.Currency),
+		TargetTo:   formatTarget(s.TargetTo, s.Currency),
+		Time:       s.ReportedAt.UTC().Format(time.RFC3339Nano),
+	}
+}
+
+func formatTarget(amount float32, currency string) string {
+	if amount <= 0 {
+		return ""
+	}
+	return strings.TrimSpace(fmt.Sprintf("%.2f %s", amount, currency))
+}
+
+// Parse valida el rating y convierte sus campos. Devuelve un
+// *ValidationError con todos los campos inválidos.
+func (r RawStock) Parse() (Stock, error) {
+	verr := &ValidationError{}
+
+	ticker := strings.TrimSpace(r.Ticker)
+	if ticker == "" {
+		verr.add("ticker", r.Ticker, "requerido")
+	}
+
+	reportedAt, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(r.Time))
+	if err != nil {
+		verr.add("time", r.Time, "fecha inválida, se espera RFC 3339")
+	}
+
+	from, err := ParseTargetPrice(r.TargetFrom)
+	if err != nil {
+		verr.add("target_from", r.TargetFrom, err.Error())
+	}
+	to, err := ParseTargetPrice(r.TargetTo)
+	if err != nil {
+		verr.add("target_to", r.TargetTo, err.Error())
+	}
+
+	if from.Currency != "" && to.Currency != "" && from.Currency != to.Currency {
+		verr.add("target_to", r.TargetTo, fmt.Sprintf("moneda %s distinta de la de target_from (%s)", to.Currency, from.Currency))
+	}
+
+	if len(verr.Fields) > 0 {
+		return Stock{}, verr
+	}
+
+	currency := to.Currency
+	if currency == "" {
+		currency = from.Currency
+	}
+	if currency == "" && (from.Amount > 0 || to.Amount > 0) {
+		currency = DefaultCurrency
+	}
+
+	return Stock{
+		Ticker:     ticker,
+		Company:    r.Company,
+		Brokerage:  r.Brokerage,
+		Action:     r.Action,
+		RatingFrom: r.RatingFrom,
+		RatingTo:   r.RatingTo,
+		TargetFrom: from.Amount,
+		TargetTo:   to.Amount,
+		Currency:   currency,
+		ReportedAt: reportedAt,
+	}, nil
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	financedomain "github.com/viteant/stockinsight/internal/finance/domain"
+)
+
+// Vidas medias por defecto, en días, del historial del broker y de la
+// antigüedad del rating.
+const (
+	DefaultTrackHalfLifeDays  = 365
+	DefaultRatingHalfLifeDays = 90
+)
+
+// Cantidad de recomendaciones por tipo de rating (buy, hold y sell).
+const (
+	DefaultRecommendationLimit = 10
+	MaxRecommendationLimit     = 100
+)
+
+var ErrInvalidRecommendationParams = errors.New("invalid recommendation params")
+
+// RecommendationParams son las opciones de consulta de recomendaciones.
+type RecommendationParams struct {
+	PriceBasis financedomain.PriceBasis
+	// HorizonDays elige el horizonte (7, 30 o 90 días hábiles) de broker_scores
+	// con el que se ordena a los brokers. Con 0 se usa el cierre más cercano al
+	// rating (broker_evaluation).
+	HorizonDays int
+	// TrackHalfLifeDays y RatingHalfLifeDays son las vidas medias, en días,
+	// con que se descuentan las predicciones pasadas del broker y la
+	// antigüedad del rating. Con 0 no se aplica decaimiento.
+	TrackHalfLifeDays  float64
+	RatingHalfLifeDays float64
+	// Limit es la cantidad por tipo de rating; 0 usa DefaultRecommendationLimit.
+	Limit int
+	// MinPredictions descarta brokers con menos predicciones evaluadas.
+	MinPredictions int
+	// Since descarta ratings anteriores a esa fecha; cero no filtra.
+	Since time.Time
+	// Brokerage filtra por nombre del brokerage (ILIKE).
+	Brokerage string
+	// ActionType filtra por tipo de acción (upgrade, downgrade, ...); vacío no filtra.
+	ActionType string
+	// DistinctTicker deja un solo rating por ticker en cada tipo, el de mayor puntaje.
+	DistinctTicker bool
+}
+
+// Normalize completa los valores por defecto y valida los rangos.
+func (p RecommendationParams) Normalize() (RecommendationParams, error) {
+	if p.PriceBasis == "" {
+		p.PriceBasis = financedomain.PriceBasisRaw
+	}
+	if p.Limit == 0 {
+		p.Limit = DefaultRecommendationLimit
+	}
+	if p.Limit < 0 || p.Limit > MaxRecommendationLimit {
+		return p, fmt.Errorf("%w: limit debe estar entre 1 y %d", ErrInvalidRecommendationParams, MaxRecommendationLimit)
+	}
+	if p.MinPredictions < 0 {
+		return p, fmt.Errorf("%w: min_predictions no puede ser negativo", ErrInvalidRecommendationParams)
+	}
+	if p.ActionType != "" && !slices.Contains(ActionTypes, p.ActionType) {
+		return p, fmt.Errorf("%w: action_type inválido: %q", ErrInvalidRecommendationParams, p.ActionType)
+	}
+	if p.TrackHalfLifeDays < 0 || p.RatingHalfLifeDays < 0 {
+		return p, fmt.Errorf("%w: las vidas medias no pueden ser negativas", ErrInvalidRecommendationParams)
+	}
+	return p, nil
+}
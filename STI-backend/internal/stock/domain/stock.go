@@ -3,16 +3,32 @@ package domain
 import "time"
 
 type Stock struct {
-	ID                  string    `json:"id"`
-	Ticker              string    `json:"ticker"`
-	Company             string    `json:"company"`
-	Brokerage           string    `json:"brokerage"`
-	Action              string    `json:"action"`
-	RatingFrom          string    `json:"rating_from"`
-	RatingTo            string    `json:"rating_to"`
-	NormalizeRatingFrom string    `json:"normalize_rating_from"`
-	NormalizeRatingTo   string    `json:"normalize_rating_to"`
-	TargetFrom          float32   `json:"target_from"`
-	TargetTo            float32   `json:"target_to"`
-	ReportedAt          time.Time `json:"created_at"`
+	ID                  string `json:"id"`
+	Ticker              string `json:"ticker"`
+	Company             string `json:"company"`
+	Brokerage           string `json:"brokerage"`
+	Action              string `json:"action"`
+	RatingFrom          string `json:"rating_from"`
+	RatingTo            string `json:"rating_to"`
+	NormalizeRatingFrom string `json:"normalize_rating_from"`
+	NormalizeRatingTo   string `json:"normalize_rating_to"`
+	// Calificaciones en la escala de 5 niveles (strong_buy … strong_sell).
+	NormalizeRatingFrom5 string    `json:"normalize_rating_from_5"`
+	NormalizeRatingTo5   string    `json:"normalize_rating_to_5"`
+	RatingMappingVersion int       `json:"rating_mapping_version"`
+	TargetFrom           float32   `json:"target_from"`
+	TargetTo             float32   `json:"target_to"`
+	Currency             string    `json:"currency"`
+	ActionType           string    `json:"action_type"`
+	TargetChangePct      *float64  `json:"target_change_pct"`
+	ReportedAt           time.Time `json:"created_at"`
+}
+
+// SaveResult cuenta las filas afectadas por un guardado en lote. Rejected
+// tiene las filas de los lotes que fallaron, para enviarlas a cuarentena.
+type SaveResult struct {
+	Inserted int
+	Updated  int
+	Failed   int
+	Rejected []RejectedStock
 }
@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("cursor inválido")
+
+// StockCursor es una posición en la lista de ratings: el valor de la columna
+// de orden y el id de una fila. Los pedidos con cursor usan paginación por
+// clave (keyset) en lugar de OFFSET.
+type StockCursor struct {
+	OrderBy string `json:"o"`
+	Desc    bool   `json:"d,omitempty"`
+	// Value es el valor de OrderBy en la fila, como texto de la base.
+	Value string `json:"v"`
+	ID    string `json:"id"`
+	// Before pide las filas anteriores a la posición; si no, las siguientes.
+	Before bool `json:"b,omitempty"`
+	// Query es StockQuery.CursorHash de la consulta que devolvió el cursor.
+	Query string `json:"q"`
+}
+
+// StockList es una página de ratings. Total es nil si no se pidió el total.
+// Los cursores están vacíos cuando no hay más filas en esa dirección. Query es
+// la consulta ya normalizada con la que se armó la página.
+type StockList struct {
+	Query      StockQuery
+	Items      []Stock
+	Total      *int
+	NextCursor string
+	PrevCursor string
+}
+
+// Encode devuelve el cursor opaco que se entrega en la API.
+func (c StockCursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func DecodeStockCursor(s string) (StockCursor, error) {
+	var c StockCursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(b, &c); err != nil || c.OrderBy == "" || c.ID == "" || c.Query == "" {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// CursorHash resume el orden y los filtros de una consulta ya normalizada. Un
+// cursor solo vale para la consulta que lo devolvió: con otros filtros la
+// posición no sirve y se saltearían o repetirían filas.
+func (q StockQuery) CursorHash() string {
+	num := func(v *float64) string {
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*v, 'g', -1, 64)
+	}
+	date := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+
+	h := sha256.New()
+	for _, part := range []string{
+		q.OrderBy, strconv.FormatBool(q.OrderDesc),
+		q.ID, q.Ticker, q.Company, q.Brokerage,
+		num(q.TargetFromMin), num(q.TargetFromMax), num(q.TargetToMin), num(q.TargetToMax),
+		date(q.CreatedFrom), date(q.CreatedBefore), q.ActionType,
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
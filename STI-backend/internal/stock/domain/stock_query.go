@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Tamaño de página de GET /api/stocks.
+const (
+	DefaultStockLimit = 20
+	MaxStockLimit     = 100
+)
+
+const DefaultStockOrderBy = "created_at"
+
+// StockOrderColumns son las columnas por las que se puede ordenar la lista.
+var StockOrderColumns = []string{
+	"created_at", "target_to", "target_from", "rating_to", "rating_from",
+	"action", "ticker", "company", "brokerage", "id",
+}
+
+// StockQuery son las opciones de la lista paginada de ratings.
+type StockQuery struct {
+	// Page empieza en 1; 0 usa la primera página.
+	Page int
+	// Limit es el tamaño de página; 0 usa DefaultStockLimit.
+	Limit int
+	// OrderBy es una de StockOrderColumns; vacío usa DefaultStockOrderBy.
+	OrderBy   string
+	OrderDesc bool
+
+	ID        string
+	Ticker    string
+	Company   string
+	Brokerage string
+	// Rangos de precio objetivo; nil no filtra.
+	TargetFromMin *float64
+	TargetFromMax *float64
+	TargetToMin   *float64
+	TargetToMax   *float64
+	// CreatedFrom (inclusivo) y CreatedBefore (exclusivo) acotan created_at;
+	// cero no filtra.
+	CreatedFrom   time.Time
+	CreatedBefore time.Time
+	// ActionType filtra por tipo de acción; vacío no filtra.
+	ActionType string
+
+	// Cursor pide la página siguiente o anterior a una posición en lugar de
+	// Page. Debe corresponder al orden y a los filtros de la consulta.
+	Cursor *StockCursor
+	// IncludeTotal cuenta las filas que cumplen los filtros.
+	IncludeTotal bool
+}
+
+// Normalize completa los valores por defecto y valida los rangos. Si algo no
+// es válido devuelve un *ValidationError con todos los campos rechazados.
+func (q StockQuery) Normalize() (StockQuery, error) {
+	verr := &ValidationError{}
+
+	if q.Page == 0 {
+		q.Page = 1
+	}
+	if q.Page < 1 {
+		verr.add("page", fmt.Sprint(q.Page), "debe ser mayor o igual a 1")
+	}
+	if q.Limit == 0 {
+		q.Limit = DefaultStockLimit
+	}
+	if q.Limit < 1 || q.Limit > MaxStockLimit {
+		verr.add("limit", fmt.Sprint(q.Limit), fmt.Sprintf("debe estar entre 1 y %d", MaxStockLimit))
+	}
+
+	q.OrderBy = strings.ToLower(q.OrderBy)
+	if q.OrderBy == "" {
+		q.OrderBy = DefaultStockOrderBy
+	}
+	if !slices.Contains(StockOrderColumns, q.OrderBy) {
+		verr.add("orderBy", q.OrderBy, "debe ser una de: "+strings.Join(StockOrderColumns, ", "))
+	}
+
+	if q.Cursor != nil {
+		if q.Page > 1 {
+			verr.add("page", fmt.Sprint(q.Page), "no se puede combinar con cursor")
+		}
+		if q.Cursor.OrderBy != q.OrderBy || q.Cursor.Desc != q.OrderDesc {
+			verr.add("cursor", "", "corresponde a otro orden; repite orderBy y orderDir del pedido que lo devolvió")
+		} else if q.Cursor.Query != q.CursorHash() {
+			verr.add("cursor", "", "corresponde a otros filtros; repite los filtros del pedido que lo devolvió")
+		}
+	}
+
+	if q.ActionType != "" && !slices.Contains(ActionTypes, q.ActionType) {
+		verr.add("action_type", q.ActionType, "debe ser uno de: "+strings.Join(ActionTypes, ", "))
+	}
+
+	checkRange(verr, "target_from", q.TargetFromMin, q.TargetFromMax)
+	checkRange(verr, "target_to", q.TargetToMin, q.TargetToMax)
+	if !q.CreatedFrom.IsZero() && !q.CreatedBefore.IsZero() && !q.CreatedFrom.Before(q.CreatedBefore) {
+		verr.add("date_to", "", "no puede ser anterior a date_from")
+	}
+
+	if len(verr.Fields) > 0 {
+		return q, verr
+	}
+	return q, nil
+}
+
+func checkRange(verr *ValidationError, field string, lo, hi *float64) {
+	if lo != nil && hi != nil && *lo > *hi {
+		verr.add(field+"_max", fmt.Sprint(*hi), fmt.Sprintf("debe ser mayor o igual a %s_min", field))
+	}
+}
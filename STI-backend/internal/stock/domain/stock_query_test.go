@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStockQuery_NormalizeDefaults(t *testing.T) {
+	q, err := StockQuery{OrderBy: "Ticker"}.Normalize()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, q.Page)
+	assert.Equal(t, DefaultStockLimit, q.Limit)
+	assert.Equal(t, "ticker", q.OrderBy)
+
+	q, err = StockQuery{}.Normalize()
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultStockOrderBy, q.OrderBy)
+}
+
+func TestStockQuery_NormalizeRejectsInvalidFields(t *testing.T) {
+	lo, hi := 50.0, 10.0
+	day := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	_, err := StockQuery{
+		Page:          -1,
+		Limit:         MaxStockLimit + 1,
+		OrderBy:       "price; DROP TABLE stocks",
+		ActionType:    "sell",
+		TargetToMin:   &lo,
+		TargetToMax:   &hi,
+		CreatedFrom:   day,
+		CreatedBefore: day,
+	}.Normalize()
+
+	var verr *ValidationError
+	assert.True(t, errors.As(err, &verr))
+
+	fields := make([]string, len(verr.Fields))
+	for i, f := range verr.Fields {
+		fields[i] = f.Field
+	}
+	assert.Equal(t, []string{"page", "limit", "orderBy", "action_type", "target_to_max", "date_to"}, fields)
+}
+
+func TestStockCursor_RoundTrip(t *testing.T) {
+	c := StockCursor{OrderBy: "created_at", Desc: true, Value: "2025-03-10 15:04:05.123456+00:00", ID: "b1d1c3a4-0000-4000-8000-000000000001", Before: true, Query: "0123456789abcdef"}
+
+	decoded, err := DecodeStockCursor(c.Encode())
+	assert.NoError(t, err)
+	assert.Equal(t, c, decoded)
+
+	for _, raw := range []string{"no-es-base64!", "e30", c.Encode() + "x"} {
+		_, err := DecodeStockCursor(raw)
+		assert.ErrorIs(t, err, ErrInvalidCursor, raw)
+	}
+}
+
+func TestStockQuery_NormalizeCursor(t *testing.T) {
+	cursor := &StockCursor{OrderBy: "ticker", ID: "id", Query: StockQuery{OrderBy: "ticker"}.CursorHash()}
+
+	q, err := StockQuery{OrderBy: "TICKER", Cursor: cursor}.Normalize()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, q.Page)
+
+	_, err = StockQuery{OrderBy: "ticker", OrderDesc: true, Page: 2, Cursor: cursor}.Normalize()
+	var verr *ValidationError
+	assert.True(t, errors.As(err, &verr))
+	assert.Equal(t, "page", verr.Fields[0].Field)
+	assert.Equal(t, "cursor", verr.Fields[1].Field)
+}
+
+func TestStockQuery_NormalizeCursorFilters(t *testing.T) {
+	low := 10.0
+	from := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	base := StockQuery{Ticker: "AAPL", TargetToMin: &low, CreatedFrom: from}
+	base, err := base.Normalize()
+	assert.NoError(t, err)
+	cursor := &StockCursor{OrderBy: base.OrderBy, ID: "id", Query: base.CursorHash()}
+
+	same := low
+	_, err = StockQuery{Ticker: "AAPL", TargetToMin: &same, CreatedFrom: from.In(time.FixedZone("ART", -3*3600)), Cursor: cursor}.Normalize()
+	assert.NoError(t, err)
+
+	other := 11.0
+	for _, q := range []StockQuery{
+		{Ticker: "MSFT", TargetToMin: &low, CreatedFrom: from},
+		{Ticker: "AAPL", TargetToMin: &other, CreatedFrom: from},
+		{Ticker: "AAPL", TargetToMax: &low, CreatedFrom: from},
+		{Ticker: "AAPL", TargetToMin: &low},
+	} {
+		q.Cursor = cursor
+		_, err := q.Normalize()
+		var verr *ValidationError
+		assert.True(t, errors.As(err, &verr))
+		assert.Equal(t, "cursor", verr.Fields[0].Field)
+	}
+}
@@ -10,5 +10,10 @@ type StockRecommendation struct {
 	TargetTo            float32 `json:"target_to"`
 	NormalizeRatingFrom string  `json:"normalize_rating_from"`
 	NormalizeRatingTo   string  `json:"normalize_rating_to"`
+	ActionType          string  `json:"action_type"`
 	WeightScore         float64 `json:"weight_score"`
+	ScoreMethod         string  `json:"score_method"`
+	ScoreCILow          float64 `json:"score_ci_low"`
+	ScoreCIHigh         float64 `json:"score_ci_high"`
+	BrokerPredictions   int     `json:"broker_predictions"`
 }
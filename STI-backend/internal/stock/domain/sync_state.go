@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// SyncState guarda el avance de la sincronización de una fuente externa.
+// LastReportedAt es la marca de agua de la última corrida completa;
+// NextPage y RunReportedAt solo tienen valor mientras hay una corrida a medias.
+type SyncState struct {
+	Source         string
+	LastReportedAt time.Time
+	RunReportedAt  time.Time
+	NextPage       string
+	UpdatedAt      time.Time
+}
+
+// SyncStats resume lo procesado en una corrida de sincronización.
+// RowsQuarantined cuenta los ratings que no pasaron la validación o cuyo lote
+// no se pudo guardar; RowsFailed, los que tampoco se pudieron enviar a
+// cuarentena. Cada rating cuenta en uno solo.
+type SyncStats struct {
+	PagesFetched    int
+	RowsInserted    int
+	RowsUpdated     int
+	RowsFailed      int
+	RowsQuarantined int
+}
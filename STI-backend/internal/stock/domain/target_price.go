@@ -0,0 +1,191 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DefaultCurrency se asume para los targets sin símbolo ni código de moneda.
+const DefaultCurrency = "USD"
+
+// maxTargetPrice es el límite de las columnas DECIMAL(10,2) de stocks.
+const maxTargetPrice = 1e8
+
+var ErrInvalidPrice = errors.New("precio inválido")
+
+// Símbolos de moneda, los más largos primero para que "US$" gane sobre "$".
+var currencySymbols = []struct {
+	symbol   string
+	currency string
+}{
+	{"US$", "USD"},
+	{"CA$", "CAD"},
+	{"HK$", "HKD"},
+	{"C$", "CAD"},
+	{"A$", "AUD"},
+	{"R$", "BRL"},
+	{"$", "USD"},
+	{"€", "EUR"},
+	{"£", "GBP"},
+	{"¥", "JPY"},
+	{"₹", "INR"},
+}
+
+// isoCurrencies son los códigos ISO 4217 de monedas en circulación.
+var isoCurrencies = codeSet(`
+	AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BHD BIF BMD BND
+	BOB BRL BSD BTN BWP BYN BZD CAD CDF CHF CLP CNY COP CRC CUP CVE CZK DJF
+	DKK DOP DZD EGP ERN ETB EUR FJD FKP GBP GEL GHS GIP GMD GNF GTQ GYD HKD
+	HNL HTG HUF IDR ILS INR IQD IRR ISK JMD JOD JPY KES KGS KHR KMF KPW KRW
+	KWD KYD KZT LAK LBP LKR LRD LSL LYD MAD MDL MGA MKD MMK MNT MOP MRU MUR
+	MVR MWK MXN MYR MZN NAD NGN NIO NOK NPR NZD OMR PAB PEN PGK PHP PKR PLN
+	PYG QAR RON RSD RUB RWF SAR SBD SCR SDG SEK SGD SHP SLE SOS SRD SSP STN
+	SVC SYP SZL THB TJS TMT TND TOP TRY TTD TWD TZS UAH UGX USD UYU UZS VES
+	VND VUV WST XAF XCD XCG XOF XPF YER ZAR ZMW ZWG ZWL`)
+
+// commaDecimalCurrencies escriben los importes con coma decimal y punto de
+// miles ("1.250,00"). Resuelven los casos ambiguos como "1.250" o "1,250".
+var commaDecimalCurrencies = codeSet(`
+	ARS BGN BRL CLP COP CZK DKK EUR HUF IDR NOK PLN PYG RON RUB SEK TRY UAH
+	UYU VND`)
+
+func codeSet(codes string) map[string]bool {
+	set := make(map[string]bool)
+	for _, c := range strings.Fields(codes) {
+		set[c] = true
+	}
+	return set
+}
+
+// TargetPrice es un precio objetivo. Amount 0 indica que no hay target.
+type TargetPrice struct {
+	Amount   float32
+	Currency string
+}
+
+// ParseTargetPrice interpreta precios como "$1,250.00", "€12", "12.50 EUR" o
+// "1.250,00 €". El separador que aparece último es el decimal. Un único
+// separador seguido de tres dígitos ("1.250", "1,250") es ambiguo y se
+// resuelve con la convención de la moneda: de miles si la moneda usa el otro
+// como decimal (punto para USD y los targets sin moneda). Un texto vacío es
+// un target ausente.
+func ParseTargetPrice(raw string) (TargetPrice, error) {
+	s := strings.TrimSpace(raw)
+	if s == "" {
+		return TargetPrice{}, nil
+	}
+
+	s, currency := splitCurrency(s)
+	if s == "" {
+		return TargetPrice{}, fmt.Errorf("%w: falta el importe", ErrInvalidPrice)
+	}
+
+	decimal := byte('.')
+	if commaDecimalCurrencies[currency] {
+		decimal = ','
+	}
+	number, err := normalizeDecimal(s, decimal)
+	if err != nil {
+		return TargetPrice{}, err
+	}
+
+	amount, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return TargetPrice{}, fmt.Errorf("%w: %q", ErrInvalidPrice, raw)
+	}
+	if amount >= maxTargetPrice {
+		return TargetPrice{}, fmt.Errorf("%w: %q fuera de rango", ErrInvalidPrice, raw)
+	}
+
+	return TargetPrice{Amount: float32(amount), Currency: currency}, nil
+}
+
+// splitCurrency separa un símbolo o código ISO 4217 al inicio o al final. Tres
+// letras que no son un código ISO ("TBD") no se toman como moneda.
+func splitCurrency(s string) (string, string) {
+	for _, c := range currencySymbols {
+		if rest, ok := strings.CutPrefix(s, c.symbol); ok {
+			return strings.TrimSpace(rest), c.currency
+		}
+		if rest, ok := strings.CutSuffix(s, c.symbol); ok {
+			return strings.TrimSpace(rest), c.currency
+		}
+	}
+
+	if len(s) > 3 && isCurrencyCode(s[:3]) {
+		return strings.TrimSpace(s[3:]), strings.ToUpper(s[:3])
+	}
+	if len(s) > 3 && isCurrencyCode(s[len(s)-3:]) {
+		return strings.TrimSpace(s[:len(s)-3]), strings.ToUpper(s[len(s)-3:])
+	}
+	return s, ""
+}
+
+func isCurrencyCode(s string) bool {
+	return isoCurrencies[strings.ToUpper(s)]
+}
+
+// normalizeDecimal quita los separadores de miles y deja el punto como
+// separador decimal. decimal es el separador decimal de la moneda, usado solo
+// para los casos ambiguos.
+func normalizeDecimal(s string, decimal byte) (string, error) {
+	for _, r := range s {
+		if !unicode.IsDigit(r) && r != ',' && r != '.' {
+			return "", fmt.Errorf("%w: carácter %q no permitido", ErrInvalidPrice, r)
+		}
+	}
+
+	lastComma := strings.LastIndex(s, ",")
+	lastDot := strings.LastIndex(s, ".")
+
+	var intPart, fracPart, thousands string
+	switch {
+	case lastComma >= 0 && lastDot >= 0:
+		dec := max(lastComma, lastDot)
+		intPart, fracPart = s[:dec], s[dec+1:]
+		thousands = ","
+		if lastComma > lastDot {
+			thousands = "."
+		}
+	case lastComma >= 0:
+		thousands = ","
+		if strings.Count(s, ",") == 1 && (len(s)-lastComma-1 != 3 || decimal == ',') {
+			intPart, fracPart, thousands = s[:lastComma], s[lastComma+1:], ""
+		} else {
+			intPart = s
+		}
+	case lastDot >= 0:
+		thousands = "."
+		if strings.Count(s, ".") == 1 && (len(s)-lastDot-1 != 3 || decimal == '.') {
+			intPart, fracPart, thousands = s[:lastDot], s[lastDot+1:], ""
+		} else {
+			intPart = s
+		}
+	default:
+		intPart = s
+	}
+
+	if strings.ContainsAny(fracPart, ",.") {
+		return "", fmt.Errorf("%w: separadores mezclados en %q", ErrInvalidPrice, s)
+	}
+	if thousands != "" {
+		groups := strings.Split(intPart, thousands)
+		for i, g := range groups {
+			if (i == 0 && (len(g) == 0 || len(g) > 3)) || (i > 0 && len(g) != 3) {
+				return "", fmt.Errorf("%w: separador de miles mal ubicado en %q", ErrInvalidPrice, s)
+			}
+		}
+		intPart = strings.Join(groups, "")
+	}
+	if strings.ContainsAny(intPart, ",.") || intPart == "" {
+		return "", fmt.Errorf("%w: %q", ErrInvalidPrice, s)
+	}
+
+	if fracPart == "" {
+		return intPart, nil
+	}
+	return intPart + "." + fracPart, nil
+}
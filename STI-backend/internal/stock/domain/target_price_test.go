@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTargetPrice(t *testing.T) {
+	cases := []struct {
+		raw      string
+		amount   float32
+		currency string
+	}{
+		{"", 0, ""},
+		{"$12", 12, "USD"},
+		{"$1,250.00", 1250, "USD"},
+		{"€12", 12, "EUR"},
+		{"1.250,50 €", 1250.5, "EUR"},
+		{"12.50 EUR", 12.5, "EUR"},
+		{"gbp 7", 7, "GBP"},
+		{"US$ 3,000", 3000, "USD"},
+		{"C$45.5", 45.5, "CAD"},
+		{"12,5", 12.5, ""},
+		{"1.000.000", 1000000, ""},
+		{"  $ 99.99  ", 99.99, "USD"},
+		// Un separador con tres dígitos depende de la moneda.
+		{"$1.250", 1.25, "USD"},
+		{"1.250", 1.25, ""},
+		{"1.250 €", 1250, "EUR"},
+		{"R$ 1.250", 1250, "BRL"},
+		{"1,250 EUR", 1.25, "EUR"},
+		{"$1,250", 1250, "USD"},
+		{"chf 1.250", 1.25, "CHF"},
+	}
+	for _, c := range cases {
+		got, err := ParseTargetPrice(c.raw)
+		if assert.NoError(t, err, c.raw) {
+			assert.InDelta(t, c.amount, got.Amount, 0.001, c.raw)
+			assert.Equal(t, c.currency, got.Currency, c.raw)
+		}
+	}
+}
+
+func TestParseTargetPrice_Invalid(t *testing.T) {
+	for _, raw := range []string{
+		"$", "N/A", "-12", "12$34", "1,25,0", "1.2.3,4,5", "$1,250.00.00", "$100000000",
+		// Tres letras que no son un código ISO 4217.
+		"TBD 12", "12 TBD", "ABC1",
+	} {
+		_, err := ParseTargetPrice(raw)
+		assert.ErrorIs(t, err, ErrInvalidPrice, raw)
+	}
+}
+
+func TestRawStockParse(t *testing.T) {
+	raw := RawStock{
+		Ticker:     " AAPL ",
+		Brokerage:  "Goldman Sachs",
+		Action:     "target raised by",
+		TargetFrom: "",
+		TargetTo:   "$1,250.00",
+		Time:       "2025-03-10T00:30:05.813548892Z",
+	}
+
+	s, err := raw.Parse()
+	assert.NoError(t, err)
+	assert.Equal(t, "AAPL", s.Ticker)
+	assert.Equal(t, float32(0), s.TargetFrom)
+	assert.Equal(t, float32(1250), s.TargetTo)
+	assert.Equal(t, "USD", s.Currency)
+	assert.Equal(t, 2025, s.ReportedAt.Year())
+}
+
+func TestRawStockParse_ValidationErrors(t *testing.T) {
+	raw := RawStock{
+		Ticker:     "",
+		TargetFrom: "€10",
+		TargetTo:   "$12",
+		Time:       "ayer",
+	}
+
+	_, err := raw.Parse()
+	var verr *ValidationError
+	if assert.ErrorAs(t, err, &verr) {
+		fields := make([]string, len(verr.Fields))
+		for i, f := range verr.Fields {
+			fields[i] = f.Field
+		}
+		assert.Equal(t, []string{"ticker", "time", "target_to"}, fields)
+		assert.Equal(t, "ayer", verr.Fields[1].Value)
+	}
+}
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+var ErrTickerNotFound = errors.New("ticker not found")
+
+type LatestClose struct {
+	Date  time.Time `json:"date"`
+	Close float32   `json:"close"`
+}
+
+// Consensus resume el último rating vigente de cada brokerage sobre un ticker.
+type Consensus struct {
+	Buy     int    `json:"buy"`
+	Hold    int    `json:"hold"`
+	Sell    int    `json:"sell"`
+	Brokers int    `json:"brokers"`
+	Rating  string `json:"rating"`
+}
+
+type TickerDetail struct {
+	Ticker      string       `json:"ticker"`
+	Company     string       `json:"company"`
+	Ratings     []Stock      `json:"ratings"`
+	LatestClose *LatestClose `json:"latest_close"`
+	Consensus   Consensus    `json:"consensus"`
+}
+
+// BuildConsensus toma el rating más reciente de cada brokerage y devuelve la
+// mayoría entre buy, hold y sell. Los empates se resuelven como hold.
+func BuildConsensus(ratings []Stock) Consensus {
+	latest := LatestByBrokerage(ratings)
+
+	var c Consensus
+	for _, r := range latest {
+		switch r.NormalizeRatingTo {
+		case "buy":
+			c.Buy++
+		case "sell":
+			c.Sell++
+		default:
+			c.Hold++
+		}
+	}
+	c.Brokers = len(latest)
+
+	switch {
+	case c.Brokers == 0:
+		c.Rating = ""
+	case c.Buy > c.Hold && c.Buy > c.Sell:
+		c.Rating = "buy"
+	case c.Sell > c.Hold && c.Sell > c.Buy:
+		c.Rating = "sell"
+	default:
+		c.Rating = "hold"
+	}
+	return c
+}
+
+// LatestByBrokerage deja el rating más reciente de cada brokerage, ordenados
+// por brokerage.
+func LatestByBrokerage(ratings []Stock) []Stock {
+	latest := make(map[string]Stock)
+	for _, r := range ratings {
+		if prev, ok := latest[r.Brokerage]; !ok || r.ReportedAt.After(prev.ReportedAt) {
+			latest[r.Brokerage] = r
+		}
+	}
+
+	result := make([]Stock, 0, len(latest))
+	for _, r := range latest {
+		result = append(result, r)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Brokerage < result[j].Brokerage
+	})
+	return result
+}
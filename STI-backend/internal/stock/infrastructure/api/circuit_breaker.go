@@ -0,0 +1,73 @@
+package api
+
+import (
+	"sync"
+	"time"
+)
+
+// CircuitBreaker deja de enviar pedidos tras threshold fallas consecutivas.
+// Pasado cooldown deja pasar un pedido de prueba: si funciona se cierra, y si
+// falla vuelve a abrirse por otro cooldown.
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+	probing   bool
+	now       func() time.Time
+}
+
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold: max(threshold, 1),
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// Allow indica si se puede enviar un pedido; si no, devuelve hasta cuándo
+// está abierto el circuito.
+func (b *CircuitBreaker) Allow() (bool, time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return true, time.Time{}
+	}
+	if b.now().Before(b.openUntil) || b.probing {
+		return false, b.openUntil
+	}
+	b.probing = true
+	return true, time.Time{}
+}
+
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probing = false
+}
+
+// Release libera el pedido de prueba sin contarlo como éxito ni como falla.
+// Lo usan los intentos que terminan sin una respuesta que evaluar (pedido
+// inválido, ctx cancelado, sin grabación), para que el circuito no quede
+// medio abierto para siempre.
+func (b *CircuitBreaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+func (b *CircuitBreaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.failures >= b.threshold {
+		b.openUntil = b.now().Add(b.cooldown)
+	}
+}
@@ -1,92 +1,75 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
-	"strings"
-	"time"
 
+	"github.com/viteant/stockinsight/internal/cassette"
 	"github.com/viteant/stockinsight/internal/stock/domain"
 )
 
 type ExternalAPIResponse struct {
-	Items    []ExternalAPIItem `json:"items"`
+	Items    []domain.RawStock `json:"items"`
 	NextPage string            `json:"next_page"`
 }
 
-type ExternalAPIItem struct {
-	Ticker     string `json:"ticker"`
-	Company    string `json:"company"`
-	Brokerage  string `json:"brokerage"`
-	Action     string `json:"action"`
-	RatingFrom string `json:"rating_from"`
-	RatingTo   string `json:"rating_to"`
-	TargetFrom string `json:"target_from"`
-	TargetTo   string `json:"target_to"`
-	Time       string `json:"time"`
-}
-
 type ExternalAPIClient struct {
 	Endpoint string
 	Token    string
+	Client   *ResilientClient
 }
 
-func NewExternalAPIClient() *ExternalAPIClient {
+// NewExternalAPIClient graba o reproduce los pedidos en el cassette
+// "ratings_api" si tape tiene directorio.
+func NewExternalAPIClient(tape cassette.Config) *ExternalAPIClient {
+	client := NewResilientClient(ClientConfigFromEnv())
+	client.HTTP.Transport = tape.Wrap("ratings_api", http.DefaultTransport)
+
 	return &ExternalAPIClient{
 		Endpoint: os.Getenv("API_ENDPOINT"),
 		Token:    os.Getenv("API_TOKEN"),
+		Client:   client,
 	}
 }
 
-func (c *ExternalAPIClient) FetchPage(nextPage string) ([]domain.Stock, string, error) {
-	url := c.Endpoint
+// FetchPage descarga una página del feed. Los ratings que no pasan la
+// validación se devuelven en Rejected en lugar de guardarse con ceros.
+func (c *ExternalAPIClient) FetchPage(ctx context.Context, nextPage string) (domain.StockPage, error) {
+	var page domain.StockPage
+
+	endpoint := c.Endpoint
 	if nextPage != "" {
-		url += fmt.Sprintf("?next_page=%s", nextPage)
+		endpoint += "?next_page=" + url.QueryEscape(nextPage)
 	}
 
-	req, _ := http.NewRequest("GET", url, nil)
-	req.Header.Set("Authorization", "Bearer "+c.Token)
-
-	resp, err := http.DefaultClient.Do(req)
+	body, err := c.Client.Get(ctx, endpoint, http.Header{
+		"Authorization": {"Bearer " + c.Token},
+		"Accept":        {"application/json"},
+	})
 	if err != nil {
-		return nil, "", err
+		return page, err
 	}
-	defer resp.Body.Close()
-
-	body, _ := io.ReadAll(resp.Body)
 
 	var apiResp ExternalAPIResponse
 	if err := json.Unmarshal(body, &apiResp); err != nil {
 		log.Println("Error al parsear respuesta:", err)
-		return nil, "", err
+		return page, fmt.Errorf("%w: la respuesta no es JSON válido: %v", domain.ErrUpstream, err)
 	}
 
-	var result []domain.Stock
 	for _, item := range apiResp.Items {
-		reportedAt, _ := time.Parse(time.RFC3339Nano, item.Time)
-
-		var tf, tt float32
-		fmt.Sscanf(strings.Replace(item.TargetFrom, "$", "", 1), "%f", &tf)
-		fmt.Sscanf(strings.Replace(item.TargetTo, "$", "", 1), "%f", &tt)
-
-		result = append(result, domain.Stock{
-			Ticker:              item.Ticker,
-			Company:             item.Company,
-			Brokerage:           item.Brokerage,
-			Action:              item.Action,
-			RatingFrom:          item.RatingFrom,
-			RatingTo:            item.RatingTo,
-			NormalizeRatingFrom: domain.NormalizeBrokerRating(item.RatingFrom),
-			NormalizeRatingTo:   domain.NormalizeBrokerRating(item.RatingTo),
-			TargetFrom:          tf,
-			TargetTo:            tt,
-			ReportedAt:          reportedAt,
-		})
+		stock, err := item.Parse()
+		if err != nil {
+			page.Rejected = append(page.Rejected, domain.RejectedStock{Raw: item, Err: err})
+			continue
+		}
+		page.Stocks = append(page.Stocks, stock)
 	}
 
-	return result, apiResp.NextPage, nil
+	page.NextPage = apiResp.NextPage
+	return page, nil
 }
@@ -0,0 +1,221 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/viteant/stockinsight/internal/cassette"
+	"github.com/viteant/stockinsight/internal/stock/domain"
+)
+
+const maxErrorBody = 512
+
+// ClientConfig define los tiempos de espera y reintentos del cliente HTTP.
+type ClientConfig struct {
+	Timeout          time.Duration
+	MaxRetries       int
+	BaseBackoff      time.Duration
+	MaxBackoff       time.Duration
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+// ClientConfigFromEnv lee API_TIMEOUT_MS (15000), API_MAX_RETRIES (4),
+// API_BACKOFF_MS (500), API_MAX_BACKOFF_MS (30000), API_BREAKER_THRESHOLD (5)
+// y API_BREAKER_COOLDOWN_MS (60000).
+func ClientConfigFromEnv() ClientConfig {
+	return ClientConfig{
+		Timeout:          envMillis("API_TIMEOUT_MS", 15*time.Second),
+		MaxRetries:       envInt("API_MAX_RETRIES", 4),
+		BaseBackoff:      envMillis("API_BACKOFF_MS", 500*time.Millisecond),
+		MaxBackoff:       envMillis("API_MAX_BACKOFF_MS", 30*time.Second),
+		BreakerThreshold: envInt("API_BREAKER_THRESHOLD", 5),
+		BreakerCooldown:  envMillis("API_BREAKER_COOLDOWN_MS", time.Minute),
+	}
+}
+
+// ResilientClient hace GET con timeout, reintentos con backoff exponencial y
+// jitter ante 429, 5xx y errores de red, respeta Retry-After y corta los
+// pedidos con un circuit breaker. Los errores se devuelven como
+// domain.ErrUnauthorized, domain.ErrRateLimited o domain.ErrUpstream.
+type ResilientClient struct {
+	HTTP    *http.Client
+	Config  ClientConfig
+	Breaker *CircuitBreaker
+
+	sleep  func(ctx context.Context, d time.Duration) error
+	jitter func() float64
+}
+
+func NewResilientClient(cfg ClientConfig) *ResilientClient {
+	return &ResilientClient{
+		HTTP:    &http.Client{Timeout: cfg.Timeout},
+		Config:  cfg,
+		Breaker: NewCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
+		sleep:   sleepContext,
+		jitter:  rand.Float64,
+	}
+}
+
+// Get devuelve el cuerpo de una respuesta 2xx.
+func (c *ResilientClient) Get(ctx context.Context, url string, header http.Header) ([]byte, error) {
+	var lastErr error
+
+	for attempt := 0; attempt <= c.Config.MaxRetries; attempt++ {
+		if ok, until := c.Breaker.Allow(); !ok {
+			return nil, fmt.Errorf("%w: circuito abierto hasta %s", domain.ErrUpstream, until.Format(time.RFC3339))
+		}
+
+		body, wait, err := c.do(ctx, url, header)
+		if err == nil {
+			return body, nil
+		}
+		lastErr = err
+
+		if wait < 0 || attempt == c.Config.MaxRetries {
+			break
+		}
+		if wait == 0 {
+			wait = c.backoff(attempt)
+		}
+		wait = min(wait, c.Config.MaxBackoff)
+		log.Printf("API de ratings: %v, reintento %d/%d en %v", err, attempt+1, c.Config.MaxRetries, wait)
+		if err := c.sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, lastErr
+}
+
+// do hace un intento. wait es la espera antes del siguiente (0 para usar el
+// backoff, negativa si no se debe reintentar).
+func (c *ResilientClient) do(ctx context.Context, url string, header http.Header) ([]byte, time.Duration, error) {
+	// Success y Failure ya liberan el pedido de prueba; las salidas que no
+	// cuentan para el circuito lo liberan acá.
+	defer c.Breaker.Release()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, -1, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, -1, ctx.Err()
+		}
+		// Sin grabación no tiene sentido reintentar ni abrir el circuito.
+		if errors.Is(err, cassette.ErrNotRecorded) {
+			return nil, -1, fmt.Errorf("%w: %v", domain.ErrUpstream, err)
+		}
+		c.Breaker.Failure()
+		return nil, 0, fmt.Errorf("%w: %v", domain.ErrUpstream, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.Breaker.Failure()
+		return nil, 0, fmt.Errorf("%w: error leyendo respuesta: %v", domain.ErrUpstream, err)
+	}
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		c.Breaker.Success()
+		return body, 0, nil
+
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		c.Breaker.Success()
+		return nil, -1, fmt.Errorf("%w: HTTP %d", domain.ErrUnauthorized, resp.StatusCode)
+
+	case resp.StatusCode == http.StatusTooManyRequests:
+		// Un 429 indica que la API responde; no cuenta para el circuito.
+		c.Breaker.Success()
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		rlErr := &domain.RateLimitError{RetryAfter: retryAfter}
+		if retryAfter > c.Config.MaxBackoff {
+			return nil, -1, rlErr
+		}
+		return nil, retryAfter, rlErr
+
+	case resp.StatusCode >= 500:
+		c.Breaker.Failure()
+		return nil, parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+			fmt.Errorf("%w: HTTP %d: %s", domain.ErrUpstream, resp.StatusCode, truncate(body))
+	}
+
+	c.Breaker.Success()
+	return nil, -1, fmt.Errorf("%w: HTTP %d: %s", domain.ErrUpstream, resp.StatusCode, truncate(body))
+}
+
+// backoff es base·2^attempt, con tope MaxBackoff, y jitter: se espera entre la
+// mitad y el total.
+func (c *ResilientClient) backoff(attempt int) time.Duration {
+	d := c.Config.BaseBackoff << attempt
+	if d > c.Config.MaxBackoff || d <= 0 {
+		d = c.Config.MaxBackoff
+	}
+	return d/2 + time.Duration(c.jitter()*float64(d/2))
+}
+
+// parseRetryAfter acepta segundos o una fecha HTTP; devuelve 0 si no hay.
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+func truncate(body []byte) string {
+	if len(body) > maxErrorBody {
+		return string(body[:maxErrorBody]) + "..."
+	}
+	return string(body)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func envInt(key string, fallback int) int {
+	if v := os.Getenv(key); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			return n
+		}
+	}
+	return fallback
+}
+
+func envMillis(key string, fallback time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if ms, err := strconv.Atoi(v); err == nil && ms > 0 {
+			return time.Duration(ms) * time.Millisecond
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viteant/stockinsight/internal/stock/domain"
+)
+
+func newTestClient(maxRetries int) (*ResilientClient, *[]time.Duration) {
+	var waits []time.Duration
+	c := NewResilientClient(ClientConfig{
+		Timeout:          time.Second,
+		MaxRetries:       maxRetries,
+		BaseBackoff:      100 * time.Millisecond,
+		MaxBackoff:       time.Second,
+		BreakerThreshold: 3,
+		BreakerCooldown:  time.Minute,
+	})
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	c.jitter = func() float64 { return 1 }
+	return c, &waits
+}
+
+func TestResilientClient_RetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"items":[]}`))
+	}))
+	defer srv.Close()
+
+	c, waits := newTestClient(4)
+	body, err := c.Get(context.Background(), srv.URL, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `{"items":[]}`, string(body))
+	assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}, *waits)
+}
+
+func TestResilientClient_RetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	c, waits := newTestClient(2)
+	_, err := c.Get(context.Background(), srv.URL, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Duration{time.Second}, *waits)
+}
+
+func TestResilientClient_RateLimitBeyondMaxBackoff(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	c, waits := newTestClient(2)
+	_, err := c.Get(context.Background(), srv.URL, nil)
+
+	var rlErr *domain.RateLimitError
+	assert.ErrorIs(t, err, domain.ErrRateLimited)
+	assert.ErrorAs(t, err, &rlErr)
+	assert.Equal(t, 2*time.Minute, rlErr.RetryAfter)
+	assert.Empty(t, *waits)
+}
+
+func TestResilientClient_Unauthorized(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	c, _ := newTestClient(3)
+	_, err := c.Get(context.Background(), srv.URL, nil)
+
+	assert.ErrorIs(t, err, domain.ErrUnauthorized)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestResilientClient_CircuitBreaker(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("<html>error</html>"))
+	}))
+	defer srv.Close()
+
+	c, _ := newTestClient(5)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.Breaker.now = func() time.Time { return now }
+
+	_, err := c.Get(context.Background(), srv.URL, nil)
+	assert.ErrorIs(t, err, domain.ErrUpstream)
+	assert.Contains(t, err.Error(), "circuito abierto")
+	assert.Equal(t, int32(3), calls.Load())
+
+	// Pasado el cooldown se deja pasar un pedido de prueba.
+	now = now.Add(2 * time.Minute)
+	_, err = c.Get(context.Background(), srv.URL, nil)
+	assert.ErrorIs(t, err, domain.ErrUpstream)
+	assert.Equal(t, int32(4), calls.Load())
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, 30*time.Second, parseRetryAfter("30", now))
+	assert.Equal(t, 90*time.Second, parseRetryAfter("Wed, 01 Jan 2025 12:01:30 GMT", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("mañana", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("", now))
+}
+
+func TestResilientClient_ReleasesProbeOnCanceledRequest(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	c, _ := newTestClient(5)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.Breaker.now = func() time.Time { return now }
+
+	_, err := c.Get(context.Background(), srv.URL, nil)
+	assert.Contains(t, err.Error(), "circuito abierto")
+
+	// El pedido de prueba se cancela antes de tener respuesta.
+	now = now.Add(2 * time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = c.Get(ctx, srv.URL, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// El siguiente pedido puede volver a probar y llega al servidor.
+	_, err = c.Get(context.Background(), srv.URL, nil)
+	assert.ErrorIs(t, err, domain.ErrUpstream)
+	assert.Equal(t, int32(4), calls.Load())
+}
@@ -0,0 +1,67 @@
+package ratingmap
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/viteant/stockinsight/internal/stock/domain"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed rating_mapping.yaml
+var defaultMapping []byte
+
+type mappingFile struct {
+	Version int                 `json:"version" yaml:"version"`
+	Levels  map[string][]string `json:"levels" yaml:"levels"`
+}
+
+// LoadFromEnv lee el archivo de RATING_MAPPING_FILE; sin la variable usa el
+// mapeo incluido en el binario.
+func LoadFromEnv() (*domain.RatingMapping, error) {
+	if path := os.Getenv("RATING_MAPPING_FILE"); path != "" {
+		return Load(path)
+	}
+	return Parse(defaultMapping, "yaml")
+}
+
+// Load lee un mapeo en YAML o JSON según la extensión del archivo.
+func Load(path string) (*domain.RatingMapping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	format := "yaml"
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		format = "json"
+	}
+
+	mapping, err := Parse(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return mapping, nil
+}
+
+func Parse(data []byte, format string) (*domain.RatingMapping, error) {
+	var file mappingFile
+	var err error
+	if format == "json" {
+		err = json.Unmarshal(data, &file)
+	} else {
+		err = yaml.Unmarshal(data, &file)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if file.Version <= 0 {
+		return nil, fmt.Errorf("el mapeo de ratings debe tener version > 0")
+	}
+	return domain.NewRatingMapping(file.Version, file.Levels)
+}
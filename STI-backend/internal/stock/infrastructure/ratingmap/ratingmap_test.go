@@ -0,0 +1,53 @@
+package ratingmap
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultMapping(t *testing.T) {
+	m, err := Parse(defaultMapping, "yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, m.Version)
+
+	r, ok := m.Normalize("Strong-Buy")
+	assert.True(t, ok)
+	assert.Equal(t, "buy", r.Level3)
+	assert.Equal(t, "strong_buy", r.Level5)
+
+	r, ok = m.Normalize("  Market   Perform ")
+	assert.True(t, ok)
+	assert.Equal(t, "hold", r.Level5)
+
+	_, ok = m.Normalize("Top Pick")
+	assert.False(t, ok)
+}
+
+func TestLoad_JSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mapping.json")
+	err := os.WriteFile(path, []byte(`{"version": 2, "levels": {"strong_sell": ["Strong Sell"], "buy": ["buy"]}}`), 0o644)
+	assert.NoError(t, err)
+
+	m, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, m.Version)
+
+	r, ok := m.Normalize("strong sell")
+	assert.True(t, ok)
+	assert.Equal(t, "sell", r.Level3)
+	assert.Equal(t, "strong_sell", r.Level5)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	_, err := Parse([]byte("version: 1\nlevels:\n  very_buy: [buy]\n"), "yaml")
+	assert.Error(t, err)
+
+	_, err = Parse([]byte("version: 1\nlevels:\n  buy: [buy]\n  sell: [buy]\n"), "yaml")
+	assert.Error(t, err)
+
+	_, err = Parse([]byte("levels:\n  buy: [buy]\n"), "yaml")
+	assert.Error(t, err)
+}
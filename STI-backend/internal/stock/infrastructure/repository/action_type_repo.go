@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/viteant/stockinsight/internal/db"
+	"github.com/viteant/stockinsight/internal/stock/domain"
+)
+
+// BackfillActionTypes completa action_type y target_change_pct en lotes de
+// BatchSize filas, recorriendo la tabla por id. Con all=true reclasifica
+// todas las filas; si no, solo las que aún no tienen action_type.
+func (r *PersistenceStockRepository) BackfillActionTypes(all bool) (int, error) {
+	batchSize := r.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	updated := 0
+	lastID := "00000000-0000-0000-0000-000000000000"
+	for {
+		stocks, err := r.fetchUnclassified(lastID, all, batchSize)
+		if err != nil {
+			return updated, err
+		}
+		if len(stocks) == 0 {
+			break
+		}
+
+		for i := range stocks {
+			stocks[i].Classify()
+		}
+		if err := db.RunInTx(r.DB, r.MaxRetries, func(tx *sql.Tx) error {
+			return updateActionTypes(tx, stocks)
+		}); err != nil {
+			return updated, err
+		}
+
+		updated += len(stocks)
+		lastID = stocks[len(stocks)-1].ID
+		log.Printf("Clasificados %d ratings", updated)
+	}
+
+	return updated, nil
+}
+
+func (r *PersistenceStockRepository) fetchUnclassified(afterID string, all bool, limit int) ([]domain.Stock, error) {
+	rows, err := r.DB.Query(`
+		SELECT id, action,
+			COALESCE(normalize_rating_from, ''), COALESCE(normalize_rating_to, ''),
+			COALESCE(target_from, 0), COALESCE(target_to, 0)
+		FROM stocks
+		WHERE id > $1 AND ($2 OR action_type IS NULL)
+		ORDER BY id
+		LIMIT $3
+	`, afterID, all, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stocks []domain.Stock
+	for rows.Next() {
+		var s domain.Stock
+		if err := rows.Scan(
+			&s.ID,
+			&s.Action,
+			&s.NormalizeRatingFrom,
+			&s.NormalizeRatingTo,
+			&s.TargetFrom,
+			&s.TargetTo,
+		); err != nil {
+			return nil, err
+		}
+		stocks = append(stocks, s)
+	}
+	return stocks, rows.Err()
+}
+
+func updateActionTypes(tx *sql.Tx, stocks []domain.Stock) error {
+	values := make([]string, 0, len(stocks))
+	args := make([]interface{}, 0, len(stocks)*3)
+	for i, s := range stocks {
+		values = append(values, fmt.Sprintf("($%d::UUID, $%d::STRING, $%d::DECIMAL)", i*3+1, i*3+2, i*3+3))
+		args = append(args, s.ID, s.ActionType, s.TargetChangePct)
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE stocks SET
+			action_type = v.action_type,
+			target_change_pct = v.target_change_pct
+		FROM (VALUES %s) AS v (id, action_type, target_change_pct)
+		WHERE stocks.id = v.id
+	`, strings.Join(values, ", "))
+
+	_, err := tx.Exec(query, args...)
+	return err
+}
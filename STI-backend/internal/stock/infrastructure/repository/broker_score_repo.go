@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/viteant/stockinsight/internal/db"
+	"github.com/viteant/stockinsight/internal/stock/domain"
+)
+
+type PersistenceBrokerScoreRepository struct {
+	DB         *sql.DB
+	MaxRetries int
+}
+
+func NewCockroachBrokerScoreRepository(db *sql.DB) *PersistenceBrokerScoreRepository {
+	return &PersistenceBrokerScoreRepository{
+		DB:         db,
+		MaxRetries: envInt("STOCK_BATCH_RETRIES", defaultMaxRetries),
+	}
+}
+
+// FetchBrokerRecords lee los aciertos de broker_evaluation (horizonte 0) y de
+// broker_horizon_stats.
+func (r *PersistenceBrokerScoreRepository) FetchBrokerRecords() ([]domain.BrokerRecord, error) {
+	rows, err := r.DB.Query(`
+		SELECT brokerage, 0, price_basis, total_predictions, COALESCE(total_hits, 0)
+		FROM broker_evaluation
+		WHERE total_predictions > 0
+		UNION ALL
+		SELECT brokerage, horizon_days, price_basis, total_predictions, total_hits
+		FROM broker_horizon_stats
+		WHERE total_predictions > 0
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []domain.BrokerRecord
+	for rows.Next() {
+		var rec domain.BrokerRecord
+		if err := rows.Scan(
+			&rec.Brokerage,
+			&rec.HorizonDays,
+			&rec.PriceBasis,
+			&rec.Predictions,
+			&rec.Hits,
+		); err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+	return records, rows.Err()
+}
+
+// ReplaceBrokerScores reemplaza la tabla broker_scores en una sola transacción.
+func (r *PersistenceBrokerScoreRepository) ReplaceBrokerScores(scores []domain.BrokerScore) error {
+	return db.RunInTx(r.DB, r.MaxRetries, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM broker_scores WHERE true`); err != nil {
+			return err
+		}
+		for start := 0; start < len(scores); start += defaultBatchSize {
+			end := min(start+defaultBatchSize, len(scores))
+			if err := insertBrokerScores(tx, scores[start:end]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func insertBrokerScores(tx *sql.Tx, scores []domain.BrokerScore) error {
+	const columns = 12
+
+	values := make([]string, 0, len(scores))
+	args := make([]interface{}, 0, len(scores)*columns)
+	for i, s := range scores {
+		p := i * columns
+		values = append(values, fmt.Sprintf(
+			"($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
+			p+1, p+2, p+3, p+4, p+5, p+6, p+7, p+8, p+9, p+10, p+11, p+12,
+		))
+		args = append(args,
+			s.Brokerage,
+			s.HorizonDays,
+			string(s.PriceBasis),
+			s.Predictions,
+			s.Hits,
+			s.RawAccuracy,
+			s.PriorAlpha,
+			s.PriorBeta,
+			s.Score,
+			s.CILow,
+			s.CIHigh,
+			s.Method,
+		)
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO broker_scores (
+			brokerage, horizon_days, price_basis,
+			total_predictions, total_hits, raw_accuracy,
+			prior_alpha, prior_beta,
+			score, ci_low, ci_high, method
+		) VALUES %s
+	`, strings.Join(values, ",\n"))
+
+	_, err := tx.Exec(query, args...)
+	return err
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"github.com/lib/pq"
+	"github.com/viteant/stockinsight/internal/stock/domain"
+)
+
+// latestRatingsCTE deja el rating más reciente de cada brokerage por ticker.
+const latestRatingsCTE = `
+	WITH latest AS (
+		SELECT DISTINCT ON (ticker, brokerage) *
+		FROM stocks
+		ORDER BY ticker, brokerage, created_at DESC
+	)`
+
+// RankConsensusTickers devuelve los params.Limit tickers con mayor puntaje de
+// consenso y luego con más brokers. El puntaje se calcula como en
+// domain.BuildTickerConsensus: la media de los ratings ponderada por el
+// puntaje de cada brokerage en broker_scores.
+func (r *PersistenceStockRepository) RankConsensusTickers(params domain.ConsensusParams) ([]string, error) {
+	rows, err := r.DB.Query(latestRatingsCTE+`
+		SELECT l.ticker
+		FROM latest l
+		LEFT JOIN broker_scores b
+			ON b.brokerage = l.brokerage AND b.price_basis = $1 AND b.horizon_days = $2
+		GROUP BY l.ticker
+		ORDER BY
+			COALESCE(
+				SUM(COALESCE(b.score, $3) * CASE l.normalize_rating_to WHEN 'buy' THEN 1 WHEN 'sell' THEN -1 ELSE 0 END)
+					/ NULLIF(SUM(COALESCE(b.score, $3)), 0),
+				0
+			) DESC,
+			COUNT(*) DESC,
+			l.ticker
+		LIMIT $4
+	`, string(params.PriceBasis), params.HorizonDays, domain.DefaultBrokerWeight, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tickers []string
+	for rows.Next() {
+		var ticker string
+		if err := rows.Scan(&ticker); err != nil {
+			return nil, err
+		}
+		tickers = append(tickers, ticker)
+	}
+
+	return tickers, rows.Err()
+}
+
+// FetchLatestRatings devuelve el rating más reciente de cada brokerage sobre
+// los tickers pedidos.
+func (r *PersistenceStockRepository) FetchLatestRatings(tickers []string) ([]domain.Stock, error) {
+	rows, err := r.DB.Query(latestRatingsCTE+`
+		SELECT `+stockColumns+`
+		FROM latest
+		WHERE ticker = ANY($1)
+	`, pq.Array(tickers))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stocks []domain.Stock
+	for rows.Next() {
+		s, err := scanStock(rows)
+		if err != nil {
+			return nil, err
+		}
+		stocks = append(stocks, s)
+	}
+
+	return stocks, rows.Err()
+}
+
+// FetchLatestCloses devuelve el último cierre de los tickers pedidos que
+// tienen precios.
+func (r *PersistenceStockRepository) FetchLatestCloses(tickers []string) (map[string]domain.LatestClose, error) {
+	rows, err := r.DB.Query(`
+		SELECT DISTINCT ON (ticker) ticker, date, close
+		FROM finances
+		WHERE close IS NOT NULL AND ticker = ANY($1)
+		ORDER BY ticker, date DESC
+	`, pq.Array(tickers))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	closes := make(map[string]domain.LatestClose)
+	for rows.Next() {
+		var ticker string
+		var lc domain.LatestClose
+		if err := rows.Scan(&ticker, &lc.Date, &lc.Close); err != nil {
+			return nil, err
+		}
+		closes[ticker] = lc
+	}
+
+	return closes, rows.Err()
+}
+
+// FetchBrokerWeights devuelve el puntaje de cada brokerage en broker_scores.
+func (r *PersistenceStockRepository) FetchBrokerWeights(params domain.ConsensusParams) (map[string]float64, error) {
+	rows, err := r.DB.Query(`
+		SELECT brokerage, score
+		FROM broker_scores
+		WHERE price_basis = $1 AND horizon_days = $2
+	`, string(params.PriceBasis), params.HorizonDays)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	weights := make(map[string]float64)
+	for rows.Next() {
+		var brokerage string
+		var score float64
+		if err := rows.Scan(&brokerage, &score); err != nil {
+			return nil, err
+		}
+		weights[brokerage] = score
+	}
+
+	return weights, rows.Err()
+}
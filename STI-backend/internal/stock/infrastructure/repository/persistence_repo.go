@@ -2,34 +2,223 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	"os"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/viteant/stockinsight/internal/db"
 	"github.com/viteant/stockinsight/internal/stock/domain"
+	"github.com/viteant/stockinsight/internal/stock/infrastructure/ratingmap"
+)
+
+const (
+	defaultBatchSize  = 500
+	defaultMaxRetries = 5
+
+	// upsertColumns son los parámetros por fila de upsertStocks.
+	upsertColumns = 17
+	// maxBatchSize respeta el límite de 65535 parámetros por consulta del
+	// protocolo de Postgres.
+	maxBatchSize = 65535 / upsertColumns
 )
 
 type PersistenceStockRepository struct {
-	DB *sql.DB
+	DB         *sql.DB
+	BatchSize  int
+	MaxRetries int
+	Mapping    *domain.RatingMapping
 }
 
-func NewCockroachStockRepository(db *sql.DB) *PersistenceStockRepository {
-	return &PersistenceStockRepository{DB: db}
+// NewCockroachStockRepository toma el tamaño de lote de STOCK_BATCH_SIZE, los
+// reintentos por conflicto de serialización de STOCK_BATCH_RETRIES y el mapeo
+// de ratings de RATING_MAPPING_FILE. Falla si el mapeo no se puede cargar o si
+// STOCK_BATCH_SIZE supera maxBatchSize.
+func NewCockroachStockRepository(db *sql.DB) (*PersistenceStockRepository, error) {
+	mapping, err := ratingmap.LoadFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo cargar el mapeo de ratings: %w", err)
+	}
+
+	batchSize := envInt("STOCK_BATCH_SIZE", defaultBatchSize)
+	if batchSize > maxBatchSize {
+		return nil, fmt.Errorf("STOCK_BATCH_SIZE=%d supera el máximo de %d filas por lote", batchSize, maxBatchSize)
+	}
+
+	return &PersistenceStockRepository{
+		DB:         db,
+		BatchSize:  batchSize,
+		MaxRetries: envInt("STOCK_BATCH_RETRIES", defaultMaxRetries),
+		Mapping:    mapping,
+	}, nil
 }
 
-func (r *PersistenceStockRepository) Save(stock domain.Stock) error {
-	_, err := r.DB.Exec(`
+// Save inserta o actualiza un rating. Devuelve true si la fila no existía.
+func (r *PersistenceStockRepository) Save(stock domain.Stock) (bool, error) {
+	result, err := r.SaveBatch([]domain.Stock{stock})
+	if err != nil {
+		return false, err
+	}
+	return result.Inserted == 1, nil
+}
+
+// SaveBatch guarda los ratings con upserts multi-fila, en una transacción por
+// lote de BatchSize filas. Un lote que falla no detiene los siguientes; sus
+// filas se cuentan como fallidas, se devuelven en Rejected y el error se
+// devuelve al final. Los ratings que no están en el mapeo se registran en
+// unmapped_ratings solo si su lote se guardó.
+func (r *PersistenceStockRepository) SaveBatch(stocks []domain.Stock) (domain.SaveResult, error) {
+	var result domain.SaveResult
+	var errs []error
+
+	stocks = dedupeStocks(stocks)
+	unmappedRaws := make([][]string, len(stocks))
+	for i := range stocks {
+		unmappedRaws[i] = stocks[i].NormalizeRatings(r.Mapping)
+		stocks[i].Classify()
+	}
+	var unmapped []unmappedSighting
+
+	for _, b := range batches(len(stocks), r.BatchSize) {
+		start, end := b[0], b[1]
+		chunk := stocks[start:end]
+
+		var inserted int
+		err := db.RunInTx(r.DB, r.MaxRetries, func(tx *sql.Tx) error {
+			existing, err := countExistingStocks(tx, chunk)
+			if err != nil {
+				return err
+			}
+			if err := upsertStocks(tx, chunk); err != nil {
+				return err
+			}
+			inserted = len(chunk) - existing
+			return nil
+		})
+
+		if err != nil {
+			log.Printf("Error saving batch of %d stocks: %v", len(chunk), err)
+			result.Failed += len(chunk)
+			for _, s := range chunk {
+				result.Rejected = append(result.Rejected, domain.RejectedStock{Raw: domain.RawFromStock(s), Err: err})
+			}
+			errs = append(errs, err)
+			continue
+		}
+
+		result.Inserted += inserted
+		result.Updated += len(chunk) - inserted
+		for i := start; i < end; i++ {
+			for _, raw := range unmappedRaws[i] {
+				unmapped = append(unmapped, unmappedSighting{raw: raw, brokerage: stocks[i].Brokerage})
+			}
+		}
+	}
+	if err := r.recordUnmapped(unmapped); err != nil {
+		log.Printf("Error registrando ratings sin mapeo: %v", err)
+	}
+
+	log.Printf("Stocks saved: %d inserted, %d updated, %d failed", result.Inserted, result.Updated, result.Failed)
+	return result, errors.Join(errs...)
+}
+
+// batches divide n filas en lotes [inicio, fin) de hasta size filas. Un size
+// fuera de (0, maxBatchSize] usa defaultBatchSize o maxBatchSize.
+func batches(n, size int) [][2]int {
+	if size <= 0 {
+		size = defaultBatchSize
+	}
+	size = min(size, maxBatchSize)
+
+	var out [][2]int
+	for start := 0; start < n; start += size {
+		out = append(out, [2]int{start, min(start+size, n)})
+	}
+	return out
+}
+
+// dedupeStocks deja una sola fila por (ticker, created_at), conservando la
+// última; un upsert multi-fila no puede tocar la misma fila dos veces.
+func dedupeStocks(stocks []domain.Stock) []domain.Stock {
+	index := make(map[string]int, len(stocks))
+	result := make([]domain.Stock, 0, len(stocks))
+
+	for _, s := range stocks {
+		key := s.Ticker + "|" + s.ReportedAt.UTC().Format(time.RFC3339Nano)
+		if i, ok := index[key]; ok {
+			result[i] = s
+			continue
+		}
+		index[key] = len(result)
+		result = append(result, s)
+	}
+
+	return result
+}
+
+func countExistingStocks(tx *sql.Tx, stocks []domain.Stock) (int, error) {
+	tuples := make([]string, 0, len(stocks))
+	args := make([]interface{}, 0, len(stocks)*2)
+	for i, s := range stocks {
+		tuples = append(tuples, fmt.Sprintf("($%d, $%d)", i*2+1, i*2+2))
+		args = append(args, s.Ticker, s.ReportedAt)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT COUNT(*) FROM stocks
+		WHERE (ticker, created_at) IN (%s)
+	`, strings.Join(tuples, ", "))
+
+	var count int
+	err := tx.QueryRow(query, args...).Scan(&count)
+	return count, err
+}
+
+func upsertStocks(tx *sql.Tx, stocks []domain.Stock) error {
+	const columns = upsertColumns
+
+	values := make([]string, 0, len(stocks))
+	args := make([]interface{}, 0, len(stocks)*columns)
+	for i, s := range stocks {
+		p := i * columns
+		values = append(values, fmt.Sprintf(
+			"(gen_random_uuid(), $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
+			p+1, p+2, p+3, p+4, p+5, p+6, p+7, p+8, p+9, p+10, p+11, p+12, p+13, p+14, p+15, p+16, p+17,
+		))
+		args = append(args,
+			s.Ticker,
+			s.Company,
+			s.Brokerage,
+			s.Action,
+			s.RatingFrom,
+			s.RatingTo,
+			s.NormalizeRatingFrom,
+			s.NormalizeRatingTo,
+			s.NormalizeRatingFrom5,
+			s.NormalizeRatingTo5,
+			s.RatingMappingVersion,
+			s.TargetFrom,
+			s.TargetTo,
+			nullString(s.Currency),
+			s.ActionType,
+			s.TargetChangePct,
+			s.ReportedAt,
+		)
+	}
+
+	query := fmt.Sprintf(`
 		INSERT INTO stocks (
 			id, ticker, company, brokerage, action,
 			rating_from, rating_to,
 			normalize_rating_from, normalize_rating_to,
-			target_from, target_to, created_at
-		) VALUES (
-			gen_random_uuid(), $1, $2, $3, $4,
-			$5, $6,
-			$7, $8,
-			$9, $10, $11
-		)
+			normalize_rating_from_5, normalize_rating_to_5, rating_mapping_version,
+			target_from, target_to, currency,
+			action_type, target_change_pct, created_at
+		) VALUES %s
 		ON CONFLICT (ticker, created_at) DO UPDATE SET
 			company = excluded.company,
 			brokerage = excluded.brokerage,
@@ -38,100 +227,169 @@ func (r *PersistenceStockRepository) Save(stock domain.Stock) error {
 			rating_to = excluded.rating_to,
 			normalize_rating_from = excluded.normalize_rating_from,
 			normalize_rating_to = excluded.normalize_rating_to,
+			normalize_rating_from_5 = excluded.normalize_rating_from_5,
+			normalize_rating_to_5 = excluded.normalize_rating_to_5,
+			rating_mapping_version = excluded.rating_mapping_version,
 			target_from = excluded.target_from,
-			target_to = excluded.target_to
-	`,
-		stock.Ticker,
-		stock.Company,
-		stock.Brokerage,
-		stock.Action,
-		stock.RatingFrom,
-		stock.RatingTo,
-		stock.NormalizeRatingFrom,
-		stock.NormalizeRatingTo,
-		stock.TargetFrom,
-		stock.TargetTo,
-		stock.ReportedAt,
+			target_to = excluded.target_to,
+			currency = excluded.currency,
+			action_type = excluded.action_type,
+			target_change_pct = excluded.target_change_pct
+	`, strings.Join(values, ",\n"))
+
+	_, err := tx.Exec(query, args...)
+	return err
+}
+
+// stockColumns son las columnas que lee scanStock, en orden.
+const stockColumns = `
+	id, ticker, company, brokerage, action,
+	rating_from, rating_to,
+	normalize_rating_from, normalize_rating_to,
+	COALESCE(normalize_rating_from_5, ''), COALESCE(normalize_rating_to_5, ''),
+	COALESCE(rating_mapping_version, 0),
+	target_from, target_to, COALESCE(currency, ''),
+	COALESCE(action_type, ''), target_change_pct, created_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanStock(row rowScanner) (domain.Stock, error) {
+	var s domain.Stock
+	err := row.Scan(
+		&s.ID,
+		&s.Ticker,
+		&s.Company,
+		&s.Brokerage,
+		&s.Action,
+		&s.RatingFrom,
+		&s.RatingTo,
+		&s.NormalizeRatingFrom,
+		&s.NormalizeRatingTo,
+		&s.NormalizeRatingFrom5,
+		&s.NormalizeRatingTo5,
+		&s.RatingMappingVersion,
+		&s.TargetFrom,
+		&s.TargetTo,
+		&s.Currency,
+		&s.ActionType,
+		&s.TargetChangePct,
+		&s.ReportedAt,
 	)
+	return s, err
+}
 
-	if err != nil {
-		log.Printf("Error saving stock %s: %v", stock.Ticker, err)
-	} else {
-		log.Printf("Stock saved or updated: %s", stock.Ticker)
+func envInt(key string, fallback int) int {
+	if v := os.Getenv(key); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
 	}
-
-	return err
+	return fallback
 }
 
-func (r *PersistenceStockRepository) FetchRecommendations() ([]domain.StockRecommendation, error) {
-	query := `
-		(
-			SELECT
-			    s.id,
-				s.ticker,
-				s.company,
-				s.brokerage,
-				s.action,
-				s.target_from,
-				s.target_to,
-				s.normalize_rating_from,
-				s.normalize_rating_to,
-				b.weight_score
-			FROM stocks s
-			JOIN broker_evaluation b ON s.brokerage = b.brokerage
-			WHERE s.normalize_rating_to = 'buy'
-			ORDER BY b.weight_score DESC
-			LIMIT 10
-		)
-		UNION ALL
-		(
+// FetchRecommendations ordena por el puntaje Beta-binomial de broker_scores
+// al horizonte pedido (0 es el cierre más cercano al rating). Los brokers que
+// todavía no tienen puntaje, por ejemplo antes del primer --rescore, usan la
+// media del prior del grupo (0.5 si broker_scores está vacía) con el método
+// ScoreMethodPrior.
+//
+// Con TrackHalfLifeDays > 0 el historial del broker se recalcula pesando cada
+// predicción por 0.5^(antigüedad / vida media) sobre el mismo prior guardado
+// en broker_scores. Con RatingHalfLifeDays > 0 el puntaje de cada rating se
+// multiplica por 0.5^(antigüedad del rating / vida media), así un "buy"
+// viejo queda por debajo de uno reciente del mismo broker.
+func (r *PersistenceStockRepository) FetchRecommendations(params domain.RecommendationParams) ([]domain.StockRecommendation, error) {
+	outcomes := `
+		SELECT brokerage, prediction_date, is_correct::INT AS is_correct
+		FROM prediction_outcomes
+		WHERE price_basis = $1 AND horizon_days = $2
+	`
+	if params.HorizonDays == 0 {
+		outcomes = `
+			SELECT brokerage, prediction_date, is_correct
+			FROM broker_predictions
+			WHERE price_basis = $1 AND is_correct IS NOT NULL
+		`
+	}
+
+	blocks := make([]string, 0, 3)
+	for _, rating := range []string{"buy", "hold", "sell"} {
+		blocks = append(blocks, recommendationBlock(rating, params.DistinctTicker))
+	}
+
+	query := fmt.Sprintf(`
+		WITH outcomes AS (%s),
+		decayed AS (
+			SELECT brokerage, SUM(w) AS n, SUM(w * is_correct) AS hits
+			FROM (
+				SELECT
+					brokerage,
+					is_correct::FLOAT AS is_correct,
+					power(0.5::FLOAT, extract(epoch FROM now() - prediction_date)::FLOAT / 86400 / NULLIF($3::FLOAT, 0)) AS w
+				FROM outcomes
+				WHERE $3::FLOAT > 0
+			) o
+			GROUP BY brokerage
+		),
+		prior AS (
+			SELECT COALESCE(AVG(prior_alpha::FLOAT / NULLIF(prior_alpha::FLOAT + prior_beta::FLOAT, 0)), 0.5) AS mean
+			FROM broker_scores
+			WHERE price_basis = $1 AND horizon_days = $2
+		),
+		scores AS (
 			SELECT
-			    s.id,
-				s.ticker,
-				s.company,
-				s.brokerage,
-				s.action,
-				s.target_from,
-				s.target_to,
-				s.normalize_rating_from,
-				s.normalize_rating_to,
-				b.weight_score
-			FROM stocks s
-			JOIN broker_evaluation b ON s.brokerage = b.brokerage
-			WHERE s.normalize_rating_to = 'hold'
-			ORDER BY b.weight_score DESC
-			LIMIT 10
+				br.brokerage,
+				CASE
+					WHEN b.brokerage IS NULL THEN p.mean
+					WHEN $3::FLOAT > 0
+					THEN (b.prior_alpha::FLOAT + COALESCE(d.hits, 0)) / (b.prior_alpha::FLOAT + b.prior_beta::FLOAT + COALESCE(d.n, 0))
+					ELSE b.score::FLOAT END AS score,
+				COALESCE(b.method, $10) AS method,
+				COALESCE(b.ci_low, 0) AS ci_low,
+				COALESCE(b.ci_high, 1) AS ci_high,
+				COALESCE(b.total_predictions, 0) AS total_predictions,
+				CASE WHEN $3::FLOAT > 0 AND b.brokerage IS NOT NULL
+					THEN b.prior_alpha::FLOAT + COALESCE(d.hits, 0) END AS post_alpha,
+				CASE WHEN $3::FLOAT > 0 AND b.brokerage IS NOT NULL
+					THEN b.prior_beta::FLOAT + COALESCE(d.n, 0) - COALESCE(d.hits, 0) END AS post_beta
+			FROM (SELECT DISTINCT brokerage FROM stocks) br
+			CROSS JOIN prior p
+			LEFT JOIN broker_scores b
+				ON b.brokerage = br.brokerage AND b.price_basis = $1 AND b.horizon_days = $2
+			LEFT JOIN decayed d ON d.brokerage = br.brokerage
+			WHERE COALESCE(b.total_predictions, 0) >= $5
 		)
-		UNION ALL
-		(
-			SELECT
-			    s.id,
-				s.ticker,
-				s.company,
-				s.brokerage,
-				s.action,
-				s.target_from,
-				s.target_to,
-				s.normalize_rating_from,
-				s.normalize_rating_to,
-				b.weight_score
-			FROM stocks s
-			JOIN broker_evaluation b ON s.brokerage = b.brokerage
-			WHERE s.normalize_rating_to = 'sell'
-			ORDER BY b.weight_score DESC
-			LIMIT 10
-		);
-	`
+		%s;
+	`, outcomes, strings.Join(blocks, "\n\t\tUNION ALL\n"))
 
-	rows, err := r.DB.Query(query)
+	rows, err := r.DB.Query(query,
+		string(params.PriceBasis),
+		params.HorizonDays,
+		params.TrackHalfLifeDays,
+		params.RatingHalfLifeDays,
+		params.MinPredictions,
+		nullTime(params.Since),
+		escapeLike(params.Brokerage),
+		params.Limit,
+		params.ActionType,
+		domain.ScoreMethodPrior,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	// El intervalo guardado en broker_scores no tiene decaimiento; con
+	// decaimiento se recalcula con los mismos conteos efectivos del puntaje.
+	type posterior struct{ alpha, beta float64 }
+	intervals := make(map[posterior][2]float64)
+
 	var results []domain.StockRecommendation
 	for rows.Next() {
 		var r domain.StockRecommendation
+		var postAlpha, postBeta sql.NullFloat64
 		if err := rows.Scan(
 			&r.ID,
 			&r.Ticker,
@@ -142,127 +400,270 @@ func (r *PersistenceStockRepository) FetchRecommendations() ([]domain.StockRecom
 			&r.TargetTo,
 			&r.NormalizeRatingFrom,
 			&r.NormalizeRatingTo,
+			&r.ActionType,
 			&r.WeightScore,
+			&r.ScoreMethod,
+			&r.ScoreCILow,
+			&r.ScoreCIHigh,
+			&r.BrokerPredictions,
+			&postAlpha,
+			&postBeta,
 		); err != nil {
 			return nil, err
 		}
+		if postAlpha.Valid && postBeta.Valid {
+			key := posterior{postAlpha.Float64, postBeta.Float64}
+			ci, ok := intervals[key]
+			if !ok {
+				ci[0], ci[1] = domain.CredibleInterval(key.alpha, key.beta)
+				intervals[key] = ci
+			}
+			r.ScoreCILow, r.ScoreCIHigh = ci[0], ci[1]
+		}
+		if params.TrackHalfLifeDays > 0 || params.RatingHalfLifeDays > 0 {
+			r.ScoreMethod += domain.ScoreMethodTimeDecaySuffix
+		}
 		results = append(results, r)
 	}
 
 	return results, nil
 }
 
-func (r *PersistenceStockRepository) FetchAllStocks(
-	page, limit int,
-	filters map[string]string,
-	orderBy, orderDir string,
-) ([]domain.Stock, int, error) {
-	offset := (page - 1) * limit
-
-	allowedOrderFields := map[string]bool{
-		"created_at":  true,
-		"target_to":   true,
-		"target_from": true,
-		"rating_to":   true,
-		"rating_from": true,
-		"action":      true,
-		"ticker":      true,
-		"company":     true,
-		"brokerage":   true,
-		"id":          true,
+// recommendationBlock arma la consulta de un tipo de rating sobre el CTE
+// scores. Con distinct se deja solo el rating mejor puntuado de cada ticker.
+func recommendationBlock(rating string, distinct bool) string {
+	distinctOn, innerOrder := "", ""
+	if distinct {
+		distinctOn = "DISTINCT ON (s.ticker)"
+		innerOrder = "ORDER BY s.ticker, weight DESC"
 	}
 
-	if !allowedOrderFields[orderBy] {
-		orderBy = "created_at"
-	}
-	if strings.ToLower(orderDir) != "asc" {
-		orderDir = "DESC"
-	} else {
-		orderDir = "ASC"
+	return fmt.Sprintf(`
+		(
+			SELECT * FROM (
+				SELECT %[1]s
+					s.id,
+					s.ticker,
+					s.company,
+					s.brokerage,
+					s.action,
+					s.target_from,
+					s.target_to,
+					s.normalize_rating_from,
+					s.normalize_rating_to,
+					COALESCE(s.action_type, '') AS action_type,
+					b.score * CASE WHEN $4::FLOAT > 0
+						THEN power(0.5::FLOAT, extract(epoch FROM now() - s.created_at)::FLOAT / 86400 / NULLIF($4::FLOAT, 0))
+						ELSE 1 END AS weight,
+					b.method,
+					b.ci_low,
+					b.ci_high,
+					b.total_predictions,
+					b.post_alpha,
+					b.post_beta
+				FROM stocks s
+				JOIN scores b ON s.brokerage = b.brokerage
+				WHERE s.normalize_rating_to = '%[2]s'
+					AND ($6::TIMESTAMPTZ IS NULL OR s.created_at >= $6)
+					AND ($7::STRING = '' OR s.brokerage ILIKE ('%%' || $7 || '%%'))
+					AND ($9::STRING = '' OR s.action_type = $9)
+				%[3]s
+			) AS r
+			ORDER BY weight DESC
+			LIMIT $8
+		)`, distinctOn, rating, innerOrder)
+}
+
+// FetchAllStocks devuelve una página de ratings con los cursores de las
+// páginas vecinas. Sin cursor pagina con OFFSET según q.Page; con cursor
+// busca por (OrderBy, id), así que no se saltea ni repite filas aunque se
+// inserten otras durante la navegación. q debe venir normalizado: OrderBy se
+// valida contra StockOrderColumns porque se interpola en el SQL.
+func (r *PersistenceStockRepository) FetchAllStocks(q domain.StockQuery) (domain.StockList, error) {
+	if !slices.Contains(domain.StockOrderColumns, q.OrderBy) {
+		return domain.StockList{}, fmt.Errorf("columna de orden inválida: %q", q.OrderBy)
 	}
 
-	whereClauses := []string{}
-	args := []interface{}{}
-	argIndex := 1
+	whereClauses, args := stockFilters(q)
 
-	for _, field := range []string{"ticker", "company", "brokerage"} {
-		if val, ok := filters[field]; ok && val != "" {
-			whereClauses = append(whereClauses, fmt.Sprintf("%s ILIKE $%d", field, argIndex))
-			args = append(args, "%"+val+"%")
-			argIndex++
-		}
+	// Las páginas anteriores se recorren en el orden inverso y después se da
+	// vuelta el resultado.
+	backward := q.Cursor != nil && q.Cursor.Before
+	desc := q.OrderDesc != backward
+	orderDir, cmp := "ASC", ">"
+	if desc {
+		orderDir, cmp = "DESC", "<"
 	}
 
-	for key, column := range map[string]string{
-		"target_from_min": "target_from >= $%d",
-		"target_from_max": "target_from <= $%d",
-		"target_to_min":   "target_to >= $%d",
-		"target_to_max":   "target_to <= $%d",
-		"date_from":       "created_at >= $%d",
-		"date_to":         "created_at <= $%d",
-		"id":              "id = $%d",
-	} {
-		if val, ok := filters[key]; ok && val != "" {
-			whereClauses = append(whereClauses, fmt.Sprintf(column, argIndex))
-			args = append(args, val)
-			argIndex++
+	if c := q.Cursor; c != nil {
+		if q.OrderBy == "id" {
+			args = append(args, c.ID)
+			whereClauses = append(whereClauses, fmt.Sprintf("id %s $%d", cmp, len(args)))
+		} else {
+			args = append(args, c.Value, c.ID)
+			whereClauses = append(whereClauses, fmt.Sprintf("(%s, id) %s ($%d, $%d)", q.OrderBy, cmp, len(args)-1, len(args)))
 		}
 	}
 
-	whereSQL := ""
-	if len(whereClauses) > 0 {
-		whereSQL = "WHERE " + strings.Join(whereClauses, " AND ")
+	// id desempata para que el orden entre páginas sea estable.
+	orderSQL := fmt.Sprintf("%s %s", q.OrderBy, orderDir)
+	if q.OrderBy != "id" {
+		orderSQL += ", id " + orderDir
+	}
+
+	offset := 0
+	if q.Cursor == nil {
+		offset = (q.Page - 1) * q.Limit
 	}
+	// Una fila de más indica si hay otra página en esa dirección.
+	args = append(args, q.Limit+1, offset)
 
 	query := fmt.Sprintf(`
-		SELECT
-			id, ticker, company, brokerage, action,
-			rating_from, rating_to,
-			normalize_rating_from, normalize_rating_to,
-			target_from, target_to, created_at
+		SELECT %s, %s::STRING
 		FROM stocks
 		%s
-		ORDER BY %s %s
+		ORDER BY %s
 		LIMIT $%d OFFSET $%d;
-	`, whereSQL, orderBy, orderDir, argIndex, argIndex+1)
-
-	args = append(args, limit, offset)
+	`, stockColumns, q.OrderBy, whereSQL(whereClauses), orderSQL, len(args)-1, len(args))
 
 	rows, err := r.DB.Query(query, args...)
 	if err != nil {
-		return nil, 0, err
+		return domain.StockList{}, err
 	}
 	defer rows.Close()
 
 	var stocks []domain.Stock
+	var keys []string
 	for rows.Next() {
-		var s domain.Stock
-		if err := rows.Scan(
-			&s.ID,
-			&s.Ticker,
-			&s.Company,
-			&s.Brokerage,
-			&s.Action,
-			&s.RatingFrom,
-			&s.RatingTo,
-			&s.NormalizeRatingFrom,
-			&s.NormalizeRatingTo,
-			&s.TargetFrom,
-			&s.TargetTo,
-			&s.ReportedAt,
-		); err != nil {
-			return nil, 0, err
+		var key string
+		s, err := scanStock(keyedRow{rows, &key})
+		if err != nil {
+			return domain.StockList{}, err
 		}
 		stocks = append(stocks, s)
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return domain.StockList{}, err
+	}
+
+	hasMore := len(stocks) > q.Limit
+	if hasMore {
+		stocks, keys = stocks[:q.Limit], keys[:q.Limit]
 	}
+	if backward {
+		slices.Reverse(stocks)
+		slices.Reverse(keys)
+	}
+
+	list := domain.StockList{Items: stocks}
+	if len(stocks) == 0 {
+		return list, nil
+	}
+
+	hash := q.CursorHash()
+	cursor := func(i int, before bool) string {
+		return domain.StockCursor{
+			OrderBy: q.OrderBy,
+			Desc:    q.OrderDesc,
+			Value:   keys[i],
+			ID:      stocks[i].ID,
+			Before:  before,
+			Query:   hash,
+		}.Encode()
+	}
+	// Si se llegó con un cursor, del lado de donde se vino siempre hay filas.
+	if hasMore || backward {
+		list.NextCursor = cursor(len(stocks)-1, false)
+	}
+	if (backward && hasMore) || (!backward && (q.Cursor != nil || q.Page > 1)) {
+		list.PrevCursor = cursor(0, true)
+	}
+	return list, nil
+}
 
-	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM stocks %s`, whereSQL)
-	countRow := r.DB.QueryRow(countQuery, args[:argIndex-1]...)
+// CountStocks cuenta las filas que cumplen los filtros de q.
+func (r *PersistenceStockRepository) CountStocks(q domain.StockQuery) (int, error) {
+	whereClauses, args := stockFilters(q)
 
 	var total int
-	if err := countRow.Scan(&total); err != nil {
-		return nil, 0, err
+	err := r.DB.QueryRow(`SELECT COUNT(*) FROM stocks `+whereSQL(whereClauses), args...).Scan(&total)
+	return total, err
+}
+
+// likeEscaper escapa los comodines de LIKE para que el texto del filtro se
+// busque literal (p. ej. "100%" o "J_P").
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// escapeLike devuelve val listo para usarse dentro de un patrón ILIKE.
+func escapeLike(val string) string {
+	return likeEscaper.Replace(val)
+}
+
+// stockFilters arma las condiciones de los filtros de la lista.
+func stockFilters(q domain.StockQuery) ([]string, []interface{}) {
+	whereClauses := []string{}
+	args := []interface{}{}
+	where := func(clause string, arg interface{}) {
+		args = append(args, arg)
+		whereClauses = append(whereClauses, fmt.Sprintf(clause, len(args)))
+	}
+
+	// Slices y no mapas: el orden de las cláusulas y de los parámetros tiene
+	// que ser estable para que la consulta sea siempre la misma.
+	for _, f := range []struct {
+		column string
+		val    string
+	}{
+		{"ticker", q.Ticker},
+		{"company", q.Company},
+		{"brokerage", q.Brokerage},
+	} {
+		if f.val != "" {
+			where(f.column+" ILIKE $%d", "%"+escapeLike(f.val)+"%")
+		}
+	}
+	for _, f := range []struct {
+		clause string
+		val    *float64
+	}{
+		{"target_from >= $%d", q.TargetFromMin},
+		{"target_from <= $%d", q.TargetFromMax},
+		{"target_to >= $%d", q.TargetToMin},
+		{"target_to <= $%d", q.TargetToMax},
+	} {
+		if f.val != nil {
+			where(f.clause, *f.val)
+		}
+	}
+	if !q.CreatedFrom.IsZero() {
+		where("created_at >= $%d", q.CreatedFrom)
+	}
+	if !q.CreatedBefore.IsZero() {
+		where("created_at < $%d", q.CreatedBefore)
 	}
+	if q.ID != "" {
+		where("id = $%d", q.ID)
+	}
+	if q.ActionType != "" {
+		where("action_type = $%d", q.ActionType)
+	}
+	return whereClauses, args
+}
+
+func whereSQL(clauses []string) string {
+	if len(clauses) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(clauses, " AND ")
+}
+
+// keyedRow agrega a scanStock la columna extra con el valor de orden.
+type keyedRow struct {
+	rows *sql.Rows
+	key  *string
+}
 
-	return stocks, total, nil
+func (k keyedRow) Scan(dest ...interface{}) error {
+	return k.rows.Scan(append(dest, k.key)...)
 }
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viteant/stockinsight/internal/stock/domain"
+)
+
+func TestEscapeLike(t *testing.T) {
+	assert.Equal(t, "J.P. Morgan", escapeLike("J.P. Morgan"))
+	assert.Equal(t, `100\%`, escapeLike("100%"))
+	assert.Equal(t, `J\_P`, escapeLike("J_P"))
+	assert.Equal(t, `a\\b`, escapeLike(`a\b`))
+}
+
+func TestBatches(t *testing.T) {
+	assert.Nil(t, batches(0, 10))
+	assert.Equal(t, [][2]int{{0, 10}, {10, 20}, {20, 25}}, batches(25, 10))
+	assert.Equal(t, [][2]int{{0, 3}}, batches(3, 10))
+	// Sin tamaño se usa el de por defecto y nunca se supera el límite de
+	// parámetros del protocolo.
+	assert.Equal(t, [][2]int{{0, defaultBatchSize}, {defaultBatchSize, 600}}, batches(600, 0))
+	assert.Equal(t, [2]int{0, maxBatchSize}, batches(5000, 10000)[0])
+	assert.LessOrEqual(t, maxBatchSize*upsertColumns, 65535)
+}
+
+func TestNewCockroachStockRepository_RejectsOversizedBatches(t *testing.T) {
+	t.Setenv("STOCK_BATCH_SIZE", "4000")
+	_, err := NewCockroachStockRepository(nil)
+	assert.ErrorContains(t, err, "STOCK_BATCH_SIZE")
+
+	t.Setenv("STOCK_BATCH_SIZE", "1000")
+	repo, err := NewCockroachStockRepository(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1000, repo.BatchSize)
+}
+
+func TestDedupeStocks_KeepsFirstPositionAndLastValue(t *testing.T) {
+	at := time.Date(2025, 3, 10, 15, 0, 0, 0, time.UTC)
+	stocks := []domain.Stock{
+		{Ticker: "AAPL", ReportedAt: at, Brokerage: "primero"},
+		{Ticker: "MSFT", ReportedAt: at},
+		// La misma fila en otra zona horaria.
+		{Ticker: "AAPL", ReportedAt: at.In(time.FixedZone("ART", -3*3600)), Brokerage: "último"},
+		{Ticker: "AAPL", ReportedAt: at.Add(time.Second)},
+	}
+
+	got := dedupeStocks(stocks)
+
+	assert.Len(t, got, 3)
+	assert.Equal(t, []string{"AAPL", "MSFT", "AAPL"}, []string{got[0].Ticker, got[1].Ticker, got[2].Ticker})
+	assert.Equal(t, "último", got[0].Brokerage)
+	assert.Equal(t, at.Add(time.Second), got[2].ReportedAt)
+}
+
+func TestStockFilters_StableOrder(t *testing.T) {
+	low, high := 10.0, 20.0
+	q := domain.StockQuery{
+		Ticker:        "AA",
+		Company:       "Apple",
+		Brokerage:     "Morgan",
+		TargetFromMin: &low,
+		TargetFromMax: &high,
+		TargetToMin:   &low,
+		TargetToMax:   &high,
+	}
+
+	for range 20 {
+		clauses, args := stockFilters(q)
+		assert.Equal(t, []string{
+			"ticker ILIKE $1",
+			"company ILIKE $2",
+			"brokerage ILIKE $3",
+			"target_from >= $4",
+			"target_from <= $5",
+			"target_to >= $6",
+			"target_to <= $7",
+		}, clauses)
+		assert.Equal(t, []interface{}{"%AA%", "%Apple%", "%Morgan%", low, high, low, high}, args)
+	}
+}
+
+func TestCountUnmapped_GroupsByRatingKey(t *testing.T) {
+	counts := countUnmapped([]unmappedSighting{
+		{raw: "Sector Perform", brokerage: "A"},
+		{raw: " sector  perform ", brokerage: "B"},
+		{raw: "Top Pick", brokerage: "A"},
+	})
+
+	assert.Equal(t, []unmappedCount{
+		{key: "sector perform", occurrences: 2, brokerage: "B"},
+		{key: "top pick", occurrences: 1, brokerage: "A"},
+	}, counts)
+}
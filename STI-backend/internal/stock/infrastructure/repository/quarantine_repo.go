@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+
+	quarantinedomain "github.com/viteant/stockinsight/internal/quarantine/domain"
+	quarantinerepository "github.com/viteant/stockinsight/internal/quarantine/infrastructure/repository"
+	"github.com/viteant/stockinsight/internal/stock/domain"
+)
+
+// QuarantineStocks guarda los ratings rechazados con el payload original, el
+// motivo y, si el rechazo fue por validación, los campos inválidos.
+func (r *PersistenceStockRepository) QuarantineStocks(source string, rejected []domain.RejectedStock) error {
+	records := make([]quarantinedomain.Record, 0, len(rejected))
+	for _, rej := range rejected {
+		payload, err := json.Marshal(rej.Raw)
+		if err != nil {
+			return err
+		}
+
+		rec := quarantinedomain.Record{
+			Kind:    quarantinedomain.KindStock,
+			Source:  source,
+			Payload: payload,
+			Reason:  rej.Err.Error(),
+		}
+		var verr *domain.ValidationError
+		if errors.As(rej.Err, &verr) {
+			if rec.Errors, err = json.Marshal(verr.Fields); err != nil {
+				return err
+			}
+		}
+		records = append(records, rec)
+	}
+
+	return quarantinerepository.NewCockroachQuarantineRepository(r.DB).Add(records)
+}
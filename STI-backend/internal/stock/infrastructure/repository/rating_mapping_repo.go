@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/viteant/stockinsight/internal/db"
+	"github.com/viteant/stockinsight/internal/stock/domain"
+)
+
+type unmappedSighting struct {
+	raw       string
+	brokerage string
+}
+
+type unmappedCount struct {
+	key         string
+	occurrences int
+	brokerage   string
+}
+
+// countUnmapped agrupa las apariciones por domain.RatingKey, la misma clave
+// con que el mapeo compara, en el orden en que aparecen. brokerage es el del
+// último avistamiento.
+func countUnmapped(sightings []unmappedSighting) []unmappedCount {
+	index := make(map[string]int)
+	var counts []unmappedCount
+	for _, s := range sightings {
+		key := domain.RatingKey(s.raw)
+		i, ok := index[key]
+		if !ok {
+			i = len(counts)
+			index[key] = i
+			counts = append(counts, unmappedCount{key: key})
+		}
+		counts[i].occurrences++
+		counts[i].brokerage = s.brokerage
+	}
+	return counts
+}
+
+// recordUnmapped suma las apariciones de cada rating sin mapeo, guardado con
+// su domain.RatingKey. occurrences cuenta cada vez que el rating se guardó,
+// incluidas las resincronizaciones.
+func (r *PersistenceStockRepository) recordUnmapped(sightings []unmappedSighting) error {
+	if len(sightings) == 0 {
+		return nil
+	}
+
+	counts := countUnmapped(sightings)
+	values := make([]string, 0, len(counts))
+	args := make([]interface{}, 0, len(counts)*4)
+	for i, c := range counts {
+		values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d, now(), now())", i*4+1, i*4+2, i*4+3, i*4+4))
+		args = append(args, c.key, c.occurrences, c.brokerage, r.Mapping.Version)
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO unmapped_ratings (raw_rating, occurrences, last_brokerage, mapping_version, first_seen_at, last_seen_at)
+		VALUES %s
+		ON CONFLICT (raw_rating) DO UPDATE SET
+			occurrences = unmapped_ratings.occurrences + excluded.occurrences,
+			last_brokerage = excluded.last_brokerage,
+			mapping_version = excluded.mapping_version,
+			last_seen_at = excluded.last_seen_at
+	`, strings.Join(values, ", "))
+
+	_, err := r.DB.Exec(query, args...)
+	return err
+}
+
+// ListUnmappedRatings devuelve los ratings sin mapeo, los más frecuentes primero.
+func (r *PersistenceStockRepository) ListUnmappedRatings() ([]domain.UnmappedRating, error) {
+	rows, err := r.DB.Query(`
+		SELECT raw_rating, occurrences, COALESCE(last_brokerage, ''), mapping_version, first_seen_at, last_seen_at
+		FROM unmapped_ratings
+		ORDER BY occurrences DESC, raw_rating
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ratings []domain.UnmappedRating
+	for rows.Next() {
+		var u domain.UnmappedRating
+		if err := rows.Scan(
+			&u.RawRating,
+			&u.Occurrences,
+			&u.LastBrokerage,
+			&u.MappingVersion,
+			&u.FirstSeenAt,
+			&u.LastSeenAt,
+		); err != nil {
+			return nil, err
+		}
+		ratings = append(ratings, u)
+	}
+	return ratings, rows.Err()
+}
+
+// Renormalize vuelve a normalizar y clasificar los ratings guardados con otra
+// versión del mapeo (todos con all=true), en lotes de BatchSize filas. Al
+// terminar quita de unmapped_ratings los ratings que el mapeo ya reconoce.
+func (r *PersistenceStockRepository) Renormalize(all bool) (int, error) {
+	batchSize := r.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	updated := 0
+	lastID := "00000000-0000-0000-0000-000000000000"
+	for {
+		stocks, err := r.fetchForRenormalize(lastID, all, batchSize)
+		if err != nil {
+			return updated, err
+		}
+		if len(stocks) == 0 {
+			break
+		}
+
+		var unmapped []unmappedSighting
+		for i := range stocks {
+			for _, raw := range stocks[i].NormalizeRatings(r.Mapping) {
+				unmapped = append(unmapped, unmappedSighting{raw: raw, brokerage: stocks[i].Brokerage})
+			}
+			stocks[i].Classify()
+		}
+		if err := db.RunInTx(r.DB, r.MaxRetries, func(tx *sql.Tx) error {
+			return updateNormalizedRatings(tx, stocks)
+		}); err != nil {
+			return updated, err
+		}
+		if err := r.recordUnmapped(unmapped); err != nil {
+			log.Printf("Error registrando ratings sin mapeo: %v", err)
+		}
+
+		updated += len(stocks)
+		lastID = stocks[len(stocks)-1].ID
+		log.Printf("Renormalizados %d ratings", updated)
+	}
+
+	return updated, r.pruneUnmapped()
+}
+
+func (r *PersistenceStockRepository) fetchForRenormalize(afterID string, all bool, limit int) ([]domain.Stock, error) {
+	rows, err := r.DB.Query(`
+		SELECT id, brokerage, action,
+			COALESCE(rating_from, ''), COALESCE(rating_to, ''),
+			COALESCE(target_from, 0), COALESCE(target_to, 0)
+		FROM stocks
+		WHERE id > $1 AND ($2 OR rating_mapping_version IS DISTINCT FROM $3)
+		ORDER BY id
+		LIMIT $4
+	`, afterID, all, r.Mapping.Version, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stocks []domain.Stock
+	for rows.Next() {
+		var s domain.Stock
+		if err := rows.Scan(
+			&s.ID,
+			&s.Brokerage,
+			&s.Action,
+			&s.RatingFrom,
+			&s.RatingTo,
+			&s.TargetFrom,
+			&s.TargetTo,
+		); err != nil {
+			return nil, err
+		}
+		stocks = append(stocks, s)
+	}
+	return stocks, rows.Err()
+}
+
+func updateNormalizedRatings(tx *sql.Tx, stocks []domain.Stock) error {
+	const columns = 8
+
+	values := make([]string, 0, len(stocks))
+	args := make([]interface{}, 0, len(stocks)*columns)
+	for i, s := range stocks {
+		p := i * columns
+		values = append(values, fmt.Sprintf(
+			"($%d::UUID, $%d::STRING, $%d::STRING, $%d::STRING, $%d::STRING, $%d::INT, $%d::STRING, $%d::DECIMAL)",
+			p+1, p+2, p+3, p+4, p+5, p+6, p+7, p+8,
+		))
+		args = append(args,
+			s.ID,
+			s.NormalizeRatingFrom,
+			s.NormalizeRatingTo,
+			s.NormalizeRatingFrom5,
+			s.NormalizeRatingTo5,
+			s.RatingMappingVersion,
+			s.ActionType,
+			s.TargetChangePct,
+		)
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE stocks SET
+			normalize_rating_from = v.from3,
+			normalize_rating_to = v.to3,
+			normalize_rating_from_5 = v.from5,
+			normalize_rating_to_5 = v.to5,
+			rating_mapping_version = v.version,
+			action_type = v.action_type,
+			target_change_pct = v.target_change_pct
+		FROM (VALUES %s) AS v (id, from3, to3, from5, to5, version, action_type, target_change_pct)
+		WHERE stocks.id = v.id
+	`, strings.Join(values, ", "))
+
+	_, err := tx.Exec(query, args...)
+	return err
+}
+
+// pruneUnmapped borra los ratings sin mapeo que la versión actual ya reconoce.
+func (r *PersistenceStockRepository) pruneUnmapped() error {
+	ratings, err := r.ListUnmappedRatings()
+	if err != nil {
+		return err
+	}
+
+	var mapped []string
+	for _, u := range ratings {
+		if _, ok := r.Mapping.Normalize(u.RawRating); ok {
+			mapped = append(mapped, u.RawRating)
+		}
+	}
+	if len(mapped) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, len(mapped))
+	args := make([]interface{}, len(mapped))
+	for i, raw := range mapped {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = raw
+	}
+	_, err = r.DB.Exec(fmt.Sprintf(
+		`DELETE FROM unmapped_ratings WHERE raw_rating IN (%s)`, strings.Join(placeholders, ", "),
+	), args...)
+	if err == nil {
+		log.Printf("%d ratings ya reconocidos por el mapeo v%d", len(mapped), r.Mapping.Version)
+	}
+	return err
+}
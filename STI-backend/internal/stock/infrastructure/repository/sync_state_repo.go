@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/viteant/stockinsight/internal/stock/domain"
+)
+
+type PersistenceSyncStateRepository struct {
+	DB *sql.DB
+}
+
+func NewCockroachSyncStateRepository(db *sql.DB) *PersistenceSyncStateRepository {
+	return &PersistenceSyncStateRepository{DB: db}
+}
+
+func (r *PersistenceSyncStateRepository) GetSyncState(source string) (domain.SyncState, error) {
+	state := domain.SyncState{Source: source}
+
+	var lastReportedAt, runReportedAt, updatedAt sql.NullTime
+	err := r.DB.QueryRow(`
+		SELECT last_reported_at, run_reported_at, next_page, updated_at
+		FROM sync_state
+		WHERE source = $1
+	`, source).Scan(&lastReportedAt, &runReportedAt, &state.NextPage, &updatedAt)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+
+	state.LastReportedAt = lastReportedAt.Time
+	state.RunReportedAt = runReportedAt.Time
+	state.UpdatedAt = updatedAt.Time
+	return state, nil
+}
+
+func (r *PersistenceSyncStateRepository) SaveSyncState(state domain.SyncState) error {
+	_, err := r.DB.Exec(`
+		INSERT INTO sync_state (source, last_reported_at, run_reported_at, next_page, updated_at)
+		VALUES ($1, $2, $3, $4, now())
+		ON CONFLICT (source) DO UPDATE SET
+			last_reported_at = excluded.last_reported_at,
+			run_reported_at = excluded.run_reported_at,
+			next_page = excluded.next_page,
+			updated_at = excluded.updated_at
+	`,
+		state.Source,
+		nullTime(state.LastReportedAt),
+		nullTime(state.RunReportedAt),
+		state.NextPage,
+	)
+	return err
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/viteant/stockinsight/internal/stock/domain"
+)
+
+// FetchTickerRatings devuelve el historial de ratings del ticker ordenado por fecha.
+func (r *PersistenceStockRepository) FetchTickerRatings(ticker string) ([]domain.Stock, error) {
+	rows, err := r.DB.Query(`
+		SELECT `+stockColumns+`
+		FROM stocks
+		WHERE ticker = $1
+		ORDER BY created_at ASC, id ASC
+	`, strings.ToUpper(ticker))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stocks []domain.Stock
+	for rows.Next() {
+		s, err := scanStock(rows)
+		if err != nil {
+			return nil, err
+		}
+		stocks = append(stocks, s)
+	}
+
+	return stocks, rows.Err()
+}
+
+// FetchLatestClose devuelve el último cierre guardado en finances, o nil si no hay precios.
+func (r *PersistenceStockRepository) FetchLatestClose(ticker string) (*domain.LatestClose, error) {
+	var lc domain.LatestClose
+	err := r.DB.QueryRow(`
+		SELECT date, close
+		FROM finances
+		WHERE ticker = $1
+		ORDER BY date DESC
+		LIMIT 1
+	`, strings.ToUpper(ticker)).Scan(&lc.Date, &lc.Close)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &lc, nil
+}
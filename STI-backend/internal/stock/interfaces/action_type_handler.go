@@ -0,0 +1,26 @@
+package interfaces
+
+import (
+	"log"
+
+	"github.com/viteant/stockinsight/internal/db"
+	"github.com/viteant/stockinsight/internal/stock/infrastructure/repository"
+)
+
+// RunActionBackfill clasifica los ratings guardados antes de existir action_type.
+func RunActionBackfill(all bool) error {
+	dbConn := db.NewCockroachDB()
+	defer dbConn.Close()
+
+	repo, err := repository.NewCockroachStockRepository(dbConn)
+	if err != nil {
+		return err
+	}
+	updated, err := repo.BackfillActionTypes(all)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Backfill de action_type completado: %d ratings clasificados", updated)
+	return nil
+}
@@ -0,0 +1,17 @@
+package interfaces
+
+import (
+	"github.com/viteant/stockinsight/internal/db"
+	"github.com/viteant/stockinsight/internal/stock/infrastructure/repository"
+	"github.com/viteant/stockinsight/internal/stock/use_cases"
+)
+
+// RefreshBrokerScores recalcula la tabla broker_scores.
+func RefreshBrokerScores() error {
+	dbConn := db.NewCockroachDB()
+	defer dbConn.Close()
+
+	service := use_cases.NewBrokerScoreService(repository.NewCockroachBrokerScoreRepository(dbConn))
+	_, err := service.Refresh()
+	return err
+}
@@ -0,0 +1,94 @@
+package interfaces
+
+import (
+	"errors"
+	"os"
+
+	"github.com/gofiber/fiber/v2"
+	financedomain "github.com/viteant/stockinsight/internal/finance/domain"
+	"github.com/viteant/stockinsight/internal/problem"
+	"github.com/viteant/stockinsight/internal/stock/domain"
+)
+
+// GetConsensus godoc
+// @Summary Consenso por ticker
+// @Description Devuelve, por ticker, el último rating de cada brokerage, conteos buy/hold/sell, estadísticas de target_to, upside implícito contra el último cierre y un puntaje de consenso ponderado por el puntaje de cada broker. Ordenado por puntaje.
+// @Tags Consensus
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param limit query int false "Cantidad de tickers (default: 50, máximo: 500)"
+// @Param price_basis query string false "Base de precios de los puntajes de brokers (raw o adjusted, default: PRICE_BASIS o raw)"
+// @Param horizon query int false "Horizonte en días hábiles de los puntajes de brokers (7, 30 o 90; default: cierre más cercano al rating)"
+// @Success 200 {array} domain.TickerConsensus
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 429 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /api/consensus [get]
+func (h *StockHandler) GetConsensus(c *fiber.Ctx) error {
+	params, fieldErrs := consensusParams(c)
+	limit, err := parseLimit(c.Query("limit"), domain.DefaultConsensusLimit, domain.MaxConsensusLimit)
+	if err != nil {
+		fieldErrs = append(fieldErrs, problem.FieldError{Field: "limit", Value: c.Query("limit"), Reason: err.Error()})
+	}
+	if len(fieldErrs) > 0 {
+		return problem.Invalid(c, fieldErrs...)
+	}
+	params.Limit = limit
+
+	result, err := h.useCase.GetConsensus(params)
+	if err != nil {
+		return problem.Internal(c, "Error fetching consensus", err)
+	}
+	return c.JSON(result)
+}
+
+// GetTickerConsensus godoc
+// @Summary Consenso de un ticker
+// @Description Devuelve el último rating de cada brokerage sobre el ticker, conteos buy/hold/sell, estadísticas de target_to, upside implícito contra el último cierre y un puntaje de consenso ponderado por el puntaje de cada broker.
+// @Tags Consensus
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param ticker path string true "Ticker"
+// @Param price_basis query string false "Base de precios de los puntajes de brokers (raw o adjusted, default: PRICE_BASIS o raw)"
+// @Param horizon query int false "Horizonte en días hábiles de los puntajes de brokers (7, 30 o 90; default: cierre más cercano al rating)"
+// @Success 200 {object} domain.TickerConsensus
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 429 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /api/tickers/{ticker}/consensus [get]
+func (h *StockHandler) GetTickerConsensus(c *fiber.Ctx) error {
+	params, fieldErrs := consensusParams(c)
+	if len(fieldErrs) > 0 {
+		return problem.Invalid(c, fieldErrs...)
+	}
+	params.Ticker = c.Params("ticker")
+
+	result, err := h.useCase.GetTickerConsensus(params)
+	if errors.Is(err, domain.ErrTickerNotFound) {
+		return problem.Write(c, fiber.StatusNotFound, "Ticker not found", "")
+	}
+	if err != nil {
+		return problem.Internal(c, "Error fetching consensus", err)
+	}
+	return c.JSON(result)
+}
+
+func consensusParams(c *fiber.Ctx) (domain.ConsensusParams, []problem.FieldError) {
+	var errs []problem.FieldError
+	basis, err := financedomain.ParsePriceBasis(c.Query("price_basis", os.Getenv("PRICE_BASIS")))
+	if err != nil {
+		errs = append(errs, problem.FieldError{Field: "price_basis", Value: c.Query("price_basis"), Reason: err.Error()})
+	}
+	horizon, err := parseHorizon(c.Query("horizon"))
+	if err != nil {
+		errs = append(errs, problem.FieldError{Field: "horizon", Value: c.Query("horizon"), Reason: err.Error()})
+	}
+	return domain.ConsensusParams{PriceBasis: basis, HorizonDays: horizon}, errs
+}
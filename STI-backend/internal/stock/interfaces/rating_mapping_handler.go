@@ -0,0 +1,56 @@
+package interfaces
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/viteant/stockinsight/internal/db"
+	"github.com/viteant/stockinsight/internal/stock/infrastructure/repository"
+)
+
+// RunRenormalize aplica el mapeo de ratings actual a los ratings guardados.
+func RunRenormalize(all bool) error {
+	dbConn := db.NewCockroachDB()
+	defer dbConn.Close()
+
+	repo, err := repository.NewCockroachStockRepository(dbConn)
+	if err != nil {
+		return err
+	}
+	updated, err := repo.Renormalize(all)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Renormalización con el mapeo v%d completada: %d ratings actualizados", repo.Mapping.Version, updated)
+	return nil
+}
+
+// PrintUnmappedRatings muestra los ratings del feed que no están en el mapeo.
+func PrintUnmappedRatings() error {
+	dbConn := db.NewCockroachDB()
+	defer dbConn.Close()
+
+	repo, err := repository.NewCockroachStockRepository(dbConn)
+	if err != nil {
+		return err
+	}
+	ratings, err := repo.ListUnmappedRatings()
+	if err != nil {
+		return err
+	}
+	if len(ratings) == 0 {
+		log.Printf("Todos los ratings están en el mapeo v%d", repo.Mapping.Version)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RATING\tAPARICIONES\tÚLTIMO BROKER\tMAPEO\tÚLTIMA VEZ")
+	for _, u := range ratings {
+		fmt.Fprintf(w, "%s\t%d\t%s\tv%d\t%s\n",
+			u.RawRating, u.Occurrences, u.LastBrokerage, u.MappingVersion, u.LastSeenAt.Format("2006-01-02"))
+	}
+	return w.Flush()
+}
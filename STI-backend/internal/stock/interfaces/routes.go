@@ -8,11 +8,18 @@ import (
 	"github.com/viteant/stockinsight/internal/stock/use_cases"
 )
 
-func RegisterStockRoutes(app fiber.Router, db *sql.DB) {
-	stockRepo := repository.NewCockroachStockRepository(db)
+func RegisterStockRoutes(app fiber.Router, db *sql.DB) error {
+	stockRepo, err := repository.NewCockroachStockRepository(db)
+	if err != nil {
+		return err
+	}
 	stockService := &use_cases.StockService{Repo: stockRepo}
 	stockHandler := NewStockHandler(stockService)
 
 	app.Get("/stocks", stockHandler.GetStocks)
 	app.Get("/recommendations", stockHandler.GetRecommendations)
+	app.Get("/consensus", stockHandler.GetConsensus)
+	app.Get("/tickers/:ticker", stockHandler.GetTicker)
+	app.Get("/tickers/:ticker/consensus", stockHandler.GetTickerConsensus)
+	return nil
 }
@@ -1,10 +1,20 @@
 package interfaces
 
 import (
+	"errors"
+	"fmt"
 	"math"
+	"os"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	brokerdomain "github.com/viteant/stockinsight/internal/broker/domain"
+	financedomain "github.com/viteant/stockinsight/internal/finance/domain"
+	"github.com/viteant/stockinsight/internal/problem"
+	"github.com/viteant/stockinsight/internal/stock/domain"
 	"github.com/viteant/stockinsight/internal/stock/use_cases"
 )
 
@@ -20,79 +30,403 @@ func NewStockHandler(useCase *use_cases.StockService) *StockHandler {
 
 // GetRecommendations godoc
 // @Summary Recomendaciones de acciones
-// @Description Devuelve una lista con 10 acciones recomendadas para comprar, mantener y vender, basadas en la puntuación de los brokers.
+// @Description Devuelve hasta limit acciones recomendadas (10 por defecto) para comprar, mantener y vender, basadas en la puntuación de los brokers (precisión Beta-binomial de broker_scores, informada en score_method).
 // @Tags Recommendations
 // @Accept json
 // @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param price_basis query string false "Base de precios para evaluar a los brokers (raw o adjusted, default: PRICE_BASIS o raw)"
+// @Param horizon query int false "Horizonte en días hábiles para ordenar a los brokers (7, 30 o 90; default: cierre más cercano al rating)"
+// @Param track_half_life query number false "Vida media en días del historial del broker (0 desactiva el decaimiento, default: 365)"
+// @Param rating_half_life query number false "Vida media en días de la antigüedad del rating (0 desactiva el decaimiento, default: 90)"
+// @Param limit query int false "Cantidad por tipo de rating (default: 10, máximo: 100)"
+// @Param min_predictions query int false "Mínimo de predicciones evaluadas del broker"
+// @Param since query string false "Solo ratings desde esta fecha (YYYY-MM-DD)"
+// @Param brokerage query string false "Filtra por brokerage (ILIKE)"
+// @Param distinct_ticker query bool false "Un solo rating por ticker en cada tipo, el de mayor puntaje"
+// @Param action_type query string false "Filtra por tipo de acción (upgrade, downgrade, initiation, reiteration, target_raised, target_lowered, target_set, other)"
 // @Success 200 {array} domain.StockRecommendation
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 429 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
 // @Router /api/recommendations [get]
 func (h *StockHandler) GetRecommendations(c *fiber.Ctx) error {
-	recs, err := h.useCase.GetRecommendations()
+	basis, err := financedomain.ParsePriceBasis(c.Query("price_basis", os.Getenv("PRICE_BASIS")))
+	if err != nil {
+		return invalidParam(c, "price_basis", err.Error())
+	}
+
+	horizon, err := parseHorizon(c.Query("horizon"))
+	if err != nil {
+		return invalidParam(c, "horizon", err.Error())
+	}
+
+	trackHalfLife, err := parseHalfLife(c.Query("track_half_life"), domain.DefaultTrackHalfLifeDays)
+	if err != nil {
+		return invalidParam(c, "track_half_life", err.Error())
+	}
+	ratingHalfLife, err := parseHalfLife(c.Query("rating_half_life"), domain.DefaultRatingHalfLifeDays)
+	if err != nil {
+		return invalidParam(c, "rating_half_life", err.Error())
+	}
+
+	limit, err := parseNonNegativeInt(c.Query("limit"))
+	if err != nil {
+		return invalidParam(c, "limit", err.Error())
+	}
+	minPredictions, err := parseNonNegativeInt(c.Query("min_predictions"))
+	if err != nil {
+		return invalidParam(c, "min_predictions", err.Error())
+	}
+
+	var since time.Time
+	if v := c.Query("since"); v != "" {
+		since, err = time.Parse("2006-01-02", v)
+		if err != nil {
+			return invalidParam(c, "since", "se esperaba una fecha YYYY-MM-DD")
+		}
+	}
+
+	distinct, err := strconv.ParseBool(c.Query("distinct_ticker", "false"))
+	if err != nil {
+		return invalidParam(c, "distinct_ticker", "se esperaba true o false")
+	}
+
+	recs, err := h.useCase.GetRecommendations(domain.RecommendationParams{
+		PriceBasis:         basis,
+		HorizonDays:        horizon,
+		TrackHalfLifeDays:  trackHalfLife,
+		RatingHalfLifeDays: ratingHalfLife,
+		Limit:              limit,
+		MinPredictions:     minPredictions,
+		Since:              since,
+		Brokerage:          c.Query("brokerage"),
+		ActionType:         c.Query("action_type"),
+		DistinctTicker:     distinct,
+	})
+	if errors.Is(err, domain.ErrInvalidRecommendationParams) {
+		return problem.Write(c, fiber.StatusBadRequest, "Invalid recommendation parameters", err.Error())
+	}
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Error fetching recommendations",
-		})
+		return problem.Write(c, fiber.StatusInternalServerError, "Error fetching recommendations", "")
 	}
 	return c.JSON(recs)
 }
 
+// invalidParam responde 400 con el error de un parámetro de la query.
+func invalidParam(c *fiber.Ctx, field, reason string) error {
+	return problem.Invalid(c, problem.FieldError{Field: field, Value: c.Query(field), Reason: reason})
+}
+
+// parseHorizon acepta los horizontes de broker_scores; vacío es 0, el cierre
+// más cercano al rating.
+func parseHorizon(raw string) (int, error) {
+	if raw == "" {
+		return 0, nil
+	}
+	horizon, err := strconv.Atoi(raw)
+	if err != nil || (horizon != 0 && !slices.Contains(brokerdomain.Horizons, horizon)) {
+		return 0, fmt.Errorf("horizon debe ser 7, 30 o 90, se recibió %q", raw)
+	}
+	return horizon, nil
+}
+
+func parseNonNegativeInt(raw string) (int, error) {
+	if raw == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("se esperaba un entero >= 0, se recibió %q", raw)
+	}
+	return n, nil
+}
+
+// parseLimit acepta un entero entre 1 y max; vacío es fallback.
+func parseLimit(raw string, fallback, max int) (int, error) {
+	if raw == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 || n > max {
+		return 0, fmt.Errorf("debe estar entre 1 y %d, se recibió %q", max, raw)
+	}
+	return n, nil
+}
+
+// parseHalfLife acepta una cantidad de días mayor o igual a cero.
+func parseHalfLife(raw string, fallback float64) (float64, error) {
+	if raw == "" {
+		return fallback, nil
+	}
+	days, err := strconv.ParseFloat(raw, 64)
+	if err != nil || days < 0 || math.IsInf(days, 0) || math.IsNaN(days) {
+		return 0, fmt.Errorf("se esperaba una cantidad de días >= 0, se recibió %q", raw)
+	}
+	return days, nil
+}
+
+// GetTicker godoc
+// @Summary Detalle de un ticker
+// @Description Devuelve la empresa, el historial de ratings ordenado por fecha, el último cierre y el consenso actual de los brokers.
+// @Tags Tickers
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param ticker path string true "Ticker"
+// @Success 200 {object} domain.TickerDetail
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 429 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /api/tickers/{ticker} [get]
+func (h *StockHandler) GetTicker(c *fiber.Ctx) error {
+	detail, err := h.useCase.GetTickerDetail(c.Params("ticker"))
+	if errors.Is(err, domain.ErrTickerNotFound) {
+		return problem.Write(c, fiber.StatusNotFound, "Ticker not found", "")
+	}
+	if err != nil {
+		return problem.Internal(c, "Error fetching ticker", err)
+	}
+	return c.JSON(detail)
+}
+
 // GetStocks godoc
 // @Summary Lista de acciones
-// @Description Devuelve acciones con paginación y filtros
+// @Description Devuelve acciones con paginación y filtros. Se puede paginar por número de página o con los cursores next_cursor y prev_cursor de la respuesta, que no se saltean ni repiten filas aunque se inserten otras. Los parámetros inválidos responden 400 con un problema RFC 7807 que detalla cada campo en errors.
 // @Tags Stocks
 // @Accept json
 // @Produce json
-// @Param page query int false "Número de página"
-// @Param limit query int false "Cantidad por página"
-// @Param orderBy query string false "Columna para ordenar (default: ID)"
-// @Param orderDir query string false "Dirección de orden (asc o desc, default": asc)"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param page query int false "Número de página (default: 1, no se combina con cursor)"
+// @Param cursor query string false "next_cursor o prev_cursor de una respuesta anterior; los filtros deben repetirse o se responde 400"
+// @Param include_total query bool false "Incluir total y total_pages (default: true)"
+// @Param limit query int false "Cantidad por página (default: 20, máximo: 100)"
+// @Param orderBy query string false "Columna para ordenar: created_at, target_to, target_from, rating_to, rating_from, action, ticker, company, brokerage o id (default: created_at)"
+// @Param orderDir query string false "Dirección de orden (asc o desc, default: asc)"
+// @Param id query string false "Filtra por ID (UUID)"
 // @Param ticker query string false "Filtra por ticker (ILIKE)"
 // @Param company query string false "Filtra por nombre de empresa (ILIKE)"
 // @Param brokerage query string false "Filtra por brokerage (ILIKE)"
 // @Param target_from_min query number false "Filtra por target_from mínimo"
 // @Param target_from_max query number false "Filtra por target_from máximo"
-// @Param date_from query string false "Fecha mínima (YYYY-MM-DD)"
-// @Param date_to query string false "Fecha máxima (YYYY-MM-DD)"
+// @Param target_to_min query number false "Filtra por target_to mínimo"
+// @Param target_to_max query number false "Filtra por target_to máximo"
+// @Param date_from query string false "Fecha mínima (YYYY-MM-DD o RFC 3339)"
+// @Param date_to query string false "Fecha máxima, inclusiva (YYYY-MM-DD incluye todo el día, o RFC 3339)"
+// @Param action_type query string false "Filtra por tipo de acción (upgrade, downgrade, initiation, reiteration, target_raised, target_lowered, target_set, other)"
 // @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 429 {object} problem.Problem
 // @Router /api/stocks [get]
 func (h *StockHandler) GetStocks(c *fiber.Ctx) error {
-	// Defaults
-	page, _ := strconv.Atoi(c.Query("page", "1"))
-	limit, _ := strconv.Atoi(c.Query("limit", "20"))
-	orderBy := c.Query("orderBy", "ID")
-	orderDir := c.Query("orderDir", "asc")
+	query, fieldErrs := parseStockQuery(c)
+	var verr *domain.ValidationError
+
+	// Con errores de formato se valida igual el resto de la consulta para
+	// informar todos los campos inválidos en una sola respuesta.
+	if len(fieldErrs) > 0 {
+		if _, err := query.Normalize(); errors.As(err, &verr) {
+			fieldErrs = append(fieldErrs, problemFields(verr)...)
+		}
+		return problem.Invalid(c, fieldErrs...)
+	}
 
-	// Filtros soportados
-	filters := map[string]string{
-		"id":              c.Query("id"),
-		"ticker":          c.Query("ticker"),
-		"company":         c.Query("company"),
-		"brokerage":       c.Query("brokerage"),
-		"target_from_min": c.Query("target_from_min"),
-		"target_from_max": c.Query("target_from_max"),
-		"target_to_min":   c.Query("target_to_min"),
-		"target_to_max":   c.Query("target_to_max"),
-		"date_from":       c.Query("date_from"),
-		"date_to":         c.Query("date_to"),
-	}
-
-	// Llama al caso de uso
-	stocks, total, err := h.useCase.GetAllStocks(page, limit, filters, orderBy, orderDir)
+	list, err := h.useCase.GetAllStocks(query)
+	if errors.As(err, &verr) {
+		return problem.Invalid(c, problemFields(verr)...)
+	}
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error":   "Error fetching stocks",
-			"message": err.Error(),
-		})
+		return problem.Internal(c, "Error fetching stocks", err)
+	}
+
+	normalized := list.Query
+	resp := fiber.Map{
+		"limit":       normalized.Limit,
+		"next_cursor": nullable(list.NextCursor),
+		"prev_cursor": nullable(list.PrevCursor),
+		"items":       list.Items,
 	}
+	// page solo tiene sentido sin cursor.
+	if normalized.Cursor == nil {
+		resp["page"] = normalized.Page
+	}
+	if list.Total != nil {
+		resp["total"] = *list.Total
+		resp["total_pages"] = (*list.Total + normalized.Limit - 1) / normalized.Limit
+	}
+	return c.JSON(resp)
+}
 
-	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+func nullable(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// parseStockQuery convierte los parámetros de GET /api/stocks a sus tipos. Los
+// rangos y valores permitidos se validan en StockQuery.Normalize.
+func parseStockQuery(c *fiber.Ctx) (domain.StockQuery, []problem.FieldError) {
+	var errs []problem.FieldError
+	invalid := func(field, reason string) {
+		errs = append(errs, problem.FieldError{Field: field, Value: c.Query(field), Reason: reason})
+	}
+
+	query := domain.StockQuery{
+		OrderBy:    c.Query("orderBy"),
+		ID:         c.Query("id"),
+		Ticker:     c.Query("ticker"),
+		Company:    c.Query("company"),
+		Brokerage:  c.Query("brokerage"),
+		ActionType: c.Query("action_type"),
+	}
+
+	// Con cursor, orderBy y orderDir se toman del cursor si no se indican.
+	orderDir := c.Query("orderDir", "asc")
+	if raw := c.Query("cursor"); raw != "" {
+		cursor, err := domain.DecodeStockCursor(raw)
+		if err != nil || !isUUID(cursor.ID) {
+			invalid("cursor", "cursor inválido; usa next_cursor o prev_cursor de una respuesta anterior")
+		} else {
+			query.Cursor = &cursor
+			if query.OrderBy == "" {
+				query.OrderBy = cursor.OrderBy
+			}
+			if c.Query("orderDir") == "" && cursor.Desc {
+				orderDir = "desc"
+			}
+		}
+		if c.Query("page") != "" {
+			invalid("page", "no se puede combinar con cursor")
+		}
+	}
+
+	query.IncludeTotal = true
+	if raw := c.Query("include_total"); raw != "" {
+		include, err := strconv.ParseBool(raw)
+		if err != nil {
+			invalid("include_total", "se esperaba true o false")
+		} else {
+			query.IncludeTotal = include
+		}
+	}
 
-	return c.JSON(fiber.Map{
-		"page":        page,
-		"limit":       limit,
-		"total":       total,
-		"total_pages": totalPages,
-		"items":       stocks,
+	// En StockQuery 0 significa el valor por defecto, así que un 0 explícito
+	// se rechaza aquí.
+	for field, dst := range map[string]*int{"page": &query.Page, "limit": &query.Limit} {
+		if field == "page" && query.Cursor != nil {
+			continue
+		}
+		if raw := c.Query(field); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil {
+				invalid(field, "se esperaba un número entero")
+				continue
+			}
+			if n < 1 {
+				invalid(field, "debe ser mayor o igual a 1")
+				continue
+			}
+			*dst = n
+		}
+	}
+
+	switch strings.ToLower(orderDir) {
+	case "asc":
+	case "desc":
+		query.OrderDesc = true
+	default:
+		invalid("orderDir", "debe ser asc o desc")
+	}
+
+	if query.ID != "" && !isUUID(query.ID) {
+		invalid("id", "se esperaba un UUID")
+	}
+
+	for field, dst := range map[string]**float64{
+		"target_from_min": &query.TargetFromMin,
+		"target_from_max": &query.TargetFromMax,
+		"target_to_min":   &query.TargetToMin,
+		"target_to_max":   &query.TargetToMax,
+	} {
+		if raw := c.Query(field); raw != "" {
+			v, err := strconv.ParseFloat(raw, 64)
+			if err != nil || math.IsInf(v, 0) || math.IsNaN(v) {
+				invalid(field, "se esperaba un número")
+				continue
+			}
+			*dst = &v
+		}
+	}
+
+	if raw := c.Query("date_from"); raw != "" {
+		t, _, err := parseISODate(raw)
+		if err != nil {
+			invalid("date_from", err.Error())
+		}
+		query.CreatedFrom = t
+	}
+	if raw := c.Query("date_to"); raw != "" {
+		t, dateOnly, err := parseISODate(raw)
+		if err != nil {
+			invalid("date_to", err.Error())
+		} else if dateOnly {
+			// Una fecha sin hora incluye todo el día.
+			query.CreatedBefore = t.AddDate(0, 0, 1)
+		} else {
+			// created_at tiene precisión de microsegundos.
+			query.CreatedBefore = t.Add(time.Microsecond)
+		}
+	}
+
+	// El orden de los errores no depende del recorrido de los mapas.
+	slices.SortStableFunc(errs, func(a, b problem.FieldError) int {
+		return strings.Compare(a.Field, b.Field)
 	})
+	return query, errs
+}
+
+// parseISODate acepta una fecha YYYY-MM-DD o un instante RFC 3339. dateOnly
+// indica si vino sin hora.
+func parseISODate(raw string) (t time.Time, dateOnly bool, err error) {
+	if t, err = time.Parse(time.DateOnly, raw); err == nil {
+		return t, true, nil
+	}
+	if t, err = time.Parse(time.RFC3339Nano, raw); err == nil {
+		return t, false, nil
+	}
+	return time.Time{}, false, errors.New("se esperaba una fecha YYYY-MM-DD o RFC 3339")
+}
+
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i, r := range s {
+		switch i {
+		case 8, 13, 18, 23:
+			if r != '-' {
+				return false
+			}
+		default:
+			if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func problemFields(verr *domain.ValidationError) []problem.FieldError {
+	fields := make([]problem.FieldError, len(verr.Fields))
+	for i, f := range verr.Fields {
+		fields[i] = problem.FieldError{Field: f.Field, Value: f.Value, Reason: f.Reason}
+	}
+	return fields
 }
@@ -0,0 +1,134 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/viteant/stockinsight/internal/problem"
+	"github.com/viteant/stockinsight/internal/stock/domain"
+	"github.com/viteant/stockinsight/internal/stock/use_cases"
+)
+
+var tickerCursor = domain.StockCursor{
+	OrderBy: "ticker",
+	Desc:    true,
+	Value:   "AAPL",
+	ID:      "b1d1c3a4-0000-4000-8000-000000000001",
+	Query:   domain.StockQuery{OrderBy: "ticker", OrderDesc: true}.CursorHash(),
+}.Encode()
+
+func TestGetStocks_InvalidParams(t *testing.T) {
+	app := fiber.New()
+	app.Get("/api/stocks", NewStockHandler(&use_cases.StockService{}).GetStocks)
+
+	cases := []struct {
+		query  string
+		fields []string
+	}{
+		{"limit=0", []string{"limit"}},
+		{"limit=500", []string{"limit"}},
+		{"page=abc&limit=x", []string{"limit", "page"}},
+		{"orderBy=price&orderDir=up", []string{"orderDir", "orderBy"}},
+		{"id=123", []string{"id"}},
+		{"target_from_min=abc&target_to_min=10&target_to_max=5", []string{"target_from_min", "target_to_max"}},
+		{"date_from=10/03/2025", []string{"date_from"}},
+		{"date_from=2025-03-10&date_to=2025-03-01", []string{"date_to"}},
+		{"action_type=sell", []string{"action_type"}},
+		{"cursor=xyz&include_total=quizas", []string{"cursor", "include_total"}},
+		{"cursor=" + tickerCursor + "&page=2", []string{"page"}},
+		{"cursor=" + tickerCursor + "&orderBy=company", []string{"cursor"}},
+		{"cursor=" + tickerCursor + "&orderDir=asc", []string{"cursor"}},
+		{"cursor=" + tickerCursor + "&ticker=MSFT", []string{"cursor"}},
+		{"cursor=" + tickerCursor + "&target_to_min=10", []string{"cursor"}},
+	}
+	for _, c := range cases {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/stocks?"+c.query, nil))
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode, c.query)
+		assert.Equal(t, problem.ContentType, resp.Header.Get(fiber.HeaderContentType))
+
+		var body problem.Problem
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, problem.TypeValidation, body.Type)
+
+		fields := make([]string, len(body.Errors))
+		for i, e := range body.Errors {
+			fields[i] = e.Field
+		}
+		assert.Equal(t, c.fields, fields, c.query)
+	}
+}
+
+func TestGetConsensus_InvalidParams(t *testing.T) {
+	app := fiber.New()
+	app.Get("/api/consensus", NewStockHandler(&use_cases.StockService{}).GetConsensus)
+
+	cases := []struct {
+		query  string
+		fields []string
+	}{
+		{"limit=0", []string{"limit"}},
+		{"limit=501", []string{"limit"}},
+		{"limit=muchos", []string{"limit"}},
+		{"horizon=14&limit=-1", []string{"horizon", "limit"}},
+	}
+	for _, c := range cases {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/consensus?"+c.query, nil))
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode, c.query)
+
+		var body problem.Problem
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		fields := make([]string, len(body.Errors))
+		for i, e := range body.Errors {
+			fields[i] = e.Field
+		}
+		assert.Equal(t, c.fields, fields, c.query)
+	}
+}
+
+func TestParseStockQuery_Dates(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		q, errs := parseStockQuery(c)
+		assert.Empty(t, errs)
+		return c.JSON(fiber.Map{"from": q.CreatedFrom, "before": q.CreatedBefore})
+	})
+
+	get := func(query string) map[string]string {
+		resp, err := app.Test(httptest.NewRequest("GET", "/?"+query, nil))
+		assert.NoError(t, err)
+		var got map[string]string
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+		return got
+	}
+
+	// Una fecha sin hora en date_to incluye todo el día.
+	got := get("date_from=2025-03-10&date_to=2025-03-10")
+	assert.Equal(t, "2025-03-10T00:00:00Z", got["from"])
+	assert.Equal(t, "2025-03-11T00:00:00Z", got["before"])
+
+	// Con un instante, date_to es inclusivo a nivel de microsegundo.
+	got = get("date_to=2025-03-10T15:04:05.123456Z")
+	assert.Equal(t, "2025-03-10T15:04:05.123457Z", got["before"])
+}
+
+func TestParseStockQuery_CursorSetsOrder(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		q, errs := parseStockQuery(c)
+		assert.Empty(t, errs)
+		assert.Equal(t, "ticker", q.OrderBy)
+		assert.True(t, q.OrderDesc)
+		assert.Equal(t, "AAPL", q.Cursor.Value)
+		assert.False(t, q.IncludeTotal)
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/?include_total=false&cursor="+tickerCursor, nil))
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+}
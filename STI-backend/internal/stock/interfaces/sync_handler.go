@@ -1,21 +1,60 @@
 package interfaces
 
 import (
+	"context"
+
+	"github.com/viteant/stockinsight/internal/cassette"
+	"log"
+
 	"github.com/viteant/stockinsight/internal/db"
+	"github.com/viteant/stockinsight/internal/stock/domain"
 	"github.com/viteant/stockinsight/internal/stock/infrastructure/api"
 	"github.com/viteant/stockinsight/internal/stock/infrastructure/repository"
 	"github.com/viteant/stockinsight/internal/stock/use_cases"
+	syncrundomain "github.com/viteant/stockinsight/internal/syncrun/domain"
+	syncrunrepository "github.com/viteant/stockinsight/internal/syncrun/infrastructure/repository"
+	syncrunusecases "github.com/viteant/stockinsight/internal/syncrun/use_cases"
 )
 
-func RunStockSync() {
+// RunStockSync sincroniza los ratings y registra la corrida en sync_runs.
+// tape indica si los pedidos se graban o reproducen. onRun recibe el ID de la
+// corrida apenas se registra; onRun y onPage pueden ser nil.
+func RunStockSync(ctx context.Context, tape cassette.Config, full bool, onRun func(runID string), onPage func(domain.SyncStats)) error {
 	dbConn := db.NewCockroachDB()
 	defer dbConn.Close()
 
-	fetcher := api.NewExternalAPIClient()
-	repo := repository.NewCockroachStockRepository(dbConn)
+	fetcher := api.NewExternalAPIClient(tape)
+	repo, err := repository.NewCockroachStockRepository(dbConn)
+	if err != nil {
+		return err
+	}
+	state := repository.NewCockroachSyncStateRepository(dbConn)
+	runs := syncrunrepository.NewCockroachRunRepository(dbConn)
+
+	sync := use_cases.NewSyncService(fetcher, repo, state)
+	sync.OnPage = onPage
+
+	run, stopHeartbeat, err := syncrunusecases.NewTracker(runs).Start(syncrundomain.KindStocks, sync.Source)
+	if err != nil {
+		return err
+	}
+	if onRun != nil {
+		onRun(run.ID)
+	}
+
+	stats, syncErr := sync.Sync(ctx, full)
+	stopHeartbeat()
 
-	sync := use_cases.NewSyncService(fetcher, repo)
-	if err := sync.Sync(); err != nil {
-		panic(err)
+	run.PagesFetched = stats.PagesFetched
+	run.RowsInserted = stats.RowsInserted
+	run.RowsUpdated = stats.RowsUpdated
+	run.RowsFailed = stats.RowsFailed
+	run.RowsQuarantined = stats.RowsQuarantined
+	run.Complete(syncErr)
+
+	if err := runs.Finish(run); err != nil {
+		log.Printf("Error registrando la corrida %s: %v", run.ID, err)
 	}
+
+	return syncErr
 }
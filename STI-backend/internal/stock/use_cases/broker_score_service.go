@@ -0,0 +1,48 @@
+package use_cases
+
+import (
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/viteant/stockinsight/internal/stock/domain"
+)
+
+const defaultPriorStrength = 10
+
+type BrokerScoreRepository interface {
+	FetchBrokerRecords() ([]domain.BrokerRecord, error)
+	ReplaceBrokerScores(scores []domain.BrokerScore) error
+}
+
+type BrokerScoreService struct {
+	Repo          BrokerScoreRepository
+	PriorStrength float64
+}
+
+// NewBrokerScoreService toma el peso del prior, en predicciones, de
+// BROKER_SCORE_PRIOR_STRENGTH.
+func NewBrokerScoreService(repo BrokerScoreRepository) *BrokerScoreService {
+	strength := float64(defaultPriorStrength)
+	if v, err := strconv.ParseFloat(os.Getenv("BROKER_SCORE_PRIOR_STRENGTH"), 64); err == nil && v > 0 {
+		strength = v
+	}
+	return &BrokerScoreService{Repo: repo, PriorStrength: strength}
+}
+
+// Refresh recalcula y guarda los puntajes de todos los brokers.
+func (s *BrokerScoreService) Refresh() ([]domain.BrokerScore, error) {
+	records, err := s.Repo.FetchBrokerRecords()
+	if err != nil {
+		return nil, err
+	}
+
+	scores := domain.ScoreBrokers(records, s.PriorStrength)
+	if err := s.Repo.ReplaceBrokerScores(scores); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Puntajes de brokers actualizados: %d registros (%s, prior de %.0f predicciones)",
+		len(scores), domain.ScoreMethodBetaBinomial, s.PriorStrength)
+	return scores, nil
+}
@@ -5,18 +5,140 @@ import (
 )
 
 type StockRepository interface {
-	FetchAllStocks(page, limit int, filters map[string]string, orderBy, orderDir string) ([]domain.Stock, int, error)
-	FetchRecommendations() ([]domain.StockRecommendation, error)
+	FetchAllStocks(query domain.StockQuery) (domain.StockList, error)
+	CountStocks(query domain.StockQuery) (int, error)
+	FetchRecommendations(params domain.RecommendationParams) ([]domain.StockRecommendation, error)
+	FetchTickerRatings(ticker string) ([]domain.Stock, error)
+	FetchLatestClose(ticker string) (*domain.LatestClose, error)
+	RankConsensusTickers(params domain.ConsensusParams) ([]string, error)
+	FetchLatestRatings(tickers []string) ([]domain.Stock, error)
+	FetchLatestCloses(tickers []string) (map[string]domain.LatestClose, error)
+	FetchBrokerWeights(params domain.ConsensusParams) (map[string]float64, error)
 }
 
 type StockService struct {
 	Repo StockRepository
 }
 
-func (s *StockService) GetRecommendations() ([]domain.StockRecommendation, error) {
-	return s.Repo.FetchRecommendations()
+func (s *StockService) GetRecommendations(params domain.RecommendationParams) ([]domain.StockRecommendation, error) {
+	params, err := params.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	return s.Repo.FetchRecommendations(params)
 }
 
-func (s *StockService) GetAllStocks(page, limit int, filters map[string]string, orderBy, orderDir string) ([]domain.Stock, int, error) {
-	return s.Repo.FetchAllStocks(page, limit, filters, orderBy, orderDir)
+// GetAllStocks devuelve la página pedida y, si query.IncludeTotal, el total.
+// Si la consulta no es válida devuelve un *domain.ValidationError.
+func (s *StockService) GetAllStocks(query domain.StockQuery) (domain.StockList, error) {
+	query, err := query.Normalize()
+	if err != nil {
+		return domain.StockList{}, err
+	}
+
+	list, err := s.Repo.FetchAllStocks(query)
+	list.Query = query
+	if err != nil || !query.IncludeTotal {
+		return list, err
+	}
+
+	total, err := s.Repo.CountStocks(query)
+	if err != nil {
+		return list, err
+	}
+	list.Total = &total
+	return list, nil
+}
+
+func (s *StockService) GetTickerDetail(ticker string) (domain.TickerDetail, error) {
+	ratings, err := s.Repo.FetchTickerRatings(ticker)
+	if err != nil {
+		return domain.TickerDetail{}, err
+	}
+	if len(ratings) == 0 {
+		return domain.TickerDetail{}, domain.ErrTickerNotFound
+	}
+
+	latestClose, err := s.Repo.FetchLatestClose(ticker)
+	if err != nil {
+		return domain.TickerDetail{}, err
+	}
+
+	last := ratings[len(ratings)-1]
+	return domain.TickerDetail{
+		Ticker:      last.Ticker,
+		Company:     last.Company,
+		Ratings:     ratings,
+		LatestClose: latestClose,
+		Consensus:   domain.BuildConsensus(ratings),
+	}, nil
+}
+
+func (s *StockService) GetTickerConsensus(params domain.ConsensusParams) (domain.TickerConsensus, error) {
+	ratings, err := s.Repo.FetchTickerRatings(params.Ticker)
+	if err != nil {
+		return domain.TickerConsensus{}, err
+	}
+	if len(ratings) == 0 {
+		return domain.TickerConsensus{}, domain.ErrTickerNotFound
+	}
+
+	latestClose, err := s.Repo.FetchLatestClose(params.Ticker)
+	if err != nil {
+		return domain.TickerConsensus{}, err
+	}
+
+	weights, err := s.Repo.FetchBrokerWeights(params)
+	if err != nil {
+		return domain.TickerConsensus{}, err
+	}
+
+	return domain.BuildTickerConsensus(ratings, latestClose, weights), nil
+}
+
+// GetConsensus arma el consenso de los params.Limit tickers con mayor puntaje
+// y luego con más brokers. El orden y el límite se resuelven en la base; solo
+// se cargan los ratings y cierres de esos tickers.
+func (s *StockService) GetConsensus(params domain.ConsensusParams) ([]domain.TickerConsensus, error) {
+	if params.Limit <= 0 {
+		params.Limit = domain.DefaultConsensusLimit
+	}
+
+	tickers, err := s.Repo.RankConsensusTickers(params)
+	if err != nil {
+		return nil, err
+	}
+	if len(tickers) == 0 {
+		return []domain.TickerConsensus{}, nil
+	}
+
+	ratings, err := s.Repo.FetchLatestRatings(tickers)
+	if err != nil {
+		return nil, err
+	}
+
+	closes, err := s.Repo.FetchLatestCloses(tickers)
+	if err != nil {
+		return nil, err
+	}
+
+	weights, err := s.Repo.FetchBrokerWeights(params)
+	if err != nil {
+		return nil, err
+	}
+
+	byTicker := make(map[string][]domain.Stock)
+	for _, r := range ratings {
+		byTicker[r.Ticker] = append(byTicker[r.Ticker], r)
+	}
+
+	result := make([]domain.TickerConsensus, 0, len(tickers))
+	for _, ticker := range tickers {
+		var latestClose *domain.LatestClose
+		if lc, ok := closes[ticker]; ok {
+			latestClose = &lc
+		}
+		result = append(result, domain.BuildTickerConsensus(byTicker[ticker], latestClose, weights))
+	}
+	return result, nil
 }
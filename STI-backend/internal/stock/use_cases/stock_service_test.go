@@ -0,0 +1,199 @@
+package use_cases
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	financedomain "github.com/viteant/stockinsight/internal/finance/domain"
+	"github.com/viteant/stockinsight/internal/stock/domain"
+)
+
+// fakeStockRepository guarda los parámetros recibidos y devuelve datos fijos.
+type fakeStockRepository struct {
+	stockQuery    *domain.StockQuery
+	counted       bool
+	recParams     *domain.RecommendationParams
+	recs          []domain.StockRecommendation
+	rankParams    *domain.ConsensusParams
+	ranked        []string
+	loaded        []string
+	latestRatings []domain.Stock
+	closes        map[string]domain.LatestClose
+	weights       map[string]float64
+}
+
+func (f *fakeStockRepository) FetchAllStocks(query domain.StockQuery) (domain.StockList, error) {
+	f.stockQuery = &query
+	return domain.StockList{NextCursor: "siguiente"}, nil
+}
+
+func (f *fakeStockRepository) CountStocks(query domain.StockQuery) (int, error) {
+	f.counted = true
+	return 42, nil
+}
+
+func (f *fakeStockRepository) FetchRecommendations(params domain.RecommendationParams) ([]domain.StockRecommendation, error) {
+	f.recParams = &params
+	return f.recs, nil
+}
+
+func (f *fakeStockRepository) FetchTickerRatings(ticker string) ([]domain.Stock, error) {
+	return nil, nil
+}
+
+func (f *fakeStockRepository) FetchLatestClose(ticker string) (*domain.LatestClose, error) {
+	return nil, nil
+}
+
+func (f *fakeStockRepository) RankConsensusTickers(params domain.ConsensusParams) ([]string, error) {
+	f.rankParams = &params
+	return f.ranked, nil
+}
+
+func (f *fakeStockRepository) FetchLatestRatings(tickers []string) ([]domain.Stock, error) {
+	f.loaded = tickers
+	var ratings []domain.Stock
+	for _, r := range f.latestRatings {
+		if slices.Contains(tickers, r.Ticker) {
+			ratings = append(ratings, r)
+		}
+	}
+	return ratings, nil
+}
+
+func (f *fakeStockRepository) FetchLatestCloses(tickers []string) (map[string]domain.LatestClose, error) {
+	closes := make(map[string]domain.LatestClose)
+	for _, t := range tickers {
+		if lc, ok := f.closes[t]; ok {
+			closes[t] = lc
+		}
+	}
+	return closes, nil
+}
+
+func (f *fakeStockRepository) FetchBrokerWeights(params domain.ConsensusParams) (map[string]float64, error) {
+	return f.weights, nil
+}
+
+func TestGetRecommendations_AppliesDefaults(t *testing.T) {
+	repo := &fakeStockRepository{}
+	service := &StockService{Repo: repo}
+
+	_, err := service.GetRecommendations(domain.RecommendationParams{})
+	assert.NoError(t, err)
+	assert.Equal(t, domain.DefaultRecommendationLimit, repo.recParams.Limit)
+	assert.Equal(t, financedomain.PriceBasisRaw, repo.recParams.PriceBasis)
+	assert.False(t, repo.recParams.DistinctTicker)
+}
+
+func TestGetRecommendations_ThreadsParams(t *testing.T) {
+	repo := &fakeStockRepository{
+		recs: []domain.StockRecommendation{{Ticker: "AAA", NormalizeRatingTo: "buy"}},
+	}
+	service := &StockService{Repo: repo}
+
+	params := domain.RecommendationParams{
+		PriceBasis:     financedomain.PriceBasisAdjusted,
+		HorizonDays:    30,
+		Limit:          25,
+		MinPredictions: 5,
+		Since:          time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Brokerage:      "Goldman",
+		DistinctTicker: true,
+	}
+
+	recs, err := service.GetRecommendations(params)
+	assert.NoError(t, err)
+	assert.Equal(t, repo.recs, recs)
+	assert.Equal(t, params, *repo.recParams)
+}
+
+func TestGetRecommendations_RejectsInvalidParams(t *testing.T) {
+	cases := map[string]domain.RecommendationParams{
+		"limit too large":          {Limit: domain.MaxRecommendationLimit + 1},
+		"negative limit":           {Limit: -1},
+		"negative min_predictions": {MinPredictions: -3},
+		"negative half-life":       {TrackHalfLifeDays: -1},
+	}
+
+	for name, params := range cases {
+		t.Run(name, func(t *testing.T) {
+			repo := &fakeStockRepository{}
+			service := &StockService{Repo: repo}
+
+			_, err := service.GetRecommendations(params)
+			assert.ErrorIs(t, err, domain.ErrInvalidRecommendationParams)
+			assert.Nil(t, repo.recParams, "no debe consultar el repositorio")
+		})
+	}
+}
+
+func TestGetConsensus_FollowsTheRanking(t *testing.T) {
+	repo := &fakeStockRepository{
+		ranked: []string{"BBB", "AAA"},
+		latestRatings: []domain.Stock{
+			{Ticker: "AAA", Brokerage: "A", NormalizeRatingTo: "hold", TargetTo: 10},
+			{Ticker: "BBB", Brokerage: "A", NormalizeRatingTo: "buy", TargetTo: 20},
+			{Ticker: "CCC", Brokerage: "A", NormalizeRatingTo: "sell", TargetTo: 30},
+		},
+		closes: map[string]domain.LatestClose{"BBB": {Close: 16}, "CCC": {Close: 30}},
+	}
+	service := &StockService{Repo: repo}
+
+	result, err := service.GetConsensus(domain.ConsensusParams{Limit: 2, HorizonDays: 30})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, repo.rankParams.Limit)
+	assert.Equal(t, 30, repo.rankParams.HorizonDays)
+	// Solo se cargan los tickers del ranking, en su orden.
+	assert.Equal(t, []string{"BBB", "AAA"}, repo.loaded)
+	assert.Len(t, result, 2)
+	assert.Equal(t, "BBB", result[0].Ticker)
+	assert.Equal(t, 25.0, *result[0].ImpliedUpside)
+	assert.Equal(t, "AAA", result[1].Ticker)
+	assert.Nil(t, result[1].LatestClose)
+}
+
+func TestGetConsensus_DefaultsAndEmptyRanking(t *testing.T) {
+	repo := &fakeStockRepository{}
+	service := &StockService{Repo: repo}
+
+	result, err := service.GetConsensus(domain.ConsensusParams{})
+	assert.NoError(t, err)
+	assert.Equal(t, domain.DefaultConsensusLimit, repo.rankParams.Limit)
+	assert.NotNil(t, result)
+	assert.Empty(t, result)
+	assert.Nil(t, repo.loaded)
+}
+
+func TestGetAllStocks_NormalizesQuery(t *testing.T) {
+	repo := &fakeStockRepository{}
+	service := &StockService{Repo: repo}
+
+	list, err := service.GetAllStocks(domain.StockQuery{OrderBy: "TICKER"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, repo.stockQuery.Page)
+	assert.Equal(t, domain.DefaultStockLimit, repo.stockQuery.Limit)
+	assert.Equal(t, "ticker", repo.stockQuery.OrderBy)
+	assert.Equal(t, *repo.stockQuery, list.Query)
+	assert.Nil(t, list.Total)
+	assert.False(t, repo.counted)
+
+	repo.stockQuery = nil
+	_, err = service.GetAllStocks(domain.StockQuery{Limit: domain.MaxStockLimit + 1})
+	var verr *domain.ValidationError
+	assert.ErrorAs(t, err, &verr)
+	assert.Nil(t, repo.stockQuery)
+}
+
+func TestGetAllStocks_IncludeTotal(t *testing.T) {
+	repo := &fakeStockRepository{}
+	service := &StockService{Repo: repo}
+
+	list, err := service.GetAllStocks(domain.StockQuery{IncludeTotal: true})
+	assert.NoError(t, err)
+	assert.True(t, repo.counted)
+	assert.Equal(t, 42, *list.Total)
+	assert.Equal(t, "siguiente", list.NextCursor)
+}
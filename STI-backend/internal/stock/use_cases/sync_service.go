@@ -1,6 +1,9 @@
 package use_cases
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"os"
 	"time"
@@ -8,53 +11,200 @@ import (
 	"github.com/viteant/stockinsight/internal/stock/domain"
 )
 
+// DefaultSyncSource identifica la API externa de ratings en sync_state.
+const DefaultSyncSource = "ratings_api"
+
 type StockFetcher interface {
-	FetchPage(nextPage string) ([]domain.Stock, string, error)
+	FetchPage(ctx context.Context, nextPage string) (domain.StockPage, error)
 }
 
 type StockSaver interface {
-	Save(stock domain.Stock) error
+	SaveBatch(stocks []domain.Stock) (domain.SaveResult, error)
+	QuarantineStocks(source string, rejected []domain.RejectedStock) error
+}
+
+type SyncStateRepository interface {
+	GetSyncState(source string) (domain.SyncState, error)
+	SaveSyncState(state domain.SyncState) error
 }
 
 type SyncService struct {
 	Fetcher StockFetcher
 	Repo    StockSaver
+	State   SyncStateRepository
+	Source  string
+
+	// OnPage, si está definido, recibe las estadísticas acumuladas después de
+	// procesar cada página.
+	OnPage func(domain.SyncStats)
 }
 
-func NewSyncService(fetcher StockFetcher, repo StockSaver) *SyncService {
+// NewSyncService usa DefaultSyncSource. Los reintentos ante 429 y 5xx son
+// del cliente HTTP del fetcher; el servicio no vuelve a pedir la página.
+func NewSyncService(fetcher StockFetcher, repo StockSaver, state SyncStateRepository) *SyncService {
 	return &SyncService{
 		Fetcher: fetcher,
 		Repo:    repo,
+		State:   state,
+		Source:  DefaultSyncSource,
 	}
 }
 
-func (s *SyncService) Sync() error {
-	next := ""
+// Sync descarga las páginas de la API hasta llegar a registros más antiguos
+// que la marca de agua guardada (el feed entrega primero los más recientes).
+// Si la corrida anterior quedó a medias, se reanuda desde el cursor guardado.
+// Con full=true se ignoran cursor y marca de agua y se recorre todo el feed.
+// Los ratings que no pasan la validación o que no se pudieron guardar van a
+// la cuarentena. Si la API rechaza el token o deja de responder, la corrida
+// se corta y la siguiente se reanuda desde el cursor guardado. Lo mismo pasa
+// al cancelar ctx: se termina la página en curso y se devuelve ctx.Err().
+func (s *SyncService) Sync(ctx context.Context, full bool) (domain.SyncStats, error) {
+	var stats domain.SyncStats
+
+	state, err := s.State.GetSyncState(s.Source)
+	if err != nil {
+		return stats, err
+	}
+
+	watermark := state.LastReportedAt
+	next := state.NextPage
+	runMax := state.RunReportedAt
+
+	if full {
+		watermark = time.Time{}
+		next = ""
+		runMax = time.Time{}
+		log.Printf("Sincronización completa de %s solicitada", s.Source)
+	} else if next != "" {
+		log.Printf("Reanudando sincronización de %s desde next_page=%s", s.Source, next)
+	}
+
 	env := os.Getenv("ENVIRONMENT")
+	lostRows := 0
 
 	for {
-		stocks, nextPage, err := s.Fetcher.FetchPage(next)
+		if err := ctx.Err(); err != nil {
+			log.Printf("Sincronización de %s cancelada en next_page=%q", s.Source, next)
+			return stats, err
+		}
+
+		page, err := s.Fetcher.FetchPage(ctx, next)
+		if errors.Is(err, domain.ErrUnauthorized) {
+			return stats, fmt.Errorf("sincronización cancelada, revisa API_TOKEN: %w", err)
+		}
 		if err != nil {
-			return err
+			if errors.Is(err, domain.ErrUpstream) || errors.Is(err, domain.ErrRateLimited) {
+				log.Printf("Sincronización de %s interrumpida en next_page=%q: %v", s.Source, next, err)
+			}
+			return stats, err
 		}
+		stats.PagesFetched++
+		nextPage := page.NextPage
 
-		for _, stock := range stocks {
-			if err := s.Repo.Save(stock); err != nil {
-				log.Printf("Error al guardar stock [%s]: %v", stock.Ticker, err)
+		// Cada fila se cuenta una sola vez: en cuarentena o, si la cuarentena
+		// falla, como fallida.
+		quarantined := s.quarantine(page.Rejected)
+		lost := len(page.Rejected) - quarantined
+		stats.RowsQuarantined += quarantined
+
+		reachedWatermark := false
+		var pageMax time.Time
+		pending := make([]domain.Stock, 0, len(page.Stocks))
+		for _, stock := range page.Stocks {
+			if stock.ReportedAt.Before(watermark) {
+				reachedWatermark = true
+				continue
+			}
+			if stock.ReportedAt.After(pageMax) {
+				pageMax = stock.ReportedAt
 			}
+			pending = append(pending, stock)
+		}
+
+		result, err := s.Repo.SaveBatch(pending)
+		if err != nil {
+			log.Printf("Error al guardar página de stocks: %v", err)
+		}
+		stats.RowsInserted += result.Inserted
+		stats.RowsUpdated += result.Updated
+		quarantined = s.quarantine(result.Rejected)
+		stats.RowsQuarantined += quarantined
+		lost += len(result.Rejected) - quarantined
+		stats.RowsFailed += lost
+		if s.OnPage != nil {
+			s.OnPage(stats)
 		}
 
+		// La marca de agua solo avanza con páginas cuyas filas quedaron
+		// guardadas o en cuarentena; si alguna se perdió, no se vuelve a
+		// guardar el estado y la próxima corrida repite desde el último cursor
+		// completo.
+		if lost > 0 {
+			lostRows += lost
+			log.Printf("%d ratings de la página next_page=%q no se guardaron ni quedaron en cuarentena", lost, next)
+		} else if pageMax.After(runMax) {
+			runMax = pageMax
+		}
+
+		if reachedWatermark {
+			log.Printf("Marca de agua alcanzada (%s), se detiene la paginación", watermark.Format(time.RFC3339))
+			break
+		}
 		if nextPage == "" {
 			break
 		}
 		next = nextPage
 
+		if lostRows == 0 {
+			if err := s.State.SaveSyncState(domain.SyncState{
+				Source:         s.Source,
+				LastReportedAt: state.LastReportedAt,
+				RunReportedAt:  runMax,
+				NextPage:       next,
+			}); err != nil {
+				return stats, err
+			}
+		}
+
+		// La pausa no demora la cancelación: al cancelar ctx, la vuelta
+		// siguiente devuelve ctx.Err().
 		if env == "dev" {
-			time.Sleep(500 * time.Millisecond)
+			select {
+			case <-ctx.Done():
+			case <-time.After(500 * time.Millisecond):
+			}
 		}
+	}
 
+	if lostRows > 0 {
+		return stats, fmt.Errorf("%d ratings no se guardaron ni quedaron en cuarentena; no se actualiza el estado de %s", lostRows, s.Source)
+	}
+
+	lastReportedAt := state.LastReportedAt
+	if runMax.After(lastReportedAt) {
+		lastReportedAt = runMax
+	}
+	if err := s.State.SaveSyncState(domain.SyncState{
+		Source:         s.Source,
+		LastReportedAt: lastReportedAt,
+	}); err != nil {
+		return stats, err
 	}
 
-	log.Println("Sincronización completada")
-	return nil
+	log.Printf("Sincronización completada: %d páginas, %d insertados, %d actualizados, %d fallidos, %d en cuarentena",
+		stats.PagesFetched, stats.RowsInserted, stats.RowsUpdated, stats.RowsFailed, stats.RowsQuarantined)
+	return stats, nil
+}
+
+// quarantine envía los ratings rechazados a la cuarentena y devuelve cuántos
+// quedaron registrados.
+func (s *SyncService) quarantine(rejected []domain.RejectedStock) int {
+	if len(rejected) == 0 {
+		return 0
+	}
+	if err := s.Repo.QuarantineStocks(s.Source, rejected); err != nil {
+		log.Printf("Error enviando %d ratings a cuarentena: %v", len(rejected), err)
+		return 0
+	}
+	return len(rejected)
 }
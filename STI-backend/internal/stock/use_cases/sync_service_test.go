@@ -0,0 +1,214 @@
+package use_cases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viteant/stockinsight/internal/stock/domain"
+)
+
+type fakeFetcher struct {
+	pages map[string]domain.StockPage
+	// errs se devuelven en orden antes de entregar las páginas.
+	errs  []error
+	calls int
+}
+
+func (f *fakeFetcher) FetchPage(ctx context.Context, nextPage string) (domain.StockPage, error) {
+	f.calls++
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		return domain.StockPage{}, err
+	}
+	return f.pages[nextPage], nil
+}
+
+type fakeSaver struct {
+	saved       []domain.Stock
+	quarantined []domain.RejectedStock
+	failSave    bool
+	failQuarant bool
+}
+
+func (f *fakeSaver) SaveBatch(stocks []domain.Stock) (domain.SaveResult, error) {
+	if f.failSave {
+		result := domain.SaveResult{Failed: len(stocks)}
+		for _, s := range stocks {
+			result.Rejected = append(result.Rejected, domain.RejectedStock{Raw: domain.RawFromStock(s), Err: errors.New("db caída")})
+		}
+		return result, errors.New("db caída")
+	}
+	f.saved = append(f.saved, stocks...)
+	return domain.SaveResult{Inserted: len(stocks)}, nil
+}
+
+func (f *fakeSaver) QuarantineStocks(source string, rejected []domain.RejectedStock) error {
+	if f.failQuarant {
+		return errors.New("cuarentena caída")
+	}
+	f.quarantined = append(f.quarantined, rejected...)
+	return nil
+}
+
+type fakeSyncState struct {
+	state domain.SyncState
+	// saved guarda cada estado en el orden en que se grabó.
+	saved []domain.SyncState
+}
+
+func (f *fakeSyncState) GetSyncState(source string) (domain.SyncState, error) {
+	return f.state, nil
+}
+
+func (f *fakeSyncState) SaveSyncState(state domain.SyncState) error {
+	f.state = state
+	f.saved = append(f.saved, state)
+	return nil
+}
+
+func TestSync_QuarantinesRejectedRatings(t *testing.T) {
+	reportedAt := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	fetcher := &fakeFetcher{pages: map[string]domain.StockPage{
+		"": {
+			Stocks:   []domain.Stock{{Ticker: "AAPL", ReportedAt: reportedAt}},
+			Rejected: []domain.RejectedStock{{Raw: domain.RawStock{Ticker: "MSFT", TargetTo: "N/A"}, Err: errors.New("inválido")}},
+			NextPage: "p2",
+		},
+		"p2": {Stocks: []domain.Stock{{Ticker: "NVDA", ReportedAt: reportedAt}}},
+	}}
+	saver := &fakeSaver{}
+
+	stats, err := NewSyncService(fetcher, saver, &fakeSyncState{}).Sync(context.Background(), false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, stats.PagesFetched)
+	assert.Equal(t, 2, stats.RowsInserted)
+	assert.Equal(t, 1, stats.RowsQuarantined)
+	assert.Equal(t, "MSFT", saver.quarantined[0].Raw.Ticker)
+}
+
+func TestSync_QuarantinesFailedBatches(t *testing.T) {
+	reportedAt := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	fetcher := &fakeFetcher{pages: map[string]domain.StockPage{
+		"": {Stocks: []domain.Stock{{Ticker: "AAPL", TargetTo: 12.5, Currency: "USD", ReportedAt: reportedAt}}},
+	}}
+	saver := &fakeSaver{failSave: true}
+
+	stats, err := NewSyncService(fetcher, saver, &fakeSyncState{}).Sync(context.Background(), false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, stats.RowsFailed)
+	assert.Equal(t, 1, stats.RowsQuarantined)
+	assert.Equal(t, "12.50 USD", saver.quarantined[0].Raw.TargetTo)
+	assert.Equal(t, "2025-03-10T00:00:00Z", saver.quarantined[0].Raw.Time)
+}
+
+func TestSync_KeepsStateWhenRowsAreLost(t *testing.T) {
+	previous := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	fetcher := &fakeFetcher{pages: map[string]domain.StockPage{
+		"":   {Stocks: []domain.Stock{{Ticker: "AAPL", ReportedAt: previous.AddDate(0, 0, 9)}}, NextPage: "p2"},
+		"p2": {Stocks: []domain.Stock{{Ticker: "MSFT", ReportedAt: previous.AddDate(0, 0, 5)}}},
+	}}
+	state := &fakeSyncState{state: domain.SyncState{LastReportedAt: previous}}
+	saver := &fakeSaver{failSave: true, failQuarant: true}
+
+	stats, err := NewSyncService(fetcher, saver, state).Sync(context.Background(), false)
+
+	assert.Error(t, err)
+	assert.Equal(t, 2, stats.PagesFetched)
+	assert.Equal(t, 2, stats.RowsFailed)
+	assert.Equal(t, 0, stats.RowsQuarantined)
+	assert.Equal(t, domain.SyncState{LastReportedAt: previous}, state.state)
+}
+
+func TestSync_StopsOnUpstreamErrors(t *testing.T) {
+	for _, want := range []error{domain.ErrUnauthorized, domain.ErrUpstream, domain.ErrRateLimited} {
+		fetcher := &fakeFetcher{errs: []error{want, want, want, want, want}}
+
+		_, err := NewSyncService(fetcher, &fakeSaver{}, &fakeSyncState{}).Sync(context.Background(), false)
+
+		// Los reintentos son del cliente HTTP: el servicio no repite la página.
+		assert.ErrorIs(t, err, want)
+		assert.Equal(t, 1, fetcher.calls)
+	}
+}
+
+func TestSync_StopsAtWatermark(t *testing.T) {
+	watermark := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	fetcher := &fakeFetcher{pages: map[string]domain.StockPage{
+		"": {Stocks: []domain.Stock{
+			{Ticker: "AAPL", ReportedAt: watermark.AddDate(0, 0, 2)},
+			{Ticker: "MSFT", ReportedAt: watermark.AddDate(0, 0, -1)},
+		}, NextPage: "p2"},
+		"p2": {Stocks: []domain.Stock{{Ticker: "NVDA", ReportedAt: watermark.AddDate(0, 0, -2)}}},
+	}}
+	state := &fakeSyncState{state: domain.SyncState{Source: DefaultSyncSource, LastReportedAt: watermark}}
+	saver := &fakeSaver{}
+
+	stats, err := NewSyncService(fetcher, saver, state).Sync(context.Background(), false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, stats.PagesFetched)
+	assert.Len(t, saver.saved, 1)
+	assert.Equal(t, "AAPL", saver.saved[0].Ticker)
+	assert.Equal(t, domain.SyncState{Source: DefaultSyncSource, LastReportedAt: watermark.AddDate(0, 0, 2)}, state.state)
+}
+
+func TestSync_SavesCursorAndResumes(t *testing.T) {
+	reportedAt := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	fetcher := &fakeFetcher{pages: map[string]domain.StockPage{
+		"":   {Stocks: []domain.Stock{{Ticker: "AAPL", ReportedAt: reportedAt}}, NextPage: "p2"},
+		"p2": {Stocks: []domain.Stock{{Ticker: "MSFT", ReportedAt: reportedAt.AddDate(0, 0, -1)}}, NextPage: "p3"},
+	}}
+	state := &fakeSyncState{}
+
+	// Paso 1: se cancela tras la segunda página; queda guardado el cursor de
+	// la página siguiente, sin mover la marca de agua.
+	ctx, cancel := context.WithCancel(context.Background())
+	service := NewSyncService(fetcher, &fakeSaver{}, state)
+	service.OnPage = func(stats domain.SyncStats) {
+		if stats.PagesFetched == 2 {
+			cancel()
+		}
+	}
+	_, err := service.Sync(ctx, false)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Len(t, state.saved, 2)
+	assert.Equal(t, "p2", state.saved[0].NextPage)
+	assert.Equal(t, domain.SyncState{Source: DefaultSyncSource, RunReportedAt: reportedAt, NextPage: "p3"}, state.state)
+
+	// Paso 2: la corrida siguiente arranca en p3 y, al terminar, la marca de
+	// agua toma el máximo de toda la corrida y se borra el cursor.
+	fetcher.pages["p3"] = domain.StockPage{Stocks: []domain.Stock{{Ticker: "NVDA", ReportedAt: reportedAt.AddDate(0, 0, -2)}}}
+	fetcher.calls = 0
+	saver := &fakeSaver{}
+	stats, err := NewSyncService(fetcher, saver, state).Sync(context.Background(), false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, fetcher.calls)
+	assert.Equal(t, 1, stats.PagesFetched)
+	assert.Equal(t, "NVDA", saver.saved[0].Ticker)
+	assert.Equal(t, domain.SyncState{Source: DefaultSyncSource, LastReportedAt: reportedAt}, state.state)
+}
+
+func TestSync_FullIgnoresCursorAndWatermark(t *testing.T) {
+	watermark := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	fetcher := &fakeFetcher{pages: map[string]domain.StockPage{
+		"":   {Stocks: []domain.Stock{{Ticker: "AAPL", ReportedAt: watermark.AddDate(0, 0, -5)}}, NextPage: "p2"},
+		"p2": {Stocks: []domain.Stock{{Ticker: "MSFT", ReportedAt: watermark.AddDate(0, 0, -6)}}},
+	}}
+	state := &fakeSyncState{state: domain.SyncState{Source: DefaultSyncSource, LastReportedAt: watermark, NextPage: "p2"}}
+	saver := &fakeSaver{}
+
+	stats, err := NewSyncService(fetcher, saver, state).Sync(context.Background(), true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, stats.PagesFetched)
+	assert.Len(t, saver.saved, 2)
+	// Una corrida completa no retrocede la marca de agua.
+	assert.Equal(t, watermark, state.state.LastReportedAt)
+	assert.Empty(t, state.state.NextPage)
+}
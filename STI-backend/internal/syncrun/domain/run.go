@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	KindStocks   = "stocks"
+	KindFinances = "finances"
+	KindRescore  = "rescore"
+
+	StatusRunning = "running"
+	StatusSuccess = "success"
+	StatusFailed  = "failed"
+)
+
+// Kinds son los tipos de corrida, en el orden en que se documentan.
+var Kinds = []string{KindStocks, KindFinances, KindRescore}
+
+var ErrRunNotFound = errors.New("sync run not found")
+
+// ErrRunAbandoned es el error con que se cierran las corridas que dejaron de
+// enviar heartbeat, por ejemplo porque el proceso se cayó.
+var ErrRunAbandoned = errors.New("corrida abandonada: el proceso dejó de enviar heartbeat")
+
+// Run es una ejecución de sincronización de stocks, de actualización de
+// finanzas o de recalificación de brokers. En las corridas de finanzas y de
+// recalificación, PagesFetched cuenta los tickers procesados; en las de
+// recalificación, RowsInserted cuenta los resultados calculados.
+// RowsQuarantined cuenta los registros rechazados que quedaron en cuarentena y
+// RowsFailed los que tampoco se pudieron enviar; cada registro cuenta en uno.
+// HeartbeatAt es el último heartbeat: una corrida running con un heartbeat
+// viejo quedó abandonada y se cerrará al iniciar la siguiente.
+type Run struct {
+	ID              string     `json:"id"`
+	Kind            string     `json:"kind"`
+	Source          string     `json:"source"`
+	Status          string     `json:"status"`
+	StartedAt       time.Time  `json:"started_at"`
+	HeartbeatAt     time.Time  `json:"heartbeat_at"`
+	FinishedAt      *time.Time `json:"finished_at"`
+	PagesFetched    int        `json:"pages_fetched"`
+	RowsInserted    int        `json:"rows_inserted"`
+	RowsUpdated     int        `json:"rows_updated"`
+	RowsFailed      int        `json:"rows_failed"`
+	RowsQuarantined int        `json:"rows_quarantined"`
+	Error           string     `json:"error,omitempty"`
+}
+
+type RunRepository interface {
+	Start(kind, source string) (Run, error)
+	Heartbeat(id string) error
+	// FailStale marca como fallidas las corridas en curso sin heartbeat desde
+	// hace más de staleAfter y devuelve cuántas marcó.
+	FailStale(staleAfter time.Duration) (int, error)
+	Finish(run Run) error
+	List(kind string, limit int) ([]Run, error)
+	GetByID(id string) (Run, error)
+}
+
+// Complete cierra la corrida con el resultado final.
+func (r *Run) Complete(err error) {
+	now := time.Now()
+	r.FinishedAt = &now
+	r.Status = StatusSuccess
+	r.Error = ""
+	if err != nil {
+		r.Status = StatusFailed
+		r.Error = err.Error()
+	}
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/viteant/stockinsight/internal/syncrun/domain"
+)
+
+type PersistenceRunRepository struct {
+	DB *sql.DB
+}
+
+func NewCockroachRunRepository(db *sql.DB) *PersistenceRunRepository {
+	return &PersistenceRunRepository{DB: db}
+}
+
+func (r *PersistenceRunRepository) Start(kind, source string) (domain.Run, error) {
+	run := domain.Run{
+		Kind:   kind,
+		Source: source,
+		Status: domain.StatusRunning,
+	}
+
+	err := r.DB.QueryRow(`
+		INSERT INTO sync_runs (kind, source, status)
+		VALUES ($1, $2, $3)
+		RETURNING id, started_at
+	`, kind, source, domain.StatusRunning).Scan(&run.ID, &run.StartedAt)
+
+	return run, err
+}
+
+func (r *PersistenceRunRepository) Heartbeat(id string) error {
+	_, err := r.DB.Exec(`
+		UPDATE sync_runs SET heartbeat_at = now()
+		WHERE id::STRING = $1 AND status = $2
+	`, id, domain.StatusRunning)
+	return err
+}
+
+func (r *PersistenceRunRepository) FailStale(staleAfter time.Duration) (int, error) {
+	res, err := r.DB.Exec(`
+		UPDATE sync_runs SET
+			status = $1,
+			finished_at = now(),
+			error = $2
+		WHERE status = $3 AND heartbeat_at < now() - $4 * INTERVAL '1 microsecond'
+	`, domain.StatusFailed, domain.ErrRunAbandoned.Error(), domain.StatusRunning, staleAfter.Microseconds())
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func (r *PersistenceRunRepository) Finish(run domain.Run) error {
+	_, err := r.DB.Exec(`
+		UPDATE sync_runs SET
+			status = $2,
+			finished_at = $3,
+			pages_fetched = $4,
+			rows_inserted = $5,
+			rows_updated = $6,
+			rows_failed = $7,
+			rows_quarantined = $8,
+			error = $9
+		WHERE id = $1
+	`,
+		run.ID,
+		run.Status,
+		run.FinishedAt,
+		run.PagesFetched,
+		run.RowsInserted,
+		run.RowsUpdated,
+		run.RowsFailed,
+		run.RowsQuarantined,
+		sql.NullString{String: run.Error, Valid: run.Error != ""},
+	)
+	return err
+}
+
+const selectRunColumns = `
+	SELECT id, kind, source, status, started_at, heartbeat_at, finished_at,
+	       pages_fetched, rows_inserted, rows_updated, rows_failed, rows_quarantined, error
+	FROM sync_runs
+`
+
+func (r *PersistenceRunRepository) List(kind string, limit int) ([]domain.Run, error) {
+	rows, err := r.DB.Query(selectRunColumns+`
+		WHERE $1 = '' OR kind = $1
+		ORDER BY started_at DESC
+		LIMIT $2
+	`, kind, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := []domain.Run{}
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, rows.Err()
+}
+
+func (r *PersistenceRunRepository) GetByID(id string) (domain.Run, error) {
+	run, err := scanRun(r.DB.QueryRow(selectRunColumns+`WHERE id::STRING = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return run, domain.ErrRunNotFound
+	}
+	return run, err
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanRun(row rowScanner) (domain.Run, error) {
+	var run domain.Run
+	var finishedAt sql.NullTime
+	var runErr sql.NullString
+
+	err := row.Scan(
+		&run.ID,
+		&run.Kind,
+		&run.Source,
+		&run.Status,
+		&run.StartedAt,
+		&run.HeartbeatAt,
+		&finishedAt,
+		&run.PagesFetched,
+		&run.RowsInserted,
+		&run.RowsUpdated,
+		&run.RowsFailed,
+		&run.RowsQuarantined,
+		&runErr,
+	)
+	if err != nil {
+		return run, err
+	}
+
+	if finishedAt.Valid {
+		run.FinishedAt = &finishedAt.Time
+	}
+	run.Error = runErr.String
+	return run, nil
+}
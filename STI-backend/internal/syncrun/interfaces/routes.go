@@ -0,0 +1,18 @@
+package interfaces
+
+import (
+	"database/sql"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/viteant/stockinsight/internal/syncrun/infrastructure/repository"
+	"github.com/viteant/stockinsight/internal/syncrun/use_cases"
+)
+
+func RegisterSyncRunRoutes(app fiber.Router, db *sql.DB) {
+	runRepo := repository.NewCockroachRunRepository(db)
+	runService := &use_cases.RunService{Repo: runRepo}
+	runHandler := NewRunHandler(runService)
+
+	app.Get("/sync/runs", runHandler.ListRuns)
+	app.Get("/sync/runs/:id", runHandler.GetRun)
+}
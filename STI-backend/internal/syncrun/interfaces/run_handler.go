@@ -0,0 +1,96 @@
+package interfaces
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/viteant/stockinsight/internal/problem"
+	"github.com/viteant/stockinsight/internal/syncrun/domain"
+	"github.com/viteant/stockinsight/internal/syncrun/use_cases"
+)
+
+const (
+	defaultRunsLimit = 20
+	maxRunsLimit     = 100
+)
+
+type RunHandler struct {
+	useCase *use_cases.RunService
+}
+
+func NewRunHandler(useCase *use_cases.RunService) *RunHandler {
+	return &RunHandler{
+		useCase: useCase,
+	}
+}
+
+// ListRuns godoc
+// @Summary Historial de sincronizaciones
+// @Description Devuelve las corridas más recientes de sincronización de stocks, actualización de finanzas y recalificación de brokers.
+// @Tags Sync
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param kind query string false "Filtra por tipo (stocks, finances o rescore)"
+// @Param limit query int false "Cantidad de corridas (default: 20, máximo: 100)"
+// @Success 200 {array} domain.Run
+// @Failure 400 {object} problem.Problem
+// @Failure 401 {object} problem.Problem
+// @Failure 429 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /api/sync/runs [get]
+func (h *RunHandler) ListRuns(c *fiber.Ctx) error {
+	var fieldErrs []problem.FieldError
+	kind := c.Query("kind")
+	if kind != "" && !slices.Contains(domain.Kinds, kind) {
+		fieldErrs = append(fieldErrs, problem.FieldError{Field: "kind", Value: kind, Reason: "debe ser uno de: " + strings.Join(domain.Kinds, ", ")})
+	}
+	limit := defaultRunsLimit
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxRunsLimit {
+			fieldErrs = append(fieldErrs, problem.FieldError{Field: "limit", Value: raw, Reason: fmt.Sprintf("debe estar entre 1 y %d", maxRunsLimit)})
+		}
+		limit = n
+	}
+	if len(fieldErrs) > 0 {
+		return problem.Invalid(c, fieldErrs...)
+	}
+
+	runs, err := h.useCase.ListRuns(kind, limit)
+	if err != nil {
+		return problem.Write(c, fiber.StatusInternalServerError, "Error fetching sync runs", "")
+	}
+	return c.JSON(runs)
+}
+
+// GetRun godoc
+// @Summary Detalle de una sincronización
+// @Description Devuelve el detalle de una corrida de sincronización.
+// @Tags Sync
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Param id path string true "ID de la corrida"
+// @Success 200 {object} domain.Run
+// @Failure 401 {object} problem.Problem
+// @Failure 404 {object} problem.Problem
+// @Failure 429 {object} problem.Problem
+// @Failure 500 {object} problem.Problem
+// @Router /api/sync/runs/{id} [get]
+func (h *RunHandler) GetRun(c *fiber.Ctx) error {
+	run, err := h.useCase.GetRun(c.Params("id"))
+	if errors.Is(err, domain.ErrRunNotFound) {
+		return problem.Write(c, fiber.StatusNotFound, "Sync run not found", "")
+	}
+	if err != nil {
+		return problem.Write(c, fiber.StatusInternalServerError, "Error fetching sync run", "")
+	}
+	return c.JSON(run)
+}
@@ -0,0 +1,44 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/viteant/stockinsight/internal/problem"
+	"github.com/viteant/stockinsight/internal/syncrun/use_cases"
+)
+
+func TestListRuns_InvalidParams(t *testing.T) {
+	app := fiber.New()
+	app.Get("/api/sync/runs", NewRunHandler(&use_cases.RunService{}).ListRuns)
+
+	cases := []struct {
+		query  string
+		fields []string
+	}{
+		{"limit=0", []string{"limit"}},
+		{"limit=-5", []string{"limit"}},
+		{"limit=101", []string{"limit"}},
+		{"limit=diez", []string{"limit"}},
+		{"kind=prices", []string{"kind"}},
+		{"kind=Stocks&limit=1000", []string{"kind", "limit"}},
+	}
+	for _, c := range cases {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/sync/runs?"+c.query, nil))
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode, c.query)
+
+		var body problem.Problem
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, problem.TypeValidation, body.Type)
+
+		fields := make([]string, len(body.Errors))
+		for i, e := range body.Errors {
+			fields[i] = e.Field
+		}
+		assert.Equal(t, c.fields, fields, c.query)
+	}
+}
@@ -0,0 +1,19 @@
+package use_cases
+
+import (
+	"github.com/viteant/stockinsight/internal/syncrun/domain"
+)
+
+// RunService consulta las corridas. Solo lee: las corridas abandonadas las
+// cierra el Tracker al iniciar la siguiente.
+type RunService struct {
+	Repo domain.RunRepository
+}
+
+func (s *RunService) ListRuns(kind string, limit int) ([]domain.Run, error) {
+	return s.Repo.List(kind, limit)
+}
+
+func (s *RunService) GetRun(id string) (domain.Run, error) {
+	return s.Repo.GetByID(id)
+}
@@ -0,0 +1,92 @@
+package use_cases
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/viteant/stockinsight/internal/syncrun/domain"
+)
+
+const (
+	defaultHeartbeatEvery = 30 * time.Second
+	defaultStaleAfter     = 5 * time.Minute
+)
+
+// Tracker registra corridas en sync_runs y actualiza su heartbeat mientras
+// están en curso. Una corrida cuyo proceso se cae deja de enviar heartbeat y
+// se marca como fallida pasado StaleAfter.
+type Tracker struct {
+	Repo           domain.RunRepository
+	HeartbeatEvery time.Duration
+	StaleAfter     time.Duration
+}
+
+// NewTracker toma el intervalo de heartbeat de SYNC_RUN_HEARTBEAT_SEC (por
+// defecto: 30) y el tiempo sin heartbeat tras el que una corrida se da por
+// abandonada de SYNC_RUN_STALE_AFTER_SEC (por defecto: 300).
+func NewTracker(repo domain.RunRepository) *Tracker {
+	return &Tracker{
+		Repo:           repo,
+		HeartbeatEvery: envSeconds("SYNC_RUN_HEARTBEAT_SEC", defaultHeartbeatEvery),
+		StaleAfter:     envSeconds("SYNC_RUN_STALE_AFTER_SEC", defaultStaleAfter),
+	}
+}
+
+// Start cierra las corridas abandonadas, registra una nueva y mantiene su
+// heartbeat hasta que se llame a la función devuelta, que debe llamarse antes
+// de Finish.
+func (t *Tracker) Start(kind, source string) (domain.Run, func(), error) {
+	t.FailStale()
+
+	run, err := t.Repo.Start(kind, source)
+	if err != nil {
+		return run, nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(t.HeartbeatEvery)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := t.Repo.Heartbeat(run.ID); err != nil {
+					log.Printf("Error actualizando el heartbeat de la corrida %s: %v", run.ID, err)
+				}
+			}
+		}
+	}()
+
+	return run, func() {
+		cancel()
+		<-done
+	}, nil
+}
+
+// FailStale marca como fallidas las corridas sin heartbeat reciente.
+func (t *Tracker) FailStale() {
+	n, err := t.Repo.FailStale(t.StaleAfter)
+	if err != nil {
+		log.Printf("Error cerrando corridas abandonadas: %v", err)
+		return
+	}
+	if n > 0 {
+		log.Printf("%d corridas sin heartbeat desde hace más de %v se marcaron como fallidas", n, t.StaleAfter)
+	}
+}
+
+func envSeconds(key string, fallback time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return time.Duration(n) * time.Second
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,59 @@
+package use_cases
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/viteant/stockinsight/internal/syncrun/domain"
+)
+
+type fakeRuns struct {
+	mu         sync.Mutex
+	heartbeats int
+	staleAfter time.Duration
+}
+
+func (f *fakeRuns) Start(kind, source string) (domain.Run, error) {
+	return domain.Run{ID: "run-1", Kind: kind, Source: source, Status: domain.StatusRunning}, nil
+}
+
+func (f *fakeRuns) Heartbeat(id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.heartbeats++
+	return nil
+}
+
+func (f *fakeRuns) FailStale(staleAfter time.Duration) (int, error) {
+	f.staleAfter = staleAfter
+	return 0, nil
+}
+
+func (f *fakeRuns) Finish(run domain.Run) error                       { return nil }
+func (f *fakeRuns) List(kind string, limit int) ([]domain.Run, error) { return nil, nil }
+func (f *fakeRuns) GetByID(id string) (domain.Run, error)             { return domain.Run{}, nil }
+
+func TestTracker_StartClosesStaleRunsAndSendsHeartbeats(t *testing.T) {
+	repo := &fakeRuns{}
+	tracker := &Tracker{Repo: repo, HeartbeatEvery: time.Millisecond, StaleAfter: time.Minute}
+
+	run, stop, err := tracker.Start(domain.KindStocks, "ratings_api")
+	assert.NoError(t, err)
+	assert.Equal(t, "run-1", run.ID)
+	assert.Equal(t, time.Minute, repo.staleAfter)
+
+	assert.Eventually(t, func() bool {
+		repo.mu.Lock()
+		defer repo.mu.Unlock()
+		return repo.heartbeats >= 2
+	}, time.Second, time.Millisecond)
+
+	stop()
+	repo.mu.Lock()
+	sent := repo.heartbeats
+	repo.mu.Unlock()
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, sent, repo.heartbeats)
+}
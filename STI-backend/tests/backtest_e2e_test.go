@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+
+	backtestdomain "github.com/viteant/stockinsight/internal/backtest/domain"
+	"github.com/viteant/stockinsight/internal/backtest/infrastructure/repository"
+	"github.com/viteant/stockinsight/internal/db"
+	financedomain "github.com/viteant/stockinsight/internal/finance/domain"
+)
+
+// seedBacktest inserta ratings y cierres de un ticker propio del test y los
+// borra al terminar.
+func seedBacktest(t *testing.T) *sql.DB {
+	_ = godotenv.Load("../.env")
+	dbConn := db.NewCockroachDB()
+
+	const ticker, brokerage = "ZZBT", "E2E Backtest Securities"
+	t.Cleanup(func() {
+		_, _ = dbConn.Exec(`DELETE FROM stocks WHERE ticker = $1`, ticker)
+		_, _ = dbConn.Exec(`DELETE FROM finances WHERE ticker = $1`, ticker)
+	})
+
+	_, err := dbConn.Exec(`
+		INSERT INTO stocks (ticker, company, brokerage, action, normalize_rating_from, normalize_rating_to, created_at)
+		VALUES ($1, 'E2E', $2, 'upgraded by', 'hold', 'buy', '2024-07-02 14:00:00+00'),
+			($1, 'E2E', $2, 'downgraded by', 'buy', 'hold', '2024-07-04 14:00:00+00'),
+			($1, 'E2E', $2, 'upgraded by', 'hold', 'buy', '2024-08-01 14:00:00+00')
+	`, ticker, brokerage)
+	assert.NoError(t, err)
+
+	_, err = dbConn.Exec(`
+		INSERT INTO finances (ticker, date, close, adj_close, source)
+		VALUES ($1, '2024-07-01', 100, 50, 'e2e'),
+			($1, '2024-07-02', 110, NULL, 'e2e'),
+			($1, '2024-07-03', 120, 60, 'e2e')
+	`, ticker)
+	assert.NoError(t, err)
+	return dbConn
+}
+
+func TestBacktestRepository_ListRatingEvents(t *testing.T) {
+	repo := repository.NewCockroachBacktestRepository(seedBacktest(t))
+
+	from := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 7, 4, 0, 0, 0, 0, time.UTC)
+	events, err := repo.ListRatingEvents("e2e backtest securities", from, to)
+	assert.NoError(t, err)
+
+	// to es inclusivo: entra el downgrade del 4 pero no el upgrade de agosto.
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, "upgraded by", events[0].Action)
+		assert.Equal(t, "buy", events[0].RatingTo)
+		assert.Equal(t, "downgraded by", events[1].Action)
+	}
+}
+
+func TestBacktestRepository_LoadCloses(t *testing.T) {
+	repo := repository.NewCockroachBacktestRepository(seedBacktest(t))
+
+	from := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 7, 3, 0, 0, 0, 0, time.UTC)
+
+	raw, err := repo.LoadCloses(from, to, financedomain.PriceBasisRaw)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{100, 110, 120}, closes(raw["ZZBT"]))
+
+	// Sin adj_close se usa el cierre.
+	adjusted, err := repo.LoadCloses(from, to, financedomain.PriceBasisAdjusted)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{50, 110, 60}, closes(adjusted["ZZBT"]))
+}
+
+func closes(points []backtestdomain.PricePoint) []float64 {
+	result := make([]float64, len(points))
+	for i, p := range points {
+		result[i] = p.Close
+	}
+	return result
+}
@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/viteant/stockinsight/internal/db"
+	financedomain "github.com/viteant/stockinsight/internal/finance/domain"
+	"github.com/viteant/stockinsight/internal/stock/domain"
+	"github.com/viteant/stockinsight/internal/stock/infrastructure/repository"
+)
+
+func TestConsensusRepository_RanksAndLoadsOnlyRequestedTickers(t *testing.T) {
+	_ = godotenv.Load("../.env")
+	dbConn := db.NewCockroachDB()
+	defer dbConn.Close()
+
+	tickers := []string{"ZZCA", "ZZCB", "ZZCC"}
+	cleanup := func() {
+		for _, ticker := range tickers {
+			_, _ = dbConn.Exec(`DELETE FROM stocks WHERE ticker = $1`, ticker)
+			_, _ = dbConn.Exec(`DELETE FROM finances WHERE ticker = $1`, ticker)
+		}
+	}
+	cleanup()
+	t.Cleanup(cleanup)
+
+	// ZZCA: un broker en buy. ZZCC: dos brokers en buy (el primero pasó de
+	// sell a buy). ZZCB: un broker en sell.
+	_, err := dbConn.Exec(`
+		INSERT INTO stocks (ticker, company, brokerage, action, normalize_rating_from, normalize_rating_to, target_to, currency, created_at)
+		VALUES ('ZZCA', 'E2E', 'E2E Consensus A', 'upgraded by', 'hold', 'buy', 50, 'USD', '2024-07-02 14:00:00+00'),
+			('ZZCB', 'E2E', 'E2E Consensus A', 'downgraded by', 'hold', 'sell', 10, 'USD', '2024-07-02 14:00:00+00'),
+			('ZZCC', 'E2E', 'E2E Consensus A', 'downgraded by', 'hold', 'sell', 20, 'USD', '2024-07-01 14:00:00+00'),
+			('ZZCC', 'E2E', 'E2E Consensus A', 'upgraded by', 'sell', 'buy', 30, 'USD', '2024-07-03 14:00:00+00'),
+			('ZZCC', 'E2E', 'E2E Consensus B', 'upgraded by', 'hold', 'buy', 32, 'USD', '2024-07-03 15:00:00+00')
+	`)
+	assert.NoError(t, err)
+	_, err = dbConn.Exec(`
+		INSERT INTO finances (ticker, date, close, source)
+		VALUES ('ZZCC', '2024-07-02', 25, 'e2e'), ('ZZCC', '2024-07-03', 26, 'e2e'), ('ZZCB', '2024-07-03', 9, 'e2e')
+	`)
+	assert.NoError(t, err)
+
+	repo, err := repository.NewCockroachStockRepository(dbConn)
+	assert.NoError(t, err)
+
+	ranked, err := repo.RankConsensusTickers(domain.ConsensusParams{PriceBasis: financedomain.PriceBasisRaw, Limit: domain.MaxConsensusLimit})
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, len(ranked), domain.MaxConsensusLimit)
+	// Con el mismo puntaje va primero el ticker con más brokers.
+	a, c := slices.Index(ranked, "ZZCA"), slices.Index(ranked, "ZZCC")
+	if a >= 0 && c >= 0 {
+		assert.Less(t, c, a)
+	}
+
+	ratings, err := repo.FetchLatestRatings([]string{"ZZCA", "ZZCC"})
+	assert.NoError(t, err)
+	assert.Len(t, ratings, 3)
+	for _, r := range ratings {
+		assert.NotEqual(t, "ZZCB", r.Ticker)
+		assert.Equal(t, "buy", r.NormalizeRatingTo, r.Ticker+" "+r.Brokerage)
+	}
+
+	closes, err := repo.FetchLatestCloses([]string{"ZZCA", "ZZCC"})
+	assert.NoError(t, err)
+	assert.Len(t, closes, 1)
+	assert.Equal(t, float32(26), closes["ZZCC"].Close)
+}
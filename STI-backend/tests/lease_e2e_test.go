@@ -0,0 +1,56 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/viteant/stockinsight/internal/db"
+	"github.com/viteant/stockinsight/internal/scheduler/domain"
+	"github.com/viteant/stockinsight/internal/scheduler/infrastructure/repository"
+)
+
+func TestLeaseRepository_AcquireRenewExpire(t *testing.T) {
+	_ = godotenv.Load("../.env")
+	dbConn := db.NewCockroachDB()
+	repo := repository.NewCockroachLeaseRepository(dbConn)
+
+	name := fmt.Sprintf("e2e-lease-%d", time.Now().UnixNano())
+	t.Cleanup(func() { _, _ = dbConn.Exec(`DELETE FROM job_leases WHERE name = $1`, name) })
+
+	first := time.Date(2024, 7, 3, 10, 0, 0, 0, time.UTC)
+	second := first.Add(30 * time.Minute)
+
+	// Acquire: la primera réplica toma el lease; la otra no, aunque pida una
+	// ejecución posterior, mientras no venza.
+	ok, err := repo.Acquire(name, "a", first, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = repo.Acquire(name, "b", second, time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// Renew: solo el dueño lo renueva.
+	assert.NoError(t, repo.Renew(name, "a", 50*time.Millisecond))
+	assert.ErrorIs(t, repo.Renew(name, "b", time.Minute), domain.ErrLeaseLost)
+
+	// Vencido, otra réplica lo toma para una ejecución posterior pero no para
+	// la misma.
+	time.Sleep(200 * time.Millisecond)
+	ok, err = repo.Acquire(name, "b", first, time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = repo.Acquire(name, "b", second, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.ErrorIs(t, repo.Renew(name, "a", time.Minute), domain.ErrLeaseLost)
+
+	// Release conserva scheduled_at: la misma ejecución no se vuelve a tomar.
+	assert.NoError(t, repo.Release(name, "b"))
+	ok, err = repo.Acquire(name, "a", second, time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,193 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/viteant/stockinsight/internal/cassette"
+	financedomain "github.com/viteant/stockinsight/internal/finance/domain"
+	"github.com/viteant/stockinsight/internal/finance/infrastructure/scraper"
+	financeusecases "github.com/viteant/stockinsight/internal/finance/use-cases"
+	"github.com/viteant/stockinsight/internal/stock/domain"
+	"github.com/viteant/stockinsight/internal/stock/infrastructure/api"
+	"github.com/viteant/stockinsight/internal/stock/use_cases"
+)
+
+// Estas pruebas reproducen las respuestas grabadas en testdata/cassettes y no
+// necesitan red ni base de datos. Para regrabarlas contra los servicios
+// reales se usa --refresh-cassettes con HTTP_CASSETTE_DIR apuntando ahí.
+
+// replayTape reproduce lo grabado en testdata/cassettes sin salir a la red.
+var replayTape = cassette.Config{Dir: "testdata/cassettes", Mode: cassette.ModeReplay}
+
+type memoryStockSaver struct {
+	saved       []domain.Stock
+	quarantined []domain.RejectedStock
+}
+
+func (m *memoryStockSaver) SaveBatch(stocks []domain.Stock) (domain.SaveResult, error) {
+	m.saved = append(m.saved, stocks...)
+	return domain.SaveResult{Inserted: len(stocks)}, nil
+}
+
+func (m *memoryStockSaver) QuarantineStocks(source string, rejected []domain.RejectedStock) error {
+	m.quarantined = append(m.quarantined, rejected...)
+	return nil
+}
+
+type memorySyncState struct {
+	state domain.SyncState
+}
+
+func (m *memorySyncState) GetSyncState(source string) (domain.SyncState, error) {
+	return m.state, nil
+}
+
+func (m *memorySyncState) SaveSyncState(state domain.SyncState) error {
+	m.state = state
+	return nil
+}
+
+func TestSyncReplay_FromCassette(t *testing.T) {
+	t.Setenv("API_ENDPOINT", "https://api.example.com/list")
+	t.Setenv("API_TOKEN", "token-de-prueba")
+
+	saver := &memoryStockSaver{}
+	state := &memorySyncState{}
+
+	stats, err := use_cases.NewSyncService(api.NewExternalAPIClient(replayTape), saver, state).Sync(context.Background(), false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, stats.PagesFetched)
+	assert.Equal(t, 3, stats.RowsInserted)
+	assert.Equal(t, 1, stats.RowsQuarantined)
+	if assert.Len(t, saver.saved, 3) {
+		assert.Equal(t, "AAPL", saver.saved[0].Ticker)
+		assert.Equal(t, float32(250), saver.saved[0].TargetTo)
+	}
+	if assert.Len(t, saver.quarantined, 1) {
+		assert.Equal(t, "MSFT", saver.quarantined[0].Raw.Ticker)
+	}
+	assert.Equal(t, time.Date(2024, 7, 3, 12, 0, 0, 0, time.UTC), state.state.LastReportedAt)
+	assert.Empty(t, state.state.NextPage)
+}
+
+func TestSyncReplay_UnrecordedPageFailsWithoutNetwork(t *testing.T) {
+	t.Setenv("API_ENDPOINT", "https://api.example.com/list")
+
+	state := &memorySyncState{state: domain.SyncState{NextPage: "sin-grabar"}}
+
+	_, err := use_cases.NewSyncService(api.NewExternalAPIClient(replayTape), &memoryStockSaver{}, state).Sync(context.Background(), false)
+
+	assert.ErrorIs(t, err, domain.ErrUpstream)
+}
+
+type memoryTickers struct {
+	ranges []financedomain.TickerRange
+}
+
+func (m *memoryTickers) GetTickersDateRange() ([]financedomain.TickerRange, error) {
+	return m.ranges, nil
+}
+
+type memoryFinances struct {
+	saved       []financedomain.Finance
+	actions     []financedomain.CorporateAction
+	quarantined []financedomain.RejectedFinance
+	failSave    bool
+}
+
+func (m *memoryFinances) BulkSave(data []financedomain.Finance) (financedomain.SaveResult, error) {
+	if m.failSave {
+		return financedomain.SaveResult{}, errors.New("db caída")
+	}
+	m.saved = append(m.saved, data...)
+	return financedomain.SaveResult{Inserted: len(data)}, nil
+}
+
+func (m *memoryFinances) GetDates(ticker string, from, to time.Time) ([]time.Time, error) {
+	return nil, nil
+}
+
+func (m *memoryFinances) GetCheckedDates(ticker string, from, to, since time.Time) ([]time.Time, error) {
+	return nil, nil
+}
+
+func (m *memoryFinances) SaveCheckedDates(ticker, source string, dates []time.Time) error {
+	return nil
+}
+
+func (m *memoryFinances) SaveCorporateActions(actions []financedomain.CorporateAction) error {
+	m.actions = append(m.actions, actions...)
+	return nil
+}
+
+func (m *memoryFinances) GetPrices(ticker string, from, to time.Time) ([]financedomain.Finance, error) {
+	return nil, nil
+}
+
+func (m *memoryFinances) QuarantineFinances(source string, rejected []financedomain.RejectedFinance) error {
+	m.quarantined = append(m.quarantined, rejected...)
+	return nil
+}
+
+func TestUpdateFinanceReplay_FromCassette(t *testing.T) {
+	t.Setenv("FINANCE_PROVIDERS", "yahoo")
+	t.Setenv("FINANCE_RATE_PER_SEC", "1000")
+
+	provider, err := scraper.NewFromEnv(replayTape)
+	assert.NoError(t, err)
+
+	tickers := &memoryTickers{ranges: []financedomain.TickerRange{{
+		Ticker:    "AAPL",
+		StartDate: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2024, 7, 3, 0, 0, 0, 0, time.UTC),
+	}}}
+	finances := &memoryFinances{}
+
+	useCase := financeusecases.NewUpdateFinanceDataUseCase(tickers, finances, provider)
+	useCase.LookaheadDays = 0
+	useCase.Now = func() time.Time { return time.Date(2024, 7, 10, 0, 0, 0, 0, time.UTC) }
+
+	summary, err := useCase.Execute(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, summary.TickersProcessed)
+	assert.Equal(t, 3, summary.RowsInserted)
+	if assert.Len(t, finances.saved, 3) {
+		assert.Equal(t, time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), finances.saved[0].Date)
+		assert.Equal(t, float32(216.75), finances.saved[0].Close)
+		assert.Equal(t, "yahoo", finances.saved[0].Source)
+	}
+	assert.Empty(t, finances.actions)
+}
+
+func TestUpdateFinanceReplay_QuarantinesRowsWhenSaveFails(t *testing.T) {
+	t.Setenv("FINANCE_PROVIDERS", "yahoo")
+	t.Setenv("FINANCE_RATE_PER_SEC", "1000")
+
+	provider, err := scraper.NewFromEnv(replayTape)
+	assert.NoError(t, err)
+
+	tickers := &memoryTickers{ranges: []financedomain.TickerRange{{
+		Ticker:    "AAPL",
+		StartDate: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2024, 7, 3, 0, 0, 0, 0, time.UTC),
+	}}}
+	finances := &memoryFinances{failSave: true}
+
+	useCase := financeusecases.NewUpdateFinanceDataUseCase(tickers, finances, provider)
+	useCase.LookaheadDays = 0
+	useCase.Now = func() time.Time { return time.Date(2024, 7, 10, 0, 0, 0, 0, time.UTC) }
+
+	summary, _ := useCase.Execute(context.Background())
+
+	assert.Equal(t, 1, summary.TickersFailed)
+	assert.Len(t, finances.quarantined, 3)
+	assert.Equal(t, 3, summary.RowsQuarantined)
+	assert.Equal(t, 0, summary.RowsFailed)
+}
@@ -13,25 +13,40 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/viteant/stockinsight/internal/api"
+	apikeydomain "github.com/viteant/stockinsight/internal/apikey/domain"
+	apikeyrepository "github.com/viteant/stockinsight/internal/apikey/infrastructure/repository"
+	apikeyusecases "github.com/viteant/stockinsight/internal/apikey/use_cases"
+	"github.com/viteant/stockinsight/internal/cassette"
 	"github.com/viteant/stockinsight/internal/db"
 )
 
-func setupE2EApp() *fiber.App {
+// setupE2EApp devuelve la app y una API key de lectura creada para el test.
+func setupE2EApp(t *testing.T) (*fiber.App, string) {
 	_ = godotenv.Load("../.env") // Asegúrate que DATABASE_URI esté cargado
 
 	dbConn := db.NewCockroachDB()
 	app := fiber.New()
 
-	api.RegisterRoutes(app, dbConn)
-	return app
+	service := apikeyusecases.NewAPIKeyService(apikeyrepository.NewCockroachAPIKeyRepository(dbConn))
+	key, created, err := service.Create("e2e", []string{apikeydomain.ScopeRead}, 0, 1000)
+	if err != nil {
+		t.Fatalf("no se pudo crear la API key: %v", err)
+	}
+	t.Cleanup(func() { _, _ = service.Revoke(created.ID) })
+
+	if err := api.RegisterRoutes(app, dbConn, cassette.Config{}); err != nil {
+		t.Fatalf("no se pudieron registrar las rutas: %v", err)
+	}
+	return app, key
 }
 
 func TestGetStocksE2E_WithDynamicFilters(t *testing.T) {
-	app := setupE2EApp()
+	app, key := setupE2EApp(t)
 
 	// Paso 1: Consulta general sin filtros
 	req1 := httptest.NewRequest("GET", "/api/stocks?limit=5", nil)
 	req1.Header.Set("Content-Type", "application/json")
+	req1.Header.Set("X-API-Key", key)
 
 	resp1, err := app.Test(req1, -1)
 	assert.NoError(t, err)
@@ -63,6 +78,7 @@ func TestGetStocksE2E_WithDynamicFilters(t *testing.T) {
 
 	// Paso 4: Ejecutar consulta filtrada
 	req2 := httptest.NewRequest("GET", filteredURL, nil)
+	req2.Header.Set("X-API-Key", key)
 	resp2, err := app.Test(req2, -1)
 	assert.NoError(t, err)
 	assert.Equal(t, http.StatusOK, resp2.StatusCode)
@@ -93,9 +109,10 @@ func TestGetStocksE2E_WithDynamicFilters(t *testing.T) {
 }
 
 func TestGetRecommendationsE2E(t *testing.T) {
-	app := setupE2EApp()
+	app, key := setupE2EApp(t)
 
 	req := httptest.NewRequest("GET", "/api/recommendations", nil)
+	req.Header.Set("X-API-Key", key)
 	resp, err := app.Test(req, -1)
 	assert.NoError(t, err)
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
@@ -123,3 +140,79 @@ func TestGetRecommendationsE2E(t *testing.T) {
 	assert.Equal(t, 10, counts["hold"], "deben haber 10 recomendaciones 'hold'")
 	assert.Equal(t, 10, counts["sell"], "deben haber 10 recomendaciones 'sell'")
 }
+
+func TestGetStocksE2E_CursorPagination(t *testing.T) {
+	app, key := setupE2EApp(t)
+
+	request := func(query string) (int, map[string]interface{}) {
+		req := httptest.NewRequest("GET", "/api/stocks?"+query, nil)
+		req.Header.Set("X-API-Key", key)
+		resp, err := app.Test(req, -1)
+		assert.NoError(t, err)
+
+		var body map[string]interface{}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		return resp.StatusCode, body
+	}
+	get := func(query string) map[string]interface{} {
+		status, body := request(query)
+		assert.Equal(t, http.StatusOK, status, query)
+		return body
+	}
+	ids := func(body map[string]interface{}) []string {
+		var out []string
+		for _, item := range body["items"].([]interface{}) {
+			out = append(out, item.(map[string]interface{})["id"].(string))
+		}
+		return out
+	}
+
+	// created_at es TIMESTAMPTZ y target_from/target_to son DECIMAL: el valor
+	// del cursor viaja como texto y tiene que volver a compararse igual.
+	for _, order := range []string{
+		"orderBy=ticker&orderDir=desc",
+		"orderBy=created_at",
+		"orderBy=created_at&orderDir=desc",
+		"orderBy=target_from",
+		"orderBy=target_to&orderDir=desc",
+		"orderBy=target_to&target_to_min=1",
+	} {
+		t.Run(order, func(t *testing.T) {
+			// Paso 1: Dos páginas en modo página como referencia
+			page1 := get("limit=3&" + order)
+			page2 := get("limit=3&page=2&" + order)
+			assert.Len(t, ids(page1), 3, "Se requieren al menos 6 registros para validar el cursor")
+			assert.Nil(t, page1["prev_cursor"])
+			assert.NotNil(t, page1["total"])
+
+			// Paso 2: El cursor siguiente devuelve la misma segunda página, sin total
+			next, ok := page1["next_cursor"].(string)
+			assert.True(t, ok)
+			byCursor := get("limit=3&include_total=false&cursor=" + url.QueryEscape(next) + "&" + order)
+			assert.Equal(t, ids(page2), ids(byCursor))
+			assert.NotContains(t, byCursor, "total")
+			assert.NotContains(t, byCursor, "page")
+
+			// Paso 3: El cursor anterior vuelve a la primera página
+			prev, ok := byCursor["prev_cursor"].(string)
+			assert.True(t, ok)
+			back := get("limit=3&include_total=false&cursor=" + url.QueryEscape(prev) + "&" + order)
+			assert.Equal(t, ids(page1), ids(back))
+			assert.Nil(t, back["prev_cursor"])
+		})
+	}
+
+	// Paso 4: Un cursor usado con otros filtros se rechaza
+	page1 := get("limit=3&orderBy=target_to&target_to_min=1")
+	next, ok := page1["next_cursor"].(string)
+	assert.True(t, ok)
+	for _, query := range []string{
+		"orderBy=target_to",
+		"orderBy=target_to&target_to_min=2",
+		"orderBy=target_to&target_to_min=1&ticker=A",
+	} {
+		status, body := request("limit=3&cursor=" + url.QueryEscape(next) + "&" + query)
+		assert.Equal(t, http.StatusBadRequest, status, query)
+		assert.Equal(t, "cursor", body["errors"].([]interface{})[0].(map[string]interface{})["field"], query)
+	}
+}
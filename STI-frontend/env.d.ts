@@ -1,2 +1,4 @@
 /// <reference types="vite/client" />
 
+// La URL del backend y la API key las usa el proxy de vite.config.ts
+// (STI_API_URL y STI_API_KEY); el frontend pide siempre a /api en su origen.
@@ -6,13 +6,11 @@ export const useRecommendationsStore = defineStore('recommendations', () => {
   const loading = ref(false)
   const error = ref<string | null>(null)
 
-  const apiUrl = import.meta.env.VITE_API_URL // Asegúrate que esté en tu .env
-
   async function fetchRecommendations() {
     loading.value = true
     error.value = null
     try {
-      const res = await fetch(`${apiUrl}/api/recommendations`)
+      const res = await fetch('/api/recommendations')
       if (!res.ok) throw new Error(`Error HTTP: ${res.status}`)
       recommendations.value = await res.json()
     } catch (err) {
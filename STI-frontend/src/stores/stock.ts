@@ -2,7 +2,6 @@
 import { defineStore } from 'pinia'
 import { ref, computed, watch, onMounted } from 'vue'
 
-const apiUrl = import.meta.env.VITE_API_URL
 
 // Si los tipos son globales según lo que definimos antes:
 // type Stock
@@ -82,7 +81,7 @@ export const useStockStore = defineStore('stock', () => {
     loading.value = true
     error.value = null
     try {
-      const url = `${apiUrl}/api/stocks?${buildQuery(filters.value)}`
+      const url = `/api/stocks?${buildQuery(filters.value)}`
       lastURL.value = url
       const res = await fetch(url, { headers: { Accept: 'application/json' } })
       if (!res.ok) throw new Error(`HTTP ${res.status}`)
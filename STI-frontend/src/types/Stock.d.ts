@@ -34,9 +34,11 @@ declare global {
   type StockResponse = {
     items: Stock[]
     limit: number
-    page: number
-    total: number
-    total_pages: number
+    page?: number
+    total?: number
+    total_pages?: number
+    next_cursor: string | null
+    prev_cursor: string | null
   }
 
   type StockRecommendation = {
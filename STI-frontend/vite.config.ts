@@ -1,20 +1,42 @@
 import { fileURLToPath, URL } from 'node:url'
 
-import { defineConfig } from 'vite'
+import { defineConfig, loadEnv } from 'vite'
 import vue from '@vitejs/plugin-vue'
 import vueDevTools from 'vite-plugin-vue-devtools'
 import tailwindcss from '@tailwindcss/vite'
 
 // https://vite.dev/config/
-export default defineConfig({
-  plugins: [
-    vue(),
-    vueDevTools(),
-    tailwindcss(),
-  ],
-  resolve: {
-    alias: {
-      '@': fileURLToPath(new URL('./src', import.meta.url))
+export default defineConfig(({ mode }) => {
+  // Sin prefijo VITE_: solo las lee el proxy y nunca llegan al bundle.
+  const env = loadEnv(mode, process.cwd(), 'STI_')
+  const apiUrl = env.STI_API_URL || 'http://localhost:8080'
+  const apiKey = env.STI_API_KEY
+
+  if (!apiKey) {
+    console.warn('STI_API_KEY no está definida: el backend responderá 401 a /api')
+  }
+
+  // El proxy agrega la API key a cada pedido a /api, así el navegador no la ve.
+  const proxy = {
+    '/api': {
+      target: apiUrl,
+      changeOrigin: true,
+      headers: apiKey ? { 'X-API-Key': apiKey } : {},
+    },
+  }
+
+  return {
+    plugins: [
+      vue(),
+      vueDevTools(),
+      tailwindcss(),
+    ],
+    resolve: {
+      alias: {
+        '@': fileURLToPath(new URL('./src', import.meta.url))
+      },
     },
-  },
+    server: { proxy },
+    preview: { proxy },
+  }
 })